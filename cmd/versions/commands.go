@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// options are the values of the command-line options shared by all commands.
+type options struct {
+	dialect dialect
+	json    bool
+	preid   string
+}
+
+// command is a single subcommand of the versions tool.
+type command struct {
+	args string
+	help string
+	run  func(opts *options, args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"parse": {
+		args: "VERSION...",
+		help: "check and print each version in canonical form",
+		run:  runParse,
+	},
+	"sort": {
+		help: "sort the versions given on stdin",
+		run:  runSort,
+	},
+	"filter": {
+		args: "CONSTRAINT",
+		help: "print the versions on stdin that meet a constraint",
+		run:  runFilter,
+	},
+	"newest": {
+		args: "[CONSTRAINT]",
+		help: "print the newest version on stdin that meets a constraint",
+		run:  runNewest,
+	},
+	"bump": {
+		args: "KIND VERSION",
+		help: "print the result of bumping a version",
+		run:  runBump,
+	},
+	"explain": {
+		args: "CONSTRAINT [VERSION]",
+		help: "print the parsed constraint as a tree, and why a version meets it or not",
+		run:  runExplain,
+	},
+}
+
+// usageError is an error caused by an invalid command line, rather than by
+// invalid input.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// run is the main entry point of the tool, returning the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "versions: unknown command %q\n\n", name)
+		printUsage(stderr)
+		return 2
+	}
+
+	var opts options
+	var dialectName string
+	var ruby bool
+	flags := flag.NewFlagSet("versions "+name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&dialectName, "dialect", "canon", "the constraint syntax to use: canon, ruby, npm or cargo")
+	flags.BoolVar(&ruby, "ruby", false, "shorthand for -dialect=ruby")
+	flags.BoolVar(&opts.json, "json", false, "produce JSON output instead of plain text")
+	if name == "bump" {
+		flags.StringVar(&opts.preid, "preid", "", "the identifier to start a new prerelease with")
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: versions %s [options] %s\n\n%s.\n\nOptions:\n", name, cmd.args, strings.ToUpper(cmd.help[:1])+cmd.help[1:])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	// The flag package stops at the first argument that isn't an option, so
+	// any options after the arguments would otherwise be silently treated as
+	// arguments. Arguments that begin with "-" can still be given after "--".
+	rest := flags.Args()
+	if len(args) == len(rest)+1 || args[len(args)-len(rest)-1] != "--" {
+		for _, arg := range rest {
+			if len(arg) > 1 && arg[0] == '-' {
+				fmt.Fprintf(stderr, "versions %s: option %s must come before the arguments\n", name, arg)
+				flags.Usage()
+				return 2
+			}
+		}
+	}
+
+	if ruby {
+		if dialectName != "canon" && dialectName != "ruby" {
+			fmt.Fprintf(stderr, "versions %s: -ruby conflicts with -dialect=%s\n", name, dialectName)
+			return 2
+		}
+		dialectName = "ruby"
+	}
+	d, ok := dialects[dialectName]
+	if !ok {
+		fmt.Fprintf(stderr, "versions %s: unknown dialect %q; must be canon, ruby, npm or cargo\n", name, dialectName)
+		return 2
+	}
+	opts.dialect = d
+
+	if err := cmd.run(&opts, rest, stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "versions %s: %s\n", name, err)
+		if _, ok := err.(usageError); ok {
+			flags.Usage()
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, "Usage: versions <command> [options] [arguments]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %-22s %s\n", strings.TrimSpace(name+" "+cmd.args), cmd.help)
+	}
+	fmt.Fprint(w, "\nRun \"versions <command> -h\" for the options of each command.\n")
+}
+
+// dialect is a constraint syntax, along with the function that decides which
+// versions meet constraints written in that syntax.
+type dialect struct {
+	parse func(s string) (constraints.Spec, constraints.Sources, error)
+	set   func(spec constraints.Spec, sources constraints.Sources) versions.Set
+}
+
+var dialects = map[string]dialect{
+	"canon": {
+		parse: func(s string) (constraints.Spec, constraints.Sources, error) {
+			return constraints.ParseWithSources(s)
+		},
+		set: versions.MeetingConstraintsWithSources,
+	},
+	"ruby": {
+		parse: func(s string) (constraints.Spec, constraints.Sources, error) {
+			return constraints.ParseRubyStyleMultiWithSources(s)
+		},
+		set: versions.MeetingConstraintsWithSources,
+	},
+	"npm": {
+		parse: func(s string) (constraints.Spec, constraints.Sources, error) {
+			return constraints.ParseNPMWithSources(s)
+		},
+		set: versions.MeetingConstraintsNPMWithSources,
+	},
+	"cargo": {
+		parse: func(s string) (constraints.Spec, constraints.Sources, error) {
+			return constraints.ParseCargoWithSources(s)
+		},
+		set: func(spec constraints.Spec, sources constraints.Sources) versions.Set {
+			return versions.MeetingConstraintsCargoWithSources(spec.(constraints.IntersectionSpec), sources)
+		},
+	},
+}
+
+func (d dialect) meeting(s string) (versions.Set, error) {
+	spec, sources, err := d.parse(s)
+	if err != nil {
+		return versions.None, err
+	}
+	return d.set(spec, sources), nil
+}
+
+// readVersions reads a list of versions from the given reader, one per line.
+func readVersions(r io.Reader) (versions.List, error) {
+	ret := make(versions.List, 0)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+		v, err := versions.ParseVersion(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		ret = append(ret, v)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func writeList(opts *options, w io.Writer, l versions.List) error {
+	if opts.json {
+		if l == nil {
+			l = versions.List{}
+		}
+		return writeJSON(w, l)
+	}
+	for _, v := range l {
+		fmt.Fprintln(w, v)
+	}
+	return nil
+}
+
+func writeVersion(opts *options, w io.Writer, v versions.Version) error {
+	if opts.json {
+		return writeJSON(w, v)
+	}
+	_, err := fmt.Fprintln(w, v)
+	return err
+}
+
+// versionJSON is the JSON representation of a version produced by the
+// parse command.
+type versionJSON struct {
+	Version    string `json:"version"`
+	Major      uint64 `json:"major"`
+	Minor      uint64 `json:"minor"`
+	Patch      uint64 `json:"patch"`
+	Prerelease string `json:"prerelease"`
+	Metadata   string `json:"metadata"`
+}
+
+func runParse(opts *options, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return usageError("at least one version is required")
+	}
+	l := make(versions.List, len(args))
+	for i, arg := range args {
+		v, err := versions.ParseVersion(arg)
+		if err != nil {
+			return err
+		}
+		l[i] = v
+	}
+
+	if opts.json {
+		vs := make([]versionJSON, len(l))
+		for i, sv := range l {
+			v := sv.(versions.Version)
+			vs[i] = versionJSON{
+				Version:    v.String(),
+				Major:      v.Major,
+				Minor:      v.Minor,
+				Patch:      v.Patch,
+				Prerelease: v.Prerelease.Raw(),
+				Metadata:   v.Metadata.Raw(),
+			}
+		}
+		return writeJSON(stdout, vs)
+	}
+	return writeList(opts, stdout, l)
+}
+
+func runSort(opts *options, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 0 {
+		return usageError("sort takes no arguments; versions are read from stdin")
+	}
+	l, err := readVersions(stdin)
+	if err != nil {
+		return err
+	}
+	l.Sort()
+	return writeList(opts, stdout, l)
+}
+
+func runFilter(opts *options, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 1 {
+		return usageError("filter requires exactly one constraint")
+	}
+	set, err := opts.dialect.meeting(args[0])
+	if err != nil {
+		return err
+	}
+	l, err := readVersions(stdin)
+	if err != nil {
+		return err
+	}
+	return writeList(opts, stdout, l.Filter(set))
+}
+
+func runNewest(opts *options, args []string, stdin io.Reader, stdout io.Writer) error {
+	set := versions.All
+	switch len(args) {
+	case 0:
+	case 1:
+		var err error
+		set, err = opts.dialect.meeting(args[0])
+		if err != nil {
+			return err
+		}
+	default:
+		return usageError("newest accepts at most one constraint")
+	}
+	l, err := readVersions(stdin)
+	if err != nil {
+		return err
+	}
+	v := l.NewestInSet(set)
+	if v == versions.Unspecified {
+		return fmt.Errorf("no suitable version")
+	}
+	return writeVersion(opts, stdout, v.(versions.Version))
+}
+
+func runBump(opts *options, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 2 {
+		return usageError("bump requires a kind and a version")
+	}
+	v, err := versions.ParseVersion(args[1])
+	if err != nil {
+		return err
+	}
+	v, err = v.Bump(versions.BumpKind(args[0]), opts.preid)
+	if err != nil {
+		return err
+	}
+	return writeVersion(opts, stdout, v)
+}
+
+func runExplain(opts *options, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 1 && len(args) != 2 {
+		return usageError("explain requires a constraint and optionally a version")
+	}
+	spec, sources, err := opts.dialect.parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		if opts.json {
+			return writeJSON(stdout, specJSON(spec))
+		}
+		writeSpecTree(stdout, spec, "")
+		return nil
+	}
+
+	v, err := versions.ParseVersion(args[1])
+	if err != nil {
+		return err
+	}
+	explanation := opts.dialect.set(spec, sources).Explain(v)
+	if opts.json {
+		return writeJSON(stdout, struct {
+			Spec        constraints.UnionSpec `json:"spec"`
+			Version     string                `json:"version"`
+			Explanation explanationNode       `json:"explanation"`
+		}{specJSON(spec), v.String(), explanationJSON(explanation)})
+	}
+	writeSpecTree(stdout, spec, "")
+	if explanation.Member {
+		fmt.Fprintf(stdout, "\n%s is allowed:\n", v)
+	} else {
+		fmt.Fprintf(stdout, "\n%s is not allowed:\n", v)
+	}
+	fmt.Fprint(stdout, explanation)
+	return nil
+}
+
+// explanationNode is the JSON representation of a versions.Explanation
+// produced by the explain command.
+type explanationNode struct {
+	Member    bool              `json:"member"`
+	Reason    string            `json:"reason"`
+	Selection string            `json:"selection,omitempty"`
+	Children  []explanationNode `json:"children,omitempty"`
+}
+
+func explanationJSON(e versions.Explanation) explanationNode {
+	ret := explanationNode{Member: e.Member, Reason: e.Reason}
+	if e.Selection != nil {
+		ret.Selection = e.Selection.String()
+	}
+	for _, child := range e.Children {
+		ret.Children = append(ret.Children, explanationJSON(child))
+	}
+	return ret
+}
+
+// specJSON returns the given spec as a UnionSpec, so that the JSON
+// representation of the spec has the same structure regardless of which
+// dialect it was parsed from.
+func specJSON(spec constraints.Spec) constraints.UnionSpec {
+	switch ts := spec.(type) {
+	case constraints.UnionSpec:
+		return ts
+	case constraints.IntersectionSpec:
+		return constraints.UnionSpec{ts}
+	case constraints.SelectionSpec:
+		return constraints.UnionSpec{{ts}}
+	case constraints.VersionSpec:
+		return constraints.UnionSpec{{{Operator: constraints.OpMatch, Boundary: ts}}}
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+func writeSpecTree(w io.Writer, spec constraints.Spec, indent string) {
+	switch ts := spec.(type) {
+	case constraints.UnionSpec:
+		fmt.Fprintf(w, "%sUnionSpec\n", indent)
+		for _, s := range ts {
+			writeSpecTree(w, s, indent+"  ")
+		}
+	case constraints.IntersectionSpec:
+		fmt.Fprintf(w, "%sIntersectionSpec\n", indent)
+		for _, s := range ts {
+			writeSpecTree(w, s, indent+"  ")
+		}
+	case constraints.SelectionSpec:
+		fmt.Fprintf(w, "%sSelectionSpec %s %s\n", indent, ts.Operator, ts.Boundary)
+	case constraints.VersionSpec:
+		fmt.Fprintf(w, "%sVersionSpec %s\n", indent, ts)
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
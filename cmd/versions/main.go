@@ -0,0 +1,51 @@
+// Command versions evaluates version numbers and version constraints from
+// the command line, for use in shell scripts and CI pipelines.
+//
+// Usage:
+//
+//	versions <command> [options] [arguments]
+//
+// The commands are:
+//
+//	parse VERSION...          check and print each version in canonical form
+//	sort                      sort the versions given on stdin
+//	filter CONSTRAINT         print the versions on stdin that meet a constraint
+//	newest [CONSTRAINT]       print the newest version on stdin, optionally
+//	                          considering only those that meet a constraint
+//	bump KIND VERSION         print the result of bumping a version, where KIND
+//	                          is major, minor, patch, premajor, preminor,
+//	                          prepatch or prerelease
+//	explain CONSTRAINT [VERSION]
+//	                          print the parsed constraint as a tree and, if
+//	                          a version is given, explain why that version
+//	                          does or does not meet the constraint
+//
+// Commands that read versions from stdin expect one version per line, and
+// ignore blank lines.
+//
+// All commands accept the following options:
+//
+//	-dialect NAME   the constraint syntax to use: canon (the default), ruby,
+//	                npm or cargo
+//	-ruby           shorthand for -dialect=ruby
+//	-json           produce JSON output instead of plain text
+//
+// The bump command also accepts -preid IDENTIFIER, giving the identifier to
+// start the prerelease portion with for the kinds that produce prereleases.
+//
+// Options must come before the arguments, as in "versions parse -json 1.0.0".
+// An argument that begins with "-" must follow "--" so that it is not taken
+// to be a misplaced option.
+//
+// The exit status is 0 on success, 1 if a version or constraint is invalid
+// or if newest finds no suitable version, and 2 if the command line itself
+// is invalid.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	const input = "1.2.0\n2.0.0-rc.1\n1.10.0\n\n1.3.5\n"
+
+	tests := []struct {
+		Args       []string
+		Stdin      string
+		WantStdout string
+		WantStderr string
+		WantStatus int
+	}{
+		{
+			[]string{"parse", "1.2.3-beta.1+abc", "2"},
+			"",
+			"1.2.3-beta.1+abc\n2.0.0\n",
+			"",
+			0,
+		},
+		{
+			[]string{"parse", "-json", "1.2.3-beta.1+abc"},
+			"",
+			`[
+  {
+    "version": "1.2.3-beta.1+abc",
+    "major": 1,
+    "minor": 2,
+    "patch": 3,
+    "prerelease": "beta.1",
+    "metadata": "abc"
+  }
+]
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"parse", "1.2.3", "not-a-version"},
+			"",
+			"",
+			"versions parse: ",
+			1,
+		},
+		{
+			[]string{"sort"},
+			input,
+			"1.2.0\n1.3.5\n1.10.0\n2.0.0-rc.1\n",
+			"",
+			0,
+		},
+		{
+			[]string{"sort", "-json"},
+			"",
+			"[]\n",
+			"",
+			0,
+		},
+		{
+			[]string{"sort"},
+			"1.0.0\nbogus\n",
+			"",
+			"versions sort: line 2: ",
+			1,
+		},
+		{
+			[]string{"filter", "^1.2"},
+			input,
+			"1.2.0\n1.10.0\n1.3.5\n",
+			"",
+			0,
+		},
+		{
+			[]string{"filter", "-ruby", "~> 1.2.0"},
+			input,
+			"1.2.0\n",
+			"",
+			0,
+		},
+		{
+			[]string{"filter", "-dialect=npm", ">=2.0.0-rc.0"},
+			input,
+			"2.0.0-rc.1\n",
+			"",
+			0,
+		},
+		{
+			[]string{"filter", "-json", ">5"},
+			input,
+			"[]\n",
+			"",
+			0,
+		},
+		{
+			[]string{"filter"},
+			input,
+			"",
+			"versions filter: filter requires exactly one constraint\nUsage: versions filter",
+			2,
+		},
+		{
+			[]string{"newest"},
+			input,
+			"2.0.0-rc.1\n",
+			"",
+			0,
+		},
+		{
+			[]string{"newest", "-json", "<1.10"},
+			input,
+			"\"1.3.5\"\n",
+			"",
+			0,
+		},
+		{
+			[]string{"newest", ">5"},
+			input,
+			"",
+			"versions newest: no suitable version\n",
+			1,
+		},
+		{
+			[]string{"bump", "minor", "1.2.3"},
+			"",
+			"1.3.0\n",
+			"",
+			0,
+		},
+		{
+			[]string{"bump", "-preid", "rc", "prerelease", "1.2.3-rc.1"},
+			"",
+			"1.2.3-rc.2\n",
+			"",
+			0,
+		},
+		{
+			[]string{"bump", "huge", "1.2.3"},
+			"",
+			"",
+			"versions bump: invalid version bump \"huge\"",
+			1,
+		},
+		{
+			[]string{"explain", ">=1.2 <2 || 3.x"},
+			"",
+			`UnionSpec
+  IntersectionSpec
+    SelectionSpec OpGreaterThanOrEqual 1.2.0
+    SelectionSpec OpLessThan 2.0.0
+  IntersectionSpec
+    SelectionSpec OpMatch 3.*.*
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"explain", "-ruby", "~> 1.2, != 1.2.5"},
+			"",
+			`IntersectionSpec
+  SelectionSpec OpGreaterThanOrEqualMinorOnly 1.2.*
+  SelectionSpec OpNotEqual 1.2.5
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"explain", "-json", "-dialect=cargo", "1.2"},
+			"",
+			`[
+  [
+    {
+      "operator": "greater_than_or_equal_minor_only",
+      "boundary": {
+        "major": {
+          "num": 1
+        },
+        "minor": {
+          "num": 2
+        },
+        "patch": {
+          "num": 0
+        }
+      }
+    }
+  ]
+]
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"explain", ">=1.0.0 <2.0.0", "2.0.0-beta1"},
+			"",
+			`UnionSpec
+  IntersectionSpec
+    SelectionSpec OpGreaterThanOrEqual 1.0.0
+    SelectionSpec OpLessThan 2.0.0
+
+2.0.0-beta1 is not allowed:
+- fails 1 of 3 requirements
+  - is a prerelease not explicitly requested by the constraints
+  + meets >=1.0.0
+  + meets <2.0.0
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"explain", "-json", "<2.0.0", "2.1.0"},
+			"",
+			`{
+  "spec": [
+    [
+      {
+        "operator": "less_than",
+        "boundary": {
+          "major": {
+            "num": 2
+          },
+          "minor": {
+            "num": 0
+          },
+          "patch": {
+            "num": 0
+          }
+        }
+      }
+    ]
+  ],
+  "version": "2.1.0",
+  "explanation": {
+    "member": false,
+    "reason": "fails 1 of 2 requirements",
+    "children": [
+      {
+        "member": true,
+        "reason": "is not a prerelease"
+      },
+      {
+        "member": false,
+        "reason": "fails <2.0.0",
+        "selection": "<2.0.0"
+      }
+    ]
+  }
+}
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"explain", "-ruby", "-dialect=npm", "1.2"},
+			"",
+			"",
+			"versions explain: -ruby conflicts with -dialect=npm\n",
+			2,
+		},
+		{
+			[]string{"explain", "-dialect=python", "1.2"},
+			"",
+			"",
+			"versions explain: unknown dialect \"python\"",
+			2,
+		},
+		{
+			[]string{"parse", "1.0.0", "-json"},
+			"",
+			"",
+			"versions parse: option -json must come before the arguments\nUsage: versions parse",
+			2,
+		},
+		{
+			[]string{"filter", "^1.2", "-dialect=npm"},
+			input,
+			"",
+			"versions filter: option -dialect=npm must come before the arguments\n",
+			2,
+		},
+		{
+			[]string{"parse", "-json", "--", "1.0.0"},
+			"",
+			`[
+  {
+    "version": "1.0.0",
+    "major": 1,
+    "minor": 0,
+    "patch": 0,
+    "prerelease": "",
+    "metadata": ""
+  }
+]
+`,
+			"",
+			0,
+		},
+		{
+			[]string{"parse", "--", "-json"},
+			"",
+			"",
+			"versions parse: ",
+			1,
+		},
+		{
+			[]string{"frobnicate"},
+			"",
+			"",
+			"versions: unknown command \"frobnicate\"",
+			2,
+		},
+		{
+			nil,
+			"",
+			"",
+			"Usage: versions <command>",
+			2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.Args, " "), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(test.Args, strings.NewReader(test.Stdin), &stdout, &stderr)
+
+			if got, want := status, test.WantStatus; got != want {
+				t.Errorf("wrong exit status\ngot:  %d\nwant: %d\nstderr: %s", got, want, stderr.String())
+			}
+			if got, want := stdout.String(), test.WantStdout; got != want {
+				t.Errorf("wrong stdout\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := stderr.String(), test.WantStderr; !strings.HasPrefix(got, want) || (want == "" && got != "") {
+				t.Errorf("wrong stderr\ngot:  %s\nwant prefix: %s", got, want)
+			}
+		})
+	}
+}
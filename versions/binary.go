@@ -0,0 +1,506 @@
+package versions
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The binary encodings of versions, lists and sets all begin with a format
+// version byte, followed by a byte indicating which kind of value follows so
+// that a value of one kind can't be mistaken for another.
+//
+// Within a format version the encoding never changes, so encoded values can
+// be cached indefinitely. A future change to the encoding will use a new
+// format version, and UnmarshalBinary will continue to accept the old ones.
+const binaryFormatV1 byte = 1
+
+const (
+	binaryKindVersion byte = 'v'
+	binaryKindList    byte = 'l'
+	binaryKindSet     byte = 's'
+)
+
+// These follow the kind byte of a set to indicate how its members are given.
+// binarySetChannels is a normalized set that distinguishes prerelease
+// channels, which has additional fields for the channel ranges.
+// binarySetOthers is a normalized set that contains versions of schemes other
+// than Semver, followed by a byte of binaryOthers flags and then the encoding
+// of its semantic versions as either binarySetNormal or binarySetChannels.
+const (
+	binarySetNone     byte = 0
+	binarySetAll      byte = 1
+	binarySetNormal   byte = 2
+	binarySetChannels byte = 3
+	binarySetOthers   byte = 4
+)
+
+// These are the flags of a set encoded as binarySetOthers, of which at least
+// one must be set.
+const (
+	binaryOthersReleased   byte = 1 << 0
+	binaryOthersPrerelease byte = 1 << 1
+)
+
+// MarshalBinary is an implementation of encoding.BinaryMarshaler, producing
+// a compact encoding of the receiver that UnmarshalBinary can decode much
+// more quickly than ParseVersion can parse the result of String.
+//
+// The encoding preserves all of the fields of the version, including its
+// metadata, and is stable: the same version always produces the same bytes.
+// The format is otherwise opaque and should not be relied upon.
+func (v Version) MarshalBinary() ([]byte, error) {
+	buf := []byte{binaryFormatV1, binaryKindVersion}
+	return appendBinaryVersion(buf, v), nil
+}
+
+// UnmarshalBinary is an implementation of encoding.BinaryUnmarshaler,
+// decoding a version previously encoded by Version.MarshalBinary.
+func (v *Version) UnmarshalBinary(data []byte) error {
+	d, err := newBinaryDecoder(data, binaryKindVersion)
+	if err != nil {
+		return err
+	}
+	new := d.version()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*v = new
+	return nil
+}
+
+// MarshalBinary is an implementation of encoding.BinaryMarshaler, producing
+// a compact encoding of all of the versions in the receiver, in order.
+//
+// This is more compact than encoding each of the versions separately, and
+// so is the best way to cache a large number of versions. Only versions of
+// the scheme Semver can be encoded, so an error is returned if the list
+// contains versions of any other scheme.
+func (l List) MarshalBinary() ([]byte, error) {
+	if err := checkBinaryList(l); err != nil {
+		return nil, err
+	}
+	buf := []byte{binaryFormatV1, binaryKindList}
+	return appendBinaryList(buf, l), nil
+}
+
+// UnmarshalBinary is an implementation of encoding.BinaryUnmarshaler,
+// decoding a list previously encoded by List.MarshalBinary.
+func (l *List) UnmarshalBinary(data []byte) error {
+	d, err := newBinaryDecoder(data, binaryKindList)
+	if err != nil {
+		return err
+	}
+	new := d.list()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*l = new
+	return nil
+}
+
+// MarshalBinary is an implementation of encoding.BinaryMarshaler, producing
+// a compact encoding of the members and requested versions of the receiver.
+//
+// Unlike MarshalText, this can represent any set that Normalize can, and the
+// set returned by UnmarshalBinary is equal to the result of calling Normalize
+// on the receiver, with the same members and the same requested versions.
+// As with Normalize, sets that select versions using a function cannot be
+// encoded and cause an error. The sources returned by Sources are not
+// preserved.
+//
+// A set can contain all of the released or pre-release versions of schemes
+// other than Semver, as Released and Prerelease do, but an error is returned
+// for a set that selects particular versions of another scheme.
+func (s Set) MarshalBinary() ([]byte, error) {
+	if problem := normalizeProblem(s.setI); problem != "" {
+		return nil, fmt.Errorf("set %s, which can't be represented in binary", problem)
+	}
+
+	buf := []byte{binaryFormatV1, binaryKindSet}
+	n := normalize(s.setI)
+	switch {
+	case s == All:
+		return append(buf, binarySetAll), nil
+	case n.isEmpty():
+		return append(buf, binarySetNone), nil
+	}
+	if len(n.schemes) != 0 {
+		return nil, fmt.Errorf("set selects particular versions of %#v, which can't be represented in binary", n.schemes[0].scheme)
+	}
+	for _, l := range [...]List{n.include, n.exclude, n.requested} {
+		if err := checkBinaryList(l); err != nil {
+			return nil, err
+		}
+	}
+	if n.otherReleased || n.otherPrerelease {
+		var flags byte
+		if n.otherReleased {
+			flags |= binaryOthersReleased
+		}
+		if n.otherPrerelease {
+			flags |= binaryOthersPrerelease
+		}
+		buf = append(buf, binarySetOthers, flags)
+	}
+	if len(n.channels) == 0 {
+		buf = append(buf, binarySetNormal)
+		buf = appendBinaryRanges(buf, n.released)
+		buf = appendBinaryRanges(buf, n.prerelease)
+	} else {
+		// The channel names come first, because the decoder needs all of
+		// them to check that each range list is canonical.
+		buf = append(buf, binarySetChannels)
+		buf = appendBinaryRanges(buf, n.released)
+		buf = appendUvarint(buf, uint64(len(n.channels)))
+		for _, c := range n.channels {
+			buf = appendBinaryString(buf, c.channel)
+		}
+		buf = appendBinaryRanges(buf, n.prerelease)
+		for _, c := range n.channels {
+			buf = appendBinaryRanges(buf, c.ranges)
+		}
+	}
+	buf = appendBinaryList(buf, n.include)
+	buf = appendBinaryList(buf, n.exclude)
+	buf = appendBinaryList(buf, n.requested)
+	return buf, nil
+}
+
+// UnmarshalBinary is an implementation of encoding.BinaryUnmarshaler,
+// decoding a set previously encoded by Set.MarshalBinary.
+//
+// The result is always normalized, as described for Set.Normalize.
+func (s *Set) UnmarshalBinary(data []byte) error {
+	d, err := newBinaryDecoder(data, binaryKindSet)
+	if err != nil {
+		return err
+	}
+
+	var new Set
+	switch kind := d.byte(); kind {
+	case binarySetNone:
+		new = None
+	case binarySetAll:
+		new = All
+	case binarySetNormal, binarySetChannels:
+		new = d.setNormal(kind, setNormal{})
+	case binarySetOthers:
+		var n setNormal
+		flags := d.byte()
+		switch {
+		case flags == 0 || flags&^(binaryOthersReleased|binaryOthersPrerelease) != 0:
+			d.fail("invalid flags for other schemes")
+		default:
+			n.otherReleased = flags&binaryOthersReleased != 0
+			n.otherPrerelease = flags&binaryOthersPrerelease != 0
+		}
+		switch kind := d.byte(); kind {
+		case binarySetNormal, binarySetChannels:
+			new = d.setNormal(kind, n)
+		default:
+			d.fail("unsupported set encoding")
+		}
+	default:
+		d.fail("unsupported set encoding")
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*s = new
+	return nil
+}
+
+// setNormal reads the fields of a set encoded as the given kind, which must
+// be either binarySetNormal or binarySetChannels, into the given set, which
+// has only the flags for other schemes populated.
+func (d *binaryDecoder) setNormal(kind byte, n setNormal) Set {
+	if kind == binarySetNormal {
+		n.released = d.ranges(releasedBound)
+		n.prerelease = d.ranges(prereleaseBound)
+	} else {
+		n.released = d.ranges(releasedBound)
+		channels := d.channelNames()
+		n.prerelease = d.ranges(channelBound("", channels))
+		for _, c := range channels {
+			n.channels = append(n.channels, channelRanges{
+				channel: c,
+				ranges:  d.ranges(channelBound(c, channels)),
+			})
+		}
+	}
+	n.include = d.exactList()
+	n.exclude = d.exactList()
+	n.requested = d.exactList()
+	if d.err == nil {
+		d.err = checkBinarySetNormal(n)
+	}
+	return n.set()
+}
+
+func appendBinaryVersion(buf []byte, v Version) []byte {
+	buf = appendUvarint(buf, v.Major)
+	buf = appendUvarint(buf, v.Minor)
+	buf = appendUvarint(buf, v.Patch)
+	buf = appendBinaryString(buf, string(v.Prerelease))
+	buf = appendBinaryString(buf, string(v.Metadata))
+	return buf
+}
+
+// appendBinaryList encodes the given list, which must have been checked
+// using checkBinaryList.
+func appendBinaryList(buf []byte, l List) []byte {
+	buf = appendUvarint(buf, uint64(len(l)))
+	for _, v := range l {
+		buf = appendBinaryVersion(buf, v.(Version))
+	}
+	return buf
+}
+
+// checkBinaryList returns an error if the given list contains a version that
+// the binary encoding can't represent because it is not a semantic version.
+func checkBinaryList(l List) error {
+	for _, v := range l {
+		if _, ok := v.(Version); !ok {
+			return fmt.Errorf("%#v is not a semantic version, so it can't be represented in binary", v)
+		}
+	}
+	return nil
+}
+
+func appendBinaryRanges(buf []byte, l rangeList) []byte {
+	buf = appendUvarint(buf, uint64(len(l)))
+	for _, r := range l {
+		buf = appendBinaryVersion(buf, r.lower)
+		if r.unbounded {
+			buf = append(buf, 0)
+			continue
+		}
+		buf = append(buf, 1)
+		buf = appendBinaryVersion(buf, r.upper)
+	}
+	return buf
+}
+
+func appendBinaryString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendUvarint(buf []byte, n uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	l := binary.PutUvarint(tmp[:], n)
+	return append(buf, tmp[:l]...)
+}
+
+// binaryDecoder reads the values written by the appendBinary... functions.
+//
+// Once an error has occurred all further reads return zero values, so that
+// callers need only check for an error once they've read everything.
+type binaryDecoder struct {
+	data []byte
+	err  error
+}
+
+func newBinaryDecoder(data []byte, kind byte) (*binaryDecoder, error) {
+	switch {
+	case len(data) < 2:
+		return nil, fmt.Errorf("invalid binary encoding: too short")
+	case data[0] != binaryFormatV1:
+		return nil, fmt.Errorf("unsupported binary encoding format %d", data[0])
+	case data[1] != kind:
+		return nil, fmt.Errorf("invalid binary encoding: wrong kind of value")
+	}
+	return &binaryDecoder{data: data[2:]}, nil
+}
+
+func (d *binaryDecoder) fail(msg string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("invalid binary encoding: %s", msg)
+	}
+	d.data = nil
+}
+
+// finish returns the first error that occurred while decoding, or an error
+// if there is data left over.
+func (d *binaryDecoder) finish() error {
+	if d.err == nil && len(d.data) != 0 {
+		d.fail("unexpected data after value")
+	}
+	return d.err
+}
+
+func (d *binaryDecoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail("unexpected end of data")
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	n, l := binary.Uvarint(d.data)
+	switch {
+	case l == 0:
+		d.fail("unexpected end of data")
+		return 0
+	case l < 0:
+		d.fail("number out of range")
+		return 0
+	}
+	d.data = d.data[l:]
+	return n
+}
+
+// count reads the length of a sequence whose items each occupy at least
+// min bytes, failing if there isn't enough data left for them. This avoids
+// allocating huge slices for corrupt lengths.
+func (d *binaryDecoder) count(min int) int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)/min) {
+		d.fail("unexpected end of data")
+		return 0
+	}
+	return int(n)
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.count(1)
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *binaryDecoder) version() Version {
+	return Version{
+		Major:      d.uvarint(),
+		Minor:      d.uvarint(),
+		Patch:      d.uvarint(),
+		Prerelease: VersionExtra(d.string()),
+		Metadata:   VersionExtra(d.string()),
+	}
+}
+
+// Each encoded version is at least five bytes long, because each of its
+// fields requires at least one byte.
+const binaryMinVersionLen = 5
+
+func (d *binaryDecoder) list() List {
+	n := d.count(binaryMinVersionLen)
+	if n == 0 {
+		// Decode an empty list as nil, as is idiomatic, rather than as an
+		// empty slice.
+		return nil
+	}
+	ret := make(List, n)
+	for i := range ret {
+		ret[i] = d.version()
+	}
+	return ret
+}
+
+// exactList reads a list that must be sorted as by sortExactList.
+func (d *binaryDecoder) exactList() List {
+	ret := d.list()
+	for i := 1; i < len(ret); i++ {
+		if !exactLess(ret[i-1], ret[i]) {
+			d.fail("exact versions out of order")
+			return nil
+		}
+	}
+	return ret
+}
+
+// channelNames reads the channel names of a set, which must be valid,
+// sorted and distinct. There is always at least one, since otherwise the set
+// would have been encoded as binarySetNormal.
+func (d *binaryDecoder) channelNames() []string {
+	n := d.count(1)
+	if n == 0 {
+		d.fail("set has no channels")
+		return nil
+	}
+	ret := make([]string, n)
+	for i := range ret {
+		ret[i] = d.string()
+		switch {
+		case d.err != nil:
+			return nil
+		case !validPrerelease(ret[i]):
+			d.fail("invalid channel name")
+		case i > 0 && ret[i-1] >= ret[i]:
+			d.fail("channels out of order")
+		}
+	}
+	return ret
+}
+
+// ranges reads a range list, which must already be canonical for the kind
+// of version that the given function canonicalizes bounds for.
+func (d *binaryDecoder) ranges(canon func(Version) Version) rangeList {
+	n := d.count(binaryMinVersionLen + 1)
+	if n == 0 {
+		return nil
+	}
+	ret := make(rangeList, n)
+	for i := range ret {
+		r := versionRange{lower: d.version()}
+		switch d.byte() {
+		case 0:
+			r.unbounded = true
+		case 1:
+			r.upper = d.version()
+		default:
+			d.fail("invalid range")
+		}
+		ret[i] = r
+	}
+	if d.err != nil {
+		return nil
+	}
+
+	for i, r := range ret {
+		switch {
+		case canon(r.lower) != r.lower || (!r.unbounded && canon(r.upper) != r.upper):
+			d.fail("range bound is not canonical")
+		case !r.unbounded && !r.lower.LessThan(r.upper):
+			d.fail("range is empty")
+		case i > 0 && !ret[i-1].upper.LessThan(r.lower):
+			d.fail("ranges out of order")
+		case r.unbounded && i != len(ret)-1:
+			d.fail("ranges out of order")
+		}
+	}
+	return ret
+}
+
+// checkBinarySetNormal returns an error if the given set does not meet the
+// requirements of setNormal that aren't already checked as it is decoded,
+// since a set that isn't canonical would not compare as equal to others.
+func checkBinarySetNormal(n setNormal) error {
+	if len(n.channels) != 0 {
+		pruned := n
+		pruned.channels = append([]channelRanges(nil), n.channels...)
+		pruned.pruneChannels()
+		if len(pruned.channels) != len(n.channels) {
+			return fmt.Errorf("invalid binary encoding: set distinguishes channels that it doesn't need to")
+		}
+	}
+	for _, v := range n.include {
+		if n.rangesHave(v) {
+			return fmt.Errorf("invalid binary encoding: included version %s is already covered by a range", v)
+		}
+	}
+	for _, v := range n.exclude {
+		if !n.rangesHave(v) {
+			return fmt.Errorf("invalid binary encoding: excluded version %s is not covered by a range", v)
+		}
+	}
+	for _, v := range n.requested {
+		if !n.Has(v) {
+			return fmt.Errorf("invalid binary encoding: requested version %s is not a member", v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build go1.18
+// +build go1.18
+
+package versions
+
+import (
+	"reflect"
+	"testing"
+)
+
+// FuzzVersionBinary complements TestBinaryRoundTripRandom on Go versions
+// that support native fuzzing, checking that arbitrary data either fails to
+// decode or decodes to a version that survives a round trip unchanged.
+func FuzzVersionBinary(f *testing.F) {
+	for _, str := range []string{"0.0.0", "1.0.0", "1.0.300-beta.1+abc", "18446744073709551615.0.0-0"} {
+		enc, err := MustParseVersion(str).MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(enc)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v Version
+		if err := v.UnmarshalBinary(data); err != nil {
+			return
+		}
+		enc, err := v.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to re-encode %#v: %s", v, err)
+		}
+		var got Version
+		if err := got.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode re-encoded %#v: %s", v, err)
+		}
+		if got != v {
+			t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, v)
+		}
+	})
+}
+
+// FuzzListBinary is like FuzzVersionBinary, but for lists of versions.
+func FuzzListBinary(f *testing.F) {
+	for _, strs := range [][]string{nil, {"1.0.0"}, {"2.0.0+b", "1.0.0-rc.1", "2.0.0+a"}} {
+		var l List
+		for _, str := range strs {
+			l = append(l, MustParseVersion(str))
+		}
+		enc, err := l.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(enc)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var l List
+		if err := l.UnmarshalBinary(data); err != nil {
+			return
+		}
+		enc, err := l.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to re-encode %#v: %s", l, err)
+		}
+		var got List
+		if err := got.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode re-encoded %#v: %s", l, err)
+		}
+		if !reflect.DeepEqual(got, l) {
+			t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, l)
+		}
+	})
+}
+
+// FuzzSetBinary complements TestBinaryRoundTripRandom on Go versions that
+// support native fuzzing, checking that arbitrary data either fails to decode
+// or decodes to a set that survives a round trip unchanged.
+func FuzzSetBinary(f *testing.F) {
+	for _, str := range []string{"*", "1.0.0", "^1.2 || 2.0.0-beta1", ">=1.0.0 !1.5.0"} {
+		enc, err := MustMakeSet(MeetingConstraintsString(str)).MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(enc)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var set Set
+		if err := set.UnmarshalBinary(data); err != nil {
+			return
+		}
+		enc, err := set.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to re-encode %#v: %s", set, err)
+		}
+		var got Set
+		if err := got.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode re-encoded %#v: %s", set, err)
+		}
+		if !reflect.DeepEqual(got, set) {
+			t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, set)
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package versions
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestVersionBinary(t *testing.T) {
+	tests := []struct {
+		Version Version
+		Want    []byte
+	}{
+		{
+			Unspecified,
+			[]byte{1, 'v', 0, 0, 0, 0, 0},
+		},
+		{
+			MustParseVersion("1.2.3"),
+			[]byte{1, 'v', 1, 2, 3, 0, 0},
+		},
+		{
+			MustParseVersion("1.0.300-beta.1+abc"),
+			[]byte{
+				1, 'v', 1, 0, 0xac, 0x02,
+				6, 'b', 'e', 't', 'a', '.', '1',
+				3, 'a', 'b', 'c',
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Version.String(), func(t *testing.T) {
+			got, err := test.Version.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.Want) {
+				t.Errorf("wrong encoding\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+
+			var v Version
+			if err := v.UnmarshalBinary(got); err != nil {
+				t.Fatal(err)
+			}
+			if v != test.Version {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", v, test.Version)
+			}
+		})
+	}
+}
+
+func TestListBinary(t *testing.T) {
+	tests := []List{
+		nil,
+		{MustParseVersion("1.0.0")},
+		{
+			MustParseVersion("2.0.0+b"),
+			MustParseVersion("1.0.0-beta"),
+			MustParseVersion("2.0.0+a"),
+			MustParseVersion("2.0.0+a"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test), func(t *testing.T) {
+			enc, err := test.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got List
+			if err := got.UnmarshalBinary(enc); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test)
+			}
+		})
+	}
+}
+
+func TestSetBinary(t *testing.T) {
+	tests := []Set{
+		All,
+		None,
+		Released,
+		All.Subtract(Released),
+		Only(MustParseVersion("1.0.0+abc")),
+		MustMakeSet(MeetingConstraintsString("^1.2 || 2.0.0-beta1")),
+		MustMakeSet(MeetingConstraintsString(">=1.0.0 !1.5.0")),
+		AtLeast(MustParseVersion("1.0.0")).Subtract(Only(MustParseVersion("1.2.0-beta"))),
+		All.Union(Only(MustParseVersion("1.0.0"))),
+		Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0"))),
+		Union(Released, PrereleaseChannel("beta"), Only(MustParseVersion("1.0.0-rc.1"))),
+		Prerelease.Subtract(Union(PrereleaseChannel("rc"), PrereleaseChannel("rc.1.2"))),
+	}
+
+	for _, test := range tests {
+		t.Run(test.GoString(), func(t *testing.T) {
+			enc, err := test.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Set
+			if err := got.UnmarshalBinary(enc); err != nil {
+				t.Fatal(err)
+			}
+
+			want := test.Normalize()
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := got.AllRequested().List(), test.AllRequested().List(); !reflect.DeepEqual(sortExactList(got), sortExactList(want)) {
+				t.Errorf("wrong requested versions\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	validVersion, _ := MustParseVersion("1.0.0").MarshalBinary()
+	validSet, _ := MustMakeSet(MeetingConstraintsString("^1.0.0")).MarshalBinary()
+
+	tests := map[string]struct {
+		Data   []byte
+		Target interface{ UnmarshalBinary([]byte) error }
+		Want   string
+	}{
+		"empty": {
+			nil,
+			new(Version),
+			"invalid binary encoding: too short",
+		},
+		"future format": {
+			append([]byte{2}, validVersion[1:]...),
+			new(Version),
+			"unsupported binary encoding format 2",
+		},
+		"wrong kind": {
+			validVersion,
+			new(Set),
+			"invalid binary encoding: wrong kind of value",
+		},
+		"truncated": {
+			validSet[:len(validSet)-3],
+			new(Set),
+			"invalid binary encoding: unexpected end of data",
+		},
+		"trailing data": {
+			append(append([]byte(nil), validVersion...), 0),
+			new(Version),
+			"invalid binary encoding: unexpected data after value",
+		},
+		"huge list": {
+			[]byte{1, 'l', 0xff, 0xff, 0xff, 0xff, 0x0f},
+			new(List),
+			"invalid binary encoding: unexpected end of data",
+		},
+		"empty range": {
+			[]byte{1, 's', 2, 1, 2, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+			new(Set),
+			"invalid binary encoding: range is empty",
+		},
+		"channels out of order": {
+			[]byte{1, 's', 3, 0, 2, 2, 'r', 'c', 4, 'b', 'e', 't', 'a', 0, 0, 0, 0, 0, 0},
+			new(Set),
+			"invalid binary encoding: channels out of order",
+		},
+		"unneeded channel": {
+			[]byte{1, 's', 3, 0, 1, 2, 'r', 'c', 0, 0, 0, 0, 0},
+			new(Set),
+			"invalid binary encoding: set distinguishes channels that it doesn't need to",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.Target.UnmarshalBinary(test.Data)
+			if err == nil {
+				t.Fatalf("succeeded; want error")
+			}
+			if got, want := err.Error(), test.Want; got != want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+// TestBinaryRoundTripRandom checks that randomly-generated versions and sets
+// survive a round trip through the binary encoding, and that corrupted
+// encodings are either rejected or decode to valid values.
+func TestBinaryRoundTripRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		l := make(List, rnd.Intn(4))
+		for i := range l {
+			l[i] = randomVersion(rnd)
+		}
+		enc, err := l.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var gotList List
+		if err := gotList.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode %#v: %s", l, err)
+		}
+		if len(l) == 0 {
+			l = nil
+		}
+		if !reflect.DeepEqual(gotList, l) {
+			t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", gotList, l)
+		}
+		checkBinaryCorrupt(t, rnd, enc, new(List))
+
+		set := randomSet(rnd, 3)
+		enc, err = set.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var gotSet Set
+		if err := gotSet.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode %#v: %s", set, err)
+		}
+		if want := set.Normalize(); !reflect.DeepEqual(gotSet, want) {
+			t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", gotSet, want)
+		}
+		checkBinaryCorrupt(t, rnd, enc, new(Set))
+	}
+}
+
+// checkBinaryCorrupt changes a random byte of the given encoding and then
+// decodes it into the given target, which must not panic. If decoding
+// succeeds then the result must itself survive a round trip.
+func checkBinaryCorrupt(t *testing.T, rnd *rand.Rand, enc []byte, target interface {
+	UnmarshalBinary([]byte) error
+	MarshalBinary() ([]byte, error)
+}) {
+	t.Helper()
+	corrupt := append([]byte(nil), enc...)
+	corrupt[rnd.Intn(len(corrupt))] = byte(rnd.Intn(256))
+	if err := target.UnmarshalBinary(corrupt); err != nil {
+		return
+	}
+	again, err := target.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to re-encode %#v: %s", target, err)
+	}
+	if err := target.UnmarshalBinary(again); err != nil {
+		t.Fatalf("failed to decode re-encoded %#v: %s", target, err)
+	}
+}
+
+func randomVersion(rnd *rand.Rand) Version {
+	extras := []VersionExtra{"", "", "0", "beta", "beta.1", "rc.2", "1.x"}
+	v := Version{
+		Major:      uint64(rnd.Intn(3)),
+		Minor:      uint64(rnd.Intn(3)),
+		Patch:      uint64(rnd.Intn(3)),
+		Prerelease: extras[rnd.Intn(len(extras))],
+		Metadata:   extras[rnd.Intn(len(extras))],
+	}
+	if rnd.Intn(10) == 0 {
+		v.Major = rnd.Uint64()
+	}
+	return v
+}
+
+func randomSet(rnd *rand.Rand, depth int) Set {
+	n := rnd.Intn(10)
+	if depth == 0 {
+		n = rnd.Intn(6)
+	}
+	switch n {
+	case 0:
+		return Only(randomVersion(rnd))
+	case 1:
+		return AtLeast(randomVersion(rnd))
+	case 2:
+		return OlderThan(randomVersion(rnd))
+	case 3:
+		return Released
+	case 4:
+		return Selection(randomVersion(rnd), randomVersion(rnd))
+	case 5:
+		channels := []string{"beta", "rc", "1"}
+		return PrereleaseChannel(channels[rnd.Intn(len(channels))])
+	case 6:
+		return Union(randomSet(rnd, depth-1), randomSet(rnd, depth-1))
+	case 7:
+		return Intersection(randomSet(rnd, depth-1), randomSet(rnd, depth-1))
+	case 8:
+		return randomSet(rnd, depth-1).Subtract(randomSet(rnd, depth-1))
+	default:
+		return All
+	}
+}
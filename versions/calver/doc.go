@@ -0,0 +1,15 @@
+// Package calver is a companion to package versions that deals with calendar
+// versioning, where some segments of a version number are derived from the
+// release date, as described at https://calver.org/.
+//
+// Calendar versions are parsed using a Format, such as "YYYY.0M.MICRO", which
+// describes what each segment means and validates that the date segments
+// represent a real date. The results are ordinary versions.Version values,
+// with the segments of the format mapped to the major, minor and patch
+// numbers in order, and so they can be used with all of the sets and lists in
+// package versions.
+//
+// Because the tilde and caret operators of constraint strings assume semantic
+// versioning, this package instead offers sets that are defined relative to
+// the current date, such as the versions released within the last few months.
+package calver
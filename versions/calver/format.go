@@ -0,0 +1,340 @@
+package calver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// Format describes the meaning of each segment of a calendar version.
+//
+// A format is written as a sequence of up to three dot-separated tokens, such
+// as "YYYY.0M.MICRO" or "YY.0M". The available tokens are:
+//
+//	YYYY   full year, as in 2006 or 2016
+//	YY     short year, as in 6 or 16 (that is, the year minus 2000)
+//	0Y     zero-padded short year, as in 06 or 16
+//	MM     short month, as in 1 or 11
+//	0M     zero-padded month, as in 01 or 11
+//	WW     short week of the year, as in 1 or 33
+//	0W     zero-padded week of the year, as in 01 or 33
+//	DD     short day of the month, as in 1 or 31
+//	0D     zero-padded day of the month, as in 01 or 31
+//	MAJOR  a number that isn't derived from the date
+//	MINOR  a number that isn't derived from the date
+//	MICRO  a number that isn't derived from the date
+//
+// The first token must be a year, followed optionally by either a month or
+// a week. A day may follow a month. The remaining tokens, if any, must be
+// MAJOR, MINOR or MICRO. Week 1 is the first seven days of the year, week 2
+// the next seven, and so on.
+type Format struct {
+	str    string
+	tokens []token
+}
+
+type token string
+
+const (
+	tokenFullYear   token = "YYYY"
+	tokenShortYear  token = "YY"
+	tokenPaddedYear token = "0Y"
+	tokenMonth      token = "MM"
+	tokenPaddedMon  token = "0M"
+	tokenWeek       token = "WW"
+	tokenPaddedWeek token = "0W"
+	tokenDay        token = "DD"
+	tokenPaddedDay  token = "0D"
+	tokenMajor      token = "MAJOR"
+	tokenMinor      token = "MINOR"
+	tokenMicro      token = "MICRO"
+)
+
+func (t token) isYear() bool {
+	return t == tokenFullYear || t == tokenShortYear || t == tokenPaddedYear
+}
+
+func (t token) isMonth() bool {
+	return t == tokenMonth || t == tokenPaddedMon
+}
+
+func (t token) isWeek() bool {
+	return t == tokenWeek || t == tokenPaddedWeek
+}
+
+func (t token) isDay() bool {
+	return t == tokenDay || t == tokenPaddedDay
+}
+
+func (t token) isCounter() bool {
+	return t == tokenMajor || t == tokenMinor || t == tokenMicro
+}
+
+func (t token) isPadded() bool {
+	return t == tokenPaddedYear || t == tokenPaddedMon || t == tokenPaddedWeek || t == tokenPaddedDay
+}
+
+// ParseFormat parses a calendar versioning format string, as described in the
+// documentation for Format.
+func ParseFormat(s string) (Format, error) {
+	if strings.TrimSpace(s) == "" {
+		return Format{}, fmt.Errorf("empty version format")
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Format{}, fmt.Errorf("invalid version format %q; at most three segments are allowed", s)
+	}
+	ret := Format{str: s, tokens: make([]token, len(parts))}
+	for i, part := range parts {
+		t := token(part)
+		switch {
+		case t.isYear() || t.isMonth() || t.isWeek() || t.isDay() || t.isCounter():
+			// valid token
+		default:
+			return Format{}, fmt.Errorf("invalid version format %q; unsupported segment %q", s, part)
+		}
+
+		var prev token
+		if i > 0 {
+			prev = ret.tokens[i-1]
+		}
+		switch {
+		case i == 0 && !t.isYear():
+			return Format{}, fmt.Errorf("invalid version format %q; the first segment must be a year", s)
+		case i > 0 && t.isYear():
+			return Format{}, fmt.Errorf("invalid version format %q; only one year segment is allowed", s)
+		case (t.isMonth() || t.isWeek()) && !prev.isYear():
+			return Format{}, fmt.Errorf("invalid version format %q; %s must immediately follow the year", s, part)
+		case t.isDay() && !prev.isMonth():
+			return Format{}, fmt.Errorf("invalid version format %q; %s must immediately follow the month", s, part)
+		}
+		ret.tokens[i] = t
+	}
+	return ret, nil
+}
+
+// MustParseFormat is the same as ParseFormat except that it will panic
+// instead of returning an error.
+func MustParseFormat(s string) Format {
+	f, err := ParseFormat(s)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// String returns the format string the receiver was parsed from.
+func (f Format) String() string {
+	return f.str
+}
+
+func (f Format) GoString() string {
+	return fmt.Sprintf("calver.MustParseFormat(%q)", f.str)
+}
+
+// Parse parses the given string as a version in the receiving format,
+// returning an error if the segments don't match the format or if the date
+// segments don't represent a valid date.
+//
+// The segments of the format are mapped to the major, minor and patch numbers
+// of the result in order, with any unused fields set to zero. The version
+// may also have a prerelease suffix introduced by "-", as in "2024.03.1-rc1",
+// which becomes the prerelease portion of the result.
+func (f Format) Parse(s string) (versions.Version, error) {
+	var v versions.Version
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return versions.Unspecified, fmt.Errorf("empty version string")
+	}
+
+	if hyphen := strings.IndexByte(str, '-'); hyphen >= 0 {
+		pre := str[hyphen+1:]
+		if !validPrerelease(pre) {
+			return versions.Unspecified, fmt.Errorf("invalid version %q; prerelease suffix must be dot-separated letters, digits and hyphens", s)
+		}
+		v.Prerelease = versions.VersionExtra(pre)
+		str = str[:hyphen]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) != len(f.tokens) {
+		return versions.Unspecified, fmt.Errorf("invalid version %q; must have the format %s", s, f.str)
+	}
+	nums := make([]uint64, len(parts))
+	for i, part := range parts {
+		t := f.tokens[i]
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return versions.Unspecified, fmt.Errorf("invalid version %q; %s segment must be a number", s, t)
+		}
+		switch {
+		case t.isPadded() && len(part) < 2:
+			return versions.Unspecified, fmt.Errorf("invalid version %q; %s segment must have two digits", s, t)
+		case !t.isPadded() && len(part) > 1 && part[0] == '0':
+			return versions.Unspecified, fmt.Errorf("invalid version %q; %s segment must not have leading zeros", s, t)
+		case t == tokenFullYear && len(part) != 4:
+			return versions.Unspecified, fmt.Errorf("invalid version %q; %s segment must have four digits", s, t)
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return versions.Unspecified, fmt.Errorf("invalid version %q; %s segment is too large", s, t)
+		}
+		nums[i] = n
+	}
+	if err := f.validateDate(nums); err != nil {
+		return versions.Unspecified, fmt.Errorf("invalid version %q; %s", s, err)
+	}
+
+	fields := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, n := range nums {
+		*fields[i] = n
+	}
+	return v, nil
+}
+
+// MustParse is the same as Parse except that it will panic instead of
+// returning an error.
+func (f Format) MustParse(s string) versions.Version {
+	v, err := f.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Format returns the given version as a string in the receiving format,
+// using the padding that the format calls for.
+func (f Format) Format(v versions.Version) string {
+	nums := versionNums(v)
+	parts := make([]string, len(f.tokens))
+	for i, t := range f.tokens {
+		if t.isPadded() {
+			parts[i] = fmt.Sprintf("%02d", nums[i])
+		} else {
+			parts[i] = strconv.FormatUint(nums[i], 10)
+		}
+	}
+	ret := strings.Join(parts, ".")
+	if v.Prerelease != "" {
+		ret += "-" + string(v.Prerelease)
+	}
+	return ret
+}
+
+// Date returns the start of the period that the date segments of the given
+// version represent, in UTC. For example, the date for version 2024.3.1 in
+// the format "YYYY.MM.MICRO" is midnight on the first of March 2024.
+func (f Format) Date(v versions.Version) time.Time {
+	nums := versionNums(v)
+	year := int(nums[0])
+	if f.tokens[0] != tokenFullYear {
+		year += 2000
+	}
+	month, day := time.January, 1
+	if len(f.tokens) > 1 {
+		switch t := f.tokens[1]; {
+		case t.isMonth():
+			month = time.Month(nums[1])
+		case t.isWeek():
+			day = 1 + int(nums[1]-1)*7
+		}
+	}
+	if len(f.tokens) > 2 && f.tokens[2].isDay() {
+		day = int(nums[2])
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// ForDate returns the first version in the receiving format for the given
+// date, with the date segments taken from the date and any other segments
+// set to zero.
+//
+// Formats with short years can only represent years from 2000 onwards, so
+// the result for earlier dates has zero in place of the year.
+func (f Format) ForDate(t time.Time) versions.Version {
+	nums := f.dateNums(t)
+	var v versions.Version
+	fields := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, n := range nums {
+		*fields[i] = n
+	}
+	return v
+}
+
+// dateNums returns the values of the date segments of the format for the
+// given date.
+func (f Format) dateNums(t time.Time) []uint64 {
+	var ret []uint64
+	for _, tok := range f.tokens {
+		switch {
+		case tok == tokenFullYear:
+			ret = append(ret, uint64(t.Year()))
+		case tok.isYear():
+			if t.Year() < 2000 {
+				ret = append(ret, 0)
+			} else {
+				ret = append(ret, uint64(t.Year()-2000))
+			}
+		case tok.isMonth():
+			ret = append(ret, uint64(t.Month()))
+		case tok.isWeek():
+			ret = append(ret, uint64((t.YearDay()-1)/7+1))
+		case tok.isDay():
+			ret = append(ret, uint64(t.Day()))
+		}
+	}
+	return ret
+}
+
+func (f Format) validateDate(nums []uint64) error {
+	year := nums[0]
+	if f.tokens[0] != tokenFullYear {
+		year += 2000
+	}
+	for i, t := range f.tokens {
+		n := nums[i]
+		switch {
+		case t.isMonth() && (n < 1 || n > 12):
+			return fmt.Errorf("month must be between 1 and 12")
+		case t.isWeek() && (n < 1 || n > 53):
+			return fmt.Errorf("week must be between 1 and 53")
+		case t.isDay():
+			// The day always follows the month, as enforced by ParseFormat.
+			max := daysIn(int(year), time.Month(nums[i-1]))
+			if n < 1 || n > uint64(max) {
+				return fmt.Errorf("day must be between 1 and %d", max)
+			}
+		}
+	}
+	return nil
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func versionNums(v versions.Version) []uint64 {
+	return []uint64{v.Major, v.Minor, v.Patch}
+}
+
+func validPrerelease(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, ident := range strings.Split(s, ".") {
+		if ident == "" {
+			return false
+		}
+		for _, c := range ident {
+			switch {
+			case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
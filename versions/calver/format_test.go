@@ -0,0 +1,126 @@
+package calver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		Input   string
+		WantErr bool
+	}{
+		{"YYYY.MM.MICRO", false},
+		{"YY.0M", false},
+		{"0Y.0M.0D", false},
+		{"YYYY.0W", false},
+		{"YYYY.MINOR.MICRO", false},
+		{"YYYY", false},
+
+		{"", true},
+		{"MM.YYYY", true},
+		{"MAJOR.MINOR", true},
+		{"YYYY.DD", true},
+		{"YYYY.MICRO.MM", true},
+		{"YYYY.YY", true},
+		{"YYYY.MM.DD.MICRO", true},
+		{"YYYY.mm", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			_, err := ParseFormat(test.Input)
+			if test.WantErr && err == nil {
+				t.Fatalf("unexpected success")
+			}
+			if !test.WantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	tests := []struct {
+		Format  string
+		Input   string
+		Want    versions.Version
+		WantErr bool
+	}{
+		{"YYYY.0M.MICRO", "2024.03.1", versions.MustParseVersion("2024.3.1"), false},
+		{"YYYY.MM.MICRO", "2024.3.1", versions.MustParseVersion("2024.3.1"), false},
+		{"YY.0M", "24.10", versions.MustParseVersion("24.10.0"), false},
+		{"0Y.0M.0D", "05.02.28", versions.MustParseVersion("5.2.28"), false},
+		{"YYYY.MM.DD", "2024.2.29", versions.MustParseVersion("2024.2.29"), false},
+		{"YYYY.0W", "2024.53", versions.MustParseVersion("2024.53.0"), false},
+		{"YYYY.0M.MICRO", "2024.03.1-rc.1", versions.MustParseVersion("2024.3.1-rc.1"), false},
+
+		{"YYYY.0M.MICRO", "", versions.Unspecified, true},
+		{"YYYY.0M.MICRO", "2024.3.1", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "2024.03.1", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "24.3.1", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "2024.13.1", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "2024.0.1", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "2024.3", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "2024.3.1.2", versions.Unspecified, true},
+		{"YYYY.MM.MICRO", "2024.3.x", versions.Unspecified, true},
+		{"YYYY.MM.DD", "2023.2.29", versions.Unspecified, true},
+		{"YYYY.MM.DD", "2024.4.31", versions.Unspecified, true},
+		{"YYYY.0W", "2024.54", versions.Unspecified, true},
+		{"YYYY.0M.MICRO", "2024.03.1-", versions.Unspecified, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Format+" "+test.Input, func(t *testing.T) {
+			f := MustParseFormat(test.Format)
+			got, err := f.Parse(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !got.Same(test.Want) || got.Prerelease != test.Want.Prerelease {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+			if got, want := f.Format(got), test.Input; got != want {
+				t.Errorf("wrong formatted result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		Format  string
+		Version string
+		Want    string
+	}{
+		{"YYYY.0M.MICRO", "2024.03.1", "2024-03-01"},
+		{"YY.0M", "24.10", "2024-10-01"},
+		{"0Y.0M.0D", "05.02.28", "2005-02-28"},
+		{"YYYY.0W", "2024.02", "2024-01-08"},
+		{"YYYY.MICRO", "2024.5", "2024-01-01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Format+" "+test.Version, func(t *testing.T) {
+			f := MustParseFormat(test.Format)
+			got := f.Date(f.MustParse(test.Version)).Format("2006-01-02")
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+
+	f := MustParseFormat("YYYY.0M.0D")
+	date := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+	if got, want := f.Format(f.ForDate(date)), "2024.03.09"; got != want {
+		t.Errorf("wrong ForDate result\ngot:  %s\nwant: %s", got, want)
+	}
+}
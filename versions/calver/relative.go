@@ -0,0 +1,81 @@
+package calver
+
+import (
+	"time"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// Clock returns the current time. The sets that are relative to the current
+// date take a Clock so that callers can control which date they are
+// evaluated against, such as in tests. Pass time.Now to use the system clock.
+//
+// The clock is consulted only once, when the set is created, so a set does
+// not change as time passes.
+type Clock func() time.Time
+
+// ReleasedWithinMonths returns a version set that contains the versions in
+// the receiving format whose date falls within the last n months, according
+// to the given clock, including the current month.
+//
+// The set is only as precise as the format allows. For a format with no
+// month or week segment, any version from the year that was n months ago or
+// later is included. Versions with dates after the current date are not
+// included.
+func (f Format) ReleasedWithinMonths(clock Clock, n int) versions.Set {
+	if n < 0 {
+		n = 0
+	}
+	now := clock().UTC()
+
+	// We do our own month arithmetic here because time.AddDate normalizes
+	// dates like "February 31st" into the following month.
+	year, month := now.Year(), int(now.Month())-n
+	for month < 1 {
+		month += 12
+		year--
+	}
+	day := now.Day()
+	if max := daysIn(year, time.Month(month)); day > max {
+		day = max
+	}
+	since := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	return versions.Intersection(
+		versions.AtLeast(f.lowerBound(f.dateNums(since))),
+		versions.OlderThan(f.upperBound(f.dateNums(now))),
+	)
+}
+
+// SameYear returns a version set that contains the versions in the receiving
+// format whose date is in the current year, according to the given clock,
+// and no later than the current date.
+func (f Format) SameYear(clock Clock) versions.Set {
+	now := clock().UTC()
+	return versions.Intersection(
+		versions.AtLeast(f.lowerBound(f.dateNums(now)[:1])),
+		versions.OlderThan(f.upperBound(f.dateNums(now))),
+	)
+}
+
+// lowerBound returns the lowest possible version whose date segments start
+// with the given numbers, including any prereleases.
+func (f Format) lowerBound(nums []uint64) versions.Version {
+	v := versions.Version{Prerelease: "0"}
+	fields := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, n := range nums {
+		*fields[i] = n
+	}
+	return v
+}
+
+// upperBound returns the lowest version that is greater than all of the
+// versions whose date segments are the given numbers. Incrementing the last
+// date segment can produce an invalid date, such as month 13, but such a
+// version still sorts correctly as a bound.
+func (f Format) upperBound(nums []uint64) versions.Version {
+	next := make([]uint64, len(nums))
+	copy(next, nums)
+	next[len(next)-1]++
+	return f.lowerBound(next)
+}
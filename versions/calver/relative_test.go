@@ -0,0 +1,80 @@
+package calver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFormatReleasedWithinMonths(t *testing.T) {
+	clock := func() time.Time {
+		return time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		Format  string
+		Months  int
+		Version string
+		Want    bool
+	}{
+		{"YYYY.0M.MICRO", 3, "2024.03.5", true},
+		{"YYYY.0M.MICRO", 3, "2024.03.0-rc1", true},
+		{"YYYY.0M.MICRO", 3, "2023.12.0", true},
+		{"YYYY.0M.MICRO", 3, "2023.11.9", false},
+		{"YYYY.0M.MICRO", 3, "2024.04.0", false},
+		{"YYYY.0M.MICRO", 3, "2024.04.0-rc1", false},
+		{"YYYY.0M.MICRO", 0, "2024.03.1", true},
+		{"YYYY.0M.MICRO", 0, "2024.02.1", false},
+		{"YYYY.0M.MICRO", 1, "2024.02.1", true},
+		{"YY.0M", 12, "23.03", true},
+		{"YY.0M", 12, "23.02", false},
+		{"YYYY.0M.0D", 1, "2024.02.29", true},
+		{"YYYY.0M.0D", 1, "2024.02.28", false},
+		{"YYYY.0M.0D", 1, "2024.03.31", true},
+		{"YYYY.MICRO", 6, "2023.9", true},
+		{"YYYY.MICRO", 2, "2023.9", false},
+		{"YYYY.0W", 1, "2024.09", true},
+		{"YYYY.0W", 1, "2024.08", false},
+		{"YYYY.0W", 1, "2024.14", false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %d months has %s", test.Format, test.Months, test.Version), func(t *testing.T) {
+			f := MustParseFormat(test.Format)
+			set := f.ReleasedWithinMonths(clock, test.Months)
+			if got, want := set.Has(f.MustParse(test.Version)), test.Want; got != want {
+				t.Errorf("wrong result\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
+
+func TestFormatSameYear(t *testing.T) {
+	clock := func() time.Time {
+		return time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		Format  string
+		Version string
+		Want    bool
+	}{
+		{"YYYY.0M.MICRO", "2024.01.0", true},
+		{"YYYY.0M.MICRO", "2024.06.3", true},
+		{"YYYY.0M.MICRO", "2024.07.0", false},
+		{"YYYY.0M.MICRO", "2023.12.9", false},
+		{"YY.MINOR", "24.0", true},
+		{"YY.MINOR", "23.9", false},
+		{"YY.MINOR", "25.0", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Format+" "+test.Version, func(t *testing.T) {
+			f := MustParseFormat(test.Format)
+			set := f.SameYear(clock)
+			if got, want := set.Has(f.MustParse(test.Version)), test.Want; got != want {
+				t.Errorf("wrong result\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package constraints
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Parse parses a constraint string using a syntax similar to that used by
@@ -10,7 +11,8 @@ import (
 // systems is not guaranteed, but instead we aim for familiarity in the choice
 // of operators and their meanings. The syntax described here is considered the
 // canonical syntax for this package, but a Ruby-style syntax is also offered
-// via the function "ParseRubyStyle".
+// via the function "ParseRubyStyle", and ParseNPM offers the exact syntax
+// used by npm.
 //
 // A constraint string is a sequence of selection sets delimited by ||, with
 // each selection set being a whitespace-delimited sequence of selections.
@@ -130,51 +132,70 @@ import (
 // If there are syntax errors or ambiguities in the provided string then an
 // error is returned. All errors returned by this function are suitable for
 // display to English-speaking end-users, and avoid any Go-specific
-// terminology.
+// terminology. They are all of type *ParseError, which additionally describes
+// the position of the problem within the given string.
 func Parse(str string) (UnionSpec, error) {
-	str = strings.TrimSpace(str)
+	spec, _, err := ParseWithSources(str)
+	return spec, err
+}
 
-	if str == "" {
-		return nil, fmt.Errorf("empty specification")
+// ParseWithSources is like Parse except that it also returns the sources of
+// the selections in the result, so that messages about them can refer back to
+// the portions of the string they were parsed from.
+func ParseWithSources(str string) (UnionSpec, Sources, error) {
+	if strings.TrimSpace(str) == "" {
+		return nil, nil, newParseError(str, 0, len(str), ErrEmpty, "empty specification")
 	}
 
 	// Most constraint strings contain only one selection, so we'll
 	// allocate under that assumption and re-allocate if needed.
 	uspec := make(UnionSpec, 0, 1)
 	ispec := make(IntersectionSpec, 0, 1)
+	sources := make(Sources, 0, 1)
+	isources := make([]Source, 0, 1)
 
-	remain := str
+	// remain is always a suffix of str, so that we can find the offset of
+	// any problem we detect in order to report it.
+	remain := trimLeftSpace(str)
+	offset := func() int {
+		return len(str) - len(remain)
+	}
 	for {
 		var selection SelectionSpec
 		var err error
-		selection, remain, err = parseSelection(remain)
+		lowerStart := offset()
+		selection, remain, err = parseSelection(str, lowerStart)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		lowerEnd := offset()
 
-		remain = strings.TrimSpace(remain)
+		remain = trimLeftSpace(remain)
 
 		if len(remain) > 0 && remain[0] == '-' {
 			// Looks like user wants to make a range expression, so we'll
 			// look for another selection.
-			remain = strings.TrimSpace(remain[1:])
+			opStart := offset()
+			remain = trimLeftSpace(remain[1:])
 			if remain == "" {
-				return nil, fmt.Errorf(`operator "-" must be followed by another version selection to specify the upper limit of the range`)
+				return nil, nil, newParseError(str, opStart, 1, ErrIncompleteRange, `operator "-" must be followed by another version selection to specify the upper limit of the range`)
 			}
 
 			var lower, upper SelectionSpec
 			lower = selection
-			upper, remain, err = parseSelection(remain)
-			remain = strings.TrimSpace(remain)
+			upperStart := offset()
+			upper, remain, err = parseSelection(str, upperStart)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
+			upperEnd := offset()
+			remain = trimLeftSpace(remain)
 
 			if lower.Operator != OpUnconstrained {
-				return nil, fmt.Errorf(`lower bound of range specified with "-" operator must be an exact version`)
+				return nil, nil, newParseError(str, lowerStart, lowerEnd-lowerStart, ErrRangeBoundOperator, `lower bound of range specified with "-" operator must be an exact version`)
 			}
 			if upper.Operator != OpUnconstrained {
-				return nil, fmt.Errorf(`upper bound of range specified with "-" operator must be an exact version`)
+				return nil, nil, newParseError(str, upperStart, upperEnd-upperStart, ErrRangeBoundOperator, `upper bound of range specified with "-" operator must be an exact version`)
 			}
 
 			lower.Operator = OpGreaterThanOrEqual
@@ -186,6 +207,10 @@ func Parse(str string) (UnionSpec, error) {
 				upper.Boundary = upper.Boundary.ConstrainToUpperBound()
 			}
 			ispec = append(ispec, lower, upper)
+
+			// Both selections come from the range as a whole.
+			source := sourceFor(str, lowerStart, upperEnd)
+			isources = append(isources, source, source)
 		} else {
 			if selection.Operator == OpUnconstrained {
 				// Select a default operator based on whether the version
@@ -220,6 +245,7 @@ func Parse(str string) (UnionSpec, error) {
 				}
 			}
 			ispec = append(ispec, selection)
+			isources = append(isources, sourceFor(str, lowerStart, lowerEnd))
 		}
 
 		if len(remain) == 0 {
@@ -228,35 +254,44 @@ func Parse(str string) (UnionSpec, error) {
 		}
 
 		if remain[0] == ',' {
-			return nil, fmt.Errorf(`commas are not needed to separate version selections; separate with spaces instead`)
+			return nil, nil, newParseError(str, offset(), 1, ErrComma, `commas are not needed to separate version selections; separate with spaces instead`)
 		}
 
 		if remain[0] == '|' {
+			opStart := offset()
 			if !strings.HasPrefix(remain, "||") {
 				// User was probably trying for "||", so we'll produce a specialized error
-				return nil, fmt.Errorf(`single "|" is not a valid operator; did you mean "||" to specify an alternative?`)
+				return nil, nil, newParseError(str, opStart, 1, ErrSinglePipe, `single "|" is not a valid operator; did you mean "||" to specify an alternative?`)
 			}
-			remain = strings.TrimSpace(remain[2:])
+			remain = trimLeftSpace(remain[2:])
 			if remain == "" {
-				return nil, fmt.Errorf(`operator "||" must be followed by another version selection`)
+				return nil, nil, newParseError(str, opStart, 2, ErrIncompleteAlternative, `operator "||" must be followed by another version selection`)
 			}
 
 			// Begin a new IntersectionSpec, added to our single UnionSpec
 			uspec = append(uspec, ispec)
 			ispec = make(IntersectionSpec, 0, 1)
+			sources = append(sources, isources)
+			isources = make([]Source, 0, 1)
 		}
 	}
 
 	uspec = append(uspec, ispec)
+	sources = append(sources, isources)
 
-	return uspec, nil
+	return uspec, sources, nil
 }
 
-// parseSelection parses one canon-style selection from the prefix of the
-// given string, returning the result along with the remaining unconsumed
-// string for the caller to use for further processing.
-func parseSelection(str string) (SelectionSpec, string, error) {
+// parseSelection parses one canon-style selection from the given string,
+// starting at the given offset, returning the result along with the remaining
+// unconsumed string for the caller to use for further processing.
+//
+// The full input string is required, rather than just the portion to be
+// parsed, so that any errors can describe positions within it.
+func parseSelection(input string, offset int) (SelectionSpec, string, error) {
+	str := input[offset:]
 	raw, remain := scanConstraint(str)
+	pos := raw.offsets(len(str) - len(remain))
 	var spec SelectionSpec
 
 	if len(str) == len(remain) {
@@ -264,11 +299,11 @@ func parseSelection(str string) (SelectionSpec, string, error) {
 			// User seems to be trying to use a "v" prefix, like "v1.0.0"
 			// (This only catches this for operator-less specs; there's a
 			// more general check for this below too.)
-			return spec, remain, fmt.Errorf(`a "v" prefix should not be used when specifying versions`)
+			return spec, remain, newParseError(input, offset, 1, ErrVPrefix, `a "v" prefix should not be used when specifying versions`)
 		}
 
 		// If we made no progress at all then the selection must be entirely invalid.
-		return spec, remain, fmt.Errorf("the sequence %q is not valid", remain)
+		return spec, remain, newParseError(input, offset, len(remain), ErrInvalidSequence, "the sequence %q is not valid", remain)
 	}
 
 	switch raw.op {
@@ -302,22 +337,22 @@ func parseSelection(str string) (SelectionSpec, string, error) {
 			spec.Operator = OpGreaterThanOrEqualMinorOnly
 		}
 	case "=<":
-		return spec, remain, fmt.Errorf("invalid constraint operator %q; did you mean \"<=\"?", raw.op)
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \"<=\"?", raw.op)
 	case "=>":
-		return spec, remain, fmt.Errorf("invalid constraint operator %q; did you mean \">=\"?", raw.op)
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \">=\"?", raw.op)
 	default:
-		return spec, remain, fmt.Errorf("invalid constraint operator %q", raw.op)
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q", raw.op)
 	}
 
 	if raw.sep != "" {
-		if strings.ContainsRune(raw.sep, 'v') {
-			return spec, remain, fmt.Errorf(`a "v" prefix should not be used when specifying versions`)
+		if i := strings.IndexRune(raw.sep, 'v'); i >= 0 {
+			return spec, remain, newParseError(input, offset+pos.sep+i, 1, ErrVPrefix, `a "v" prefix should not be used when specifying versions`)
 		}
-		return spec, remain, fmt.Errorf("no spaces allowed after operator %q", raw.op)
+		return spec, remain, newParseError(input, offset+pos.sep, len(raw.sep), ErrSpaceAfterOperator, "no spaces allowed after operator %q", raw.op)
 	}
 
 	if raw.numCt > 3 {
-		return spec, remain, fmt.Errorf("too many numbered portions; only three are allowed (major, minor, patch)")
+		return spec, remain, newParseError(input, offset+pos.nums[3]-1, pos.numsEnd-pos.nums[3]+1, ErrTooManySegments, "too many numbered portions; only three are allowed (major, minor, patch)")
 	}
 
 	// Unspecified portions are either zero or wildcard depending on whether
@@ -337,17 +372,20 @@ func parseSelection(str string) (SelectionSpec, string, error) {
 			// If we find a non-wildcard after we've already seen a wildcard
 			// then this specification is inconsistent, which is an error.
 			if seenWild {
-				return spec, remain, fmt.Errorf("can't use exact %s segment after a previous segment was wildcard", rawNumNames[i])
+				return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrWildcardAfterExact, "can't use exact %s segment after a previous segment was wildcard", rawNumNames[i])
+			}
+			if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+				return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrNumberTooLarge, "%s number is too large", rawNumNames[i])
 			}
 		}
 	}
 
 	if seenWild {
 		if raw.pre != "" {
-			return spec, remain, fmt.Errorf(`can't use prerelease segment (introduced by "-") in a version with wildcards`)
+			return spec, remain, newParseError(input, offset+pos.pre, len(raw.pre)+1, ErrPrereleaseWithWildcard, `can't use prerelease segment (introduced by "-") in a version with wildcards`)
 		}
 		if raw.meta != "" {
-			return spec, remain, fmt.Errorf(`can't use build metadata segment (introduced by "+") in a version with wildcards`)
+			return spec, remain, newParseError(input, offset+pos.meta, len(raw.meta)+1, ErrMetadataWithWildcard, `can't use build metadata segment (introduced by "+") in a version with wildcards`)
 		}
 	}
 
@@ -355,3 +393,10 @@ func parseSelection(str string) (SelectionSpec, string, error) {
 
 	return spec, remain, nil
 }
+
+// trimLeftSpace removes any leading whitespace from the given string, leaving
+// the result as a suffix of the original so that offsets into it can still be
+// related to the original string.
+func trimLeftSpace(s string) string {
+	return strings.TrimLeftFunc(s, unicode.IsSpace)
+}
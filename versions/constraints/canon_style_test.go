@@ -666,6 +666,32 @@ func TestParse(t *testing.T) {
 			nil,
 			`a "v" prefix should not be used when specifying versions`,
 		},
+		{
+			"18446744073709551615.0.0",
+			UnionSpec{
+				IntersectionSpec{
+					SelectionSpec{
+						Operator: OpEqual,
+						Boundary: VersionSpec{
+							Major: NumConstraint{Num: 18446744073709551615},
+							Minor: NumConstraint{Num: 0},
+							Patch: NumConstraint{Num: 0},
+						},
+					},
+				},
+			},
+			"",
+		},
+		{
+			"18446744073709551616.0.0",
+			nil,
+			"major number is too large",
+		},
+		{
+			">=1.0.0 <1.0.18446744073709551616",
+			nil,
+			"patch number is too large",
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,271 @@
+package constraints
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseCargo parses a version requirement string using the syntax of Rust's
+// package manager "cargo", as used in Cargo.toml files.
+//
+// A requirement is a comma-separated sequence of selections, all of which
+// must match a version for the requirement to match it. Unlike Parse, a
+// selection with no operator is a "^" selection, and so "1.2.3" is the same
+// as "^1.2.3". The available operators are:
+//
+//	^  Compatible with the boundary (the default)
+//	~  Greater than with implied upper limit
+//	=  Equal, or matching a partial version
+//	>  Greater than
+//	>= Greater than or equal
+//	<  Less than
+//	<= Less than or equal
+//
+// The "^" operator allows any newer version that doesn't change the leftmost
+// non-zero segment of the boundary, or the leftmost segment that is present
+// if they are all zero:
+//
+//	^1.2.3 is equivalent to >=1.2.3 <2.0.0
+//	^1.2   is equivalent to >=1.2.0 <2.0.0
+//	^1     is equivalent to >=1.0.0 <2.0.0
+//	^0.2.3 is equivalent to >=0.2.3 <0.3.0
+//	^0.2   is equivalent to >=0.2.0 <0.3.0
+//	^0.0.3 is equivalent to >=0.0.3 <0.0.4
+//	^0.0   is equivalent to >=0.0.0 <0.1.0
+//	^0     is equivalent to >=0.0.0 <1.0.0
+//
+// The "~" operator allows only newer patch versions, unless only the major
+// version is given:
+//
+//	~1.2.3 is equivalent to >=1.2.3 <1.3.0
+//	~1.2   is equivalent to >=1.2.0 <1.3.0
+//	~1     is equivalent to >=1.0.0 <2.0.0
+//
+// A version with no operator can also use wildcards, written as "*", "x" or
+// "X", as in "1.*" or "1.2.*". A wildcard for the major version, as in "*",
+// matches all versions and must be the only selection in the requirement.
+// Versions given with the other operators may omit segments, which then
+// behave like wildcards: ">1.2" is equivalent to ">=1.3.0", for example.
+//
+// Build metadata is not allowed in requirements.
+//
+// The result uses the existing operators where they have the same meaning,
+// adding OpGreaterThanOrEqualPrereleaseOnly for "^0.0.x" selections.
+// Cargo has special rules for matching pre-release versions against a
+// requirement, which are not represented in the result. Use the
+// MeetingConstraintsCargo function in the "versions" package, in the parent
+// directory, to find the versions that a requirement matches.
+//
+// If there are syntax errors in the provided string then an error is
+// returned. All errors returned by this function are of type *ParseError
+// and are suitable for display to English-speaking end-users.
+func ParseCargo(str string) (IntersectionSpec, error) {
+	spec, _, err := ParseCargoWithSources(str)
+	return spec, err
+}
+
+// ParseCargoWithSources is like ParseCargo except that it also returns the
+// sources of the selections in the result, so that messages about them can
+// refer back to the portions of the string they were parsed from.
+func ParseCargoWithSources(str string) (IntersectionSpec, Sources, error) {
+	if strings.TrimSpace(str) == "" {
+		return nil, nil, newParseError(str, 0, len(str), ErrEmpty, "empty specification")
+	}
+
+	var spec IntersectionSpec
+	var sources []Source
+	var anyStart, anyEnd int // position of a selection matching all versions
+	anyFound := false
+
+	// remain is always a suffix of str, so that we can find the offset of
+	// any problem we detect in order to report it.
+	remain := trimLeftSpace(str)
+	for {
+		start := len(str) - len(remain)
+		sel, newRemain, err := parseCargoSelection(str, start)
+		if err != nil {
+			return nil, nil, err
+		}
+		end := len(str) - len(newRemain)
+		if sel.Operator == OpMatch && sel.Boundary.ConstraintDepth() == Unconstrained {
+			anyStart, anyEnd, anyFound = start, end, true
+		}
+		spec = append(spec, sel)
+		sources = append(sources, sourceFor(str, start, end))
+
+		remain = trimLeftSpace(newRemain)
+		if remain == "" {
+			break
+		}
+
+		offset := len(str) - len(remain)
+		switch {
+		case remain[0] == ',':
+			remain = trimLeftSpace(remain[1:])
+			if remain == "" {
+				return nil, nil, newParseError(str, offset, 1, ErrInvalidSequence, "a comma must be followed by another version selection")
+			}
+		case remain[0] == '|':
+			return nil, nil, newParseError(str, offset, len(remain), ErrMultipleNotAllowed, "alternative version selections are not supported")
+		case remain[0] == '-':
+			// User seems to be trying to use npm-style range constraints
+			return nil, nil, newParseError(str, offset, len(remain), ErrRangeNotAllowed, "range constraints are not supported")
+		default:
+			return nil, nil, newParseError(str, offset, 0, ErrMissingComma, "missing comma after %q", str[start:end])
+		}
+	}
+
+	if anyFound && len(spec) > 1 {
+		return nil, nil, newParseError(str, anyStart, anyEnd-anyStart, ErrMultipleNotAllowed, "a wildcard that matches all versions must be the only selection")
+	}
+
+	return spec, Sources{sources}, nil
+}
+
+// parseCargoSelection parses one Cargo-style selection from the given string,
+// starting at the given offset, returning the result along with the remaining
+// unconsumed string.
+func parseCargoSelection(input string, offset int) (SelectionSpec, string, error) {
+	str := input[offset:]
+	raw, remain := scanConstraint(str)
+	pos := raw.offsets(len(str) - len(remain))
+	var spec SelectionSpec
+
+	if len(str) == len(remain) || raw.numCt == 0 {
+		end := strings.IndexFunc(str, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ','
+		})
+		if end == -1 {
+			end = len(str)
+		}
+		return spec, remain, newParseError(input, offset, end, ErrInvalidSequence, "the sequence %q is not valid", str[:end])
+	}
+
+	switch raw.op {
+	case "", "^", "~", "=", ">", ">=", "<", "<=":
+		// all valid
+	case "=<":
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \"<=\"?", raw.op)
+	case "=>":
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \">=\"?", raw.op)
+	case "~>":
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \"~\"?", raw.op)
+	default:
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q", raw.op)
+	}
+
+	if i := strings.IndexRune(raw.sep, 'v'); i >= 0 {
+		return spec, remain, newParseError(input, offset+pos.sep+i, 1, ErrVPrefix, `a "v" prefix should not be used when specifying versions`)
+	}
+
+	if raw.numCt > 3 {
+		return spec, remain, newParseError(input, offset+pos.nums[3]-1, pos.numsEnd-pos.nums[3]+1, ErrTooManySegments, "too many numbered portions; only three are allowed (major, minor, patch)")
+	}
+
+	seenWild := false
+	for i, s := range raw.nums[:raw.numCt] {
+		switch {
+		case isWildcardNum(s):
+			if raw.op != "" && i == 0 {
+				return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrWildcardNotAllowed, "can't use wildcard for major number with operator %q", raw.op)
+			}
+			seenWild = true
+		case seenWild:
+			return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrWildcardAfterExact, "can't use exact %s segment after a previous segment was wildcard", rawNumNames[i])
+		case len(s) > 1 && s[0] == '0':
+			return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrLeadingZero, "%s number must not have leading zeros", rawNumNames[i])
+		default:
+			if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+				return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrNumberTooLarge, "%s number is too large", rawNumNames[i])
+			}
+		}
+	}
+
+	if raw.pre != "" {
+		if seenWild || raw.numCt < 3 {
+			return spec, remain, newParseError(input, offset+pos.pre, len(raw.pre)+1, ErrPrereleaseWithWildcard, `can't use prerelease segment (introduced by "-") in a version with wildcards or omitted segments`)
+		}
+		if i, l, invalid := invalidPrereleaseIdent(raw.pre); invalid {
+			return spec, remain, newParseError(input, offset+pos.pre+1+i, l, ErrInvalidPrerelease, "prerelease identifiers must not be empty or have leading zeros")
+		}
+	}
+	if raw.meta != "" {
+		return spec, remain, newParseError(input, offset+pos.meta, len(raw.meta)+1, ErrMetadataNotAllowed, `can't use build metadata segment (introduced by "+") in a version requirement`)
+	}
+
+	if remain != "" && !strings.ContainsAny(remain[:1], ",|-") && !unicode.IsSpace(rune(remain[0])) {
+		remainOffset := offset + len(str) - len(remain)
+		end := strings.IndexFunc(remain, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ','
+		})
+		if end == -1 {
+			end = len(remain)
+		}
+		return spec, remain, newParseError(input, remainOffset, end, ErrInvalidCharacters, "invalid characters %q", remain[:end])
+	}
+
+	boundary := raw.VersionSpec()
+	switch raw.op {
+	case "":
+		if seenWild {
+			spec.Operator = OpMatch
+		} else {
+			spec.Operator = cargoCaretOp(boundary)
+		}
+	case "^":
+		spec.Operator = cargoCaretOp(boundary)
+	case "~":
+		if boundary.ConstraintDepth() == ConstrainedMajor {
+			spec.Operator = OpGreaterThanOrEqualMinorOnly
+		} else {
+			spec.Operator = OpGreaterThanOrEqualPatchOnly
+		}
+	case "=":
+		if boundary.IsExact() {
+			spec.Operator = OpEqual
+		} else {
+			spec.Operator = OpMatch
+		}
+	case ">":
+		if boundary.IsExact() {
+			spec.Operator = OpGreaterThan
+		} else {
+			// Greater than a partial version means greater than all of
+			// the versions it matches, so e.g. >1.2 means >=1.3.0.
+			spec.Operator = OpGreaterThanOrEqual
+			boundary = boundary.ConstrainToUpperBound()
+		}
+	case ">=":
+		spec.Operator = OpGreaterThanOrEqual
+	case "<":
+		spec.Operator = OpLessThan
+	case "<=":
+		if boundary.IsExact() {
+			spec.Operator = OpLessThanOrEqual
+		} else {
+			spec.Operator = OpLessThan
+			boundary = boundary.ConstrainToUpperBound()
+		}
+	}
+	if spec.Operator != OpMatch {
+		boundary = boundary.ConstrainToZero()
+	}
+	spec.Boundary = boundary
+
+	return spec, remain, nil
+}
+
+// cargoCaretOp returns the operator that has the same meaning as Cargo's
+// "^" operator for the given boundary, which depends on which of its
+// segments are present and zero.
+func cargoCaretOp(boundary VersionSpec) SelectionOp {
+	switch {
+	case boundary.Major.Num != 0 || boundary.ConstraintDepth() == ConstrainedMajor:
+		return OpGreaterThanOrEqualMinorOnly
+	case boundary.Minor.Num != 0 || boundary.ConstraintDepth() == ConstrainedMinor:
+		return OpGreaterThanOrEqualPatchOnly
+	default:
+		return OpGreaterThanOrEqualPrereleaseOnly
+	}
+}
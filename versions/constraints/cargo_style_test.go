@@ -0,0 +1,91 @@
+package constraints
+
+import (
+	"testing"
+)
+
+func TestParseCargo(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr ParseErrorCode
+	}{
+		{"1.2.3", "^1.2.3", ""},
+		{"^1.2.3", "^1.2.3", ""},
+		{"1.2", "^1.2.0", ""},
+		{"1", "^1.0.0", ""},
+		{"0.2.3", "~0.2.3", ""},
+		{"0.2", "~0.2.0", ""},
+		{"0.0.3", ">=0.0.3 <0.0.4", ""},
+		{"^0.0.3-beta", ">=0.0.3-beta <0.0.4", ""},
+		{"0.0", "~0.0.0", ""},
+		{"0", "~0", ""},
+		{"~1.2.3", "~1.2.3", ""},
+		{"~1.2", "~1.2.0", ""},
+		{"~1", "^1.0.0", ""},
+		{"=1.2.3", "1.2.3", ""},
+		{"=1.2", "1.2.*", ""},
+		{"*", "*", ""},
+		{"1.*", "1.*", ""},
+		{"1.2.*", "1.2.*", ""},
+		{"1.2.x", "1.2.*", ""},
+		{"^1.*", "^1.0.0", ""},
+		{">1.2.3", ">1.2.3", ""},
+		{">1.2", ">=1.3.0", ""},
+		{">1", ">=2.0.0", ""},
+		{">=1.2", ">=1.2.0", ""},
+		{"<1.2", "<1.2.0", ""},
+		{"<=1.2", "<1.3.0", ""},
+		{"<=1.2.3", "<=1.2.3", ""},
+		{">= 1.2.0, < 1.5", ">=1.2.0 <1.5.0", ""},
+		{"  >=1.2.0,<1.5  ", ">=1.2.0 <1.5.0", ""},
+		{"1.2.3-alpha.1", "^1.2.3-alpha.1", ""},
+
+		{"", "", ErrEmpty},
+		{"v1.2.3", "", ErrVPrefix},
+		{"1.2.3+build", "", ErrMetadataNotAllowed},
+		{"1.2-beta", "", ErrPrereleaseWithWildcard},
+		{"1.2.3-01", "", ErrInvalidPrerelease},
+		{"1.2.3-a..b", "", ErrInvalidPrerelease},
+		{"01.2.3", "", ErrLeadingZero},
+		{"1.2.3.4", "", ErrTooManySegments},
+		{"1.*.3", "", ErrWildcardAfterExact},
+		{">*", "", ErrWildcardNotAllowed},
+		{"*, >1.0.0", "", ErrMultipleNotAllowed},
+		{"1.0.0 || 2.0.0", "", ErrMultipleNotAllowed},
+		{"1.0.0 - 2.0.0", "", ErrRangeNotAllowed},
+		{">=1.0.0 <2.0.0", "", ErrMissingComma},
+		{">=1.0.0,", "", ErrInvalidSequence},
+		{"~>1.0", "", ErrInvalidOperator},
+		{"!=1.0.0", "", ErrInvalidOperator},
+		{"1.2.3foo", "", ErrInvalidCharacters},
+		{"99999999999999999999.0.0", "", ErrNumberTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, err := ParseCargo(test.Input)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot:  %s\nwant error: %s", spec, test.WantErr)
+				}
+				if got, want := err.(*ParseError).Code, test.WantErr; got != want {
+					t.Fatalf("wrong error code\ngot:  %s (%s)\nwant: %s", got, err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got, err := Format(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
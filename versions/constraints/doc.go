@@ -10,4 +10,39 @@
 // a version set that contains all versions meeting the given constraints.
 // Package "constraints" does not contain any functionalty for checking versions
 // against constraints since that is provided by package "versions".
+//
+// # JSON Representation
+//
+// The spec types in this package can be marshalled to and unmarshalled from
+// JSON, so that constraints parsed by this package can be consumed by
+// software that doesn't use it, and so that specs can be constructed without
+// writing constraint strings.
+//
+// A UnionSpec is a JSON array of IntersectionSpecs, and an IntersectionSpec
+// is a JSON array of SelectionSpecs. A SelectionSpec is a JSON object like the
+// following, which is the selection that ParseRubyStyleMulti produces for
+// the "~> 1.2" in "~> 1.2, < 1.2.5":
+//
+//	{
+//	  "operator": "greater_than_or_equal_minor_only",
+//	  "boundary": {
+//	    "major": {"num": 1},
+//	    "minor": {"num": 2},
+//	    "patch": {"num": 0, "unconstrained": true}
+//	  }
+//	}
+//
+// The operator is one of the names returned by SelectionOp.MarshalText. The
+// boundary is a VersionSpec, whose numbers each have an "unconstrained"
+// property that is present and true if that number is a wildcard, as with
+// NumConstraint.Unconstrained. A VersionSpec may also have "prerelease" and
+// "metadata" string properties, which are omitted if empty.
+//
+// Sources are not part of a spec and so are not included, but a Sources
+// table can be marshalled separately as an array of arrays of objects like
+// {"text": "~> 1.2", "offset": 0}, whose "filename" property is omitted if
+// empty.
+//
+// Unmarshalling returns an error if the result would not be valid as defined
+// by Validate, such as if an operator is not recognized.
 package constraints
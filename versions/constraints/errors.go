@@ -0,0 +1,216 @@
+package constraints
+
+import (
+	"fmt"
+)
+
+// ParseError is the type of the errors returned by the parsers in this
+// package, describing a problem with a particular part of the input string.
+//
+// The result of Error is a message suitable for display to English-speaking
+// end-users, as with the other errors from this package. Callers that need to
+// react to particular kinds of problem should use Code instead, since the
+// messages may change in future versions.
+type ParseError struct {
+	// Code identifies the kind of problem, using one of the ParseErrorCode
+	// constants declared in this package.
+	Code ParseErrorCode
+
+	// Offset and Length describe the range of bytes in the input string that
+	// the problem relates to. Length may be zero if the problem is that
+	// something is missing, in which case Offset is where it was expected.
+	Offset int
+	Length int
+
+	// Text is the portion of the input string described by Offset and
+	// Length.
+	Text string
+
+	// Message is the description of the problem returned by Error.
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+// ParseErrorCode is a short, stable identifier for a kind of parse error.
+type ParseErrorCode string
+
+const (
+	// ErrEmpty means that the input string contains no specification.
+	ErrEmpty ParseErrorCode = "empty"
+
+	// ErrVPrefix means that a version was written with a "v" prefix.
+	ErrVPrefix ParseErrorCode = "v-prefix"
+
+	// ErrInvalidOperator means that a constraint operator was not recognized.
+	ErrInvalidOperator ParseErrorCode = "invalid-operator"
+
+	// ErrOperatorNotAllowed means that a constraint operator was used where
+	// only an exact version is allowed.
+	ErrOperatorNotAllowed ParseErrorCode = "operator-not-allowed"
+
+	// ErrSpaceAfterOperator means that there are too many spaces, or any
+	// spaces at all, between an operator and its version.
+	ErrSpaceAfterOperator ParseErrorCode = "space-after-operator"
+
+	// ErrExtraneousSpace means that there are spaces at the start or end of
+	// a specification where none are allowed.
+	ErrExtraneousSpace ParseErrorCode = "extraneous-space"
+
+	// ErrInvalidSequence means that a version selection could not be
+	// recognized at all.
+	ErrInvalidSequence ParseErrorCode = "invalid-sequence"
+
+	// ErrInvalidCharacters means that there are unexpected characters after
+	// an otherwise-valid specification.
+	ErrInvalidCharacters ParseErrorCode = "invalid-characters"
+
+	// ErrTooManySegments means that a version has more than three numbered
+	// segments.
+	ErrTooManySegments ParseErrorCode = "too-many-segments"
+
+	// ErrWildcardNotAllowed means that a wildcard was used for a version
+	// segment where wildcards are not permitted.
+	ErrWildcardNotAllowed ParseErrorCode = "wildcard-not-allowed"
+
+	// ErrLeadingZero means that a version number segment has a leading
+	// zero, in a syntax that does not allow them.
+	ErrLeadingZero ParseErrorCode = "leading-zero"
+
+	// ErrMissingSegments means that a version has fewer than three numbered
+	// segments in a syntax that requires all three.
+	ErrMissingSegments ParseErrorCode = "missing-segments"
+
+	// ErrNumberTooLarge means that a version number segment is larger than
+	// the syntax in use allows.
+	ErrNumberTooLarge ParseErrorCode = "number-too-large"
+
+	// ErrWildcardAfterExact means that a wildcard segment is followed by an
+	// exact segment, as in "1.*.2", where only further wildcard segments are
+	// allowed.
+	ErrWildcardAfterExact ParseErrorCode = "wildcard-after-exact"
+
+	// ErrPrereleaseWithWildcard means that a version with wildcards also
+	// has a prerelease segment.
+	ErrPrereleaseWithWildcard ParseErrorCode = "prerelease-with-wildcard"
+
+	// ErrMetadataWithWildcard means that a version with wildcards also has
+	// a build metadata segment.
+	ErrMetadataWithWildcard ParseErrorCode = "metadata-with-wildcard"
+
+	// ErrMetadataNotAllowed means that a version has a build metadata
+	// segment in a syntax that does not allow one.
+	ErrMetadataNotAllowed ParseErrorCode = "metadata-not-allowed"
+
+	// ErrInvalidPrerelease means that a prerelease segment has an empty
+	// identifier or a numeric identifier with leading zeros.
+	ErrInvalidPrerelease ParseErrorCode = "invalid-prerelease"
+
+	// ErrInvalidMetadata means that a build metadata segment has an empty
+	// identifier.
+	ErrInvalidMetadata ParseErrorCode = "invalid-metadata"
+
+	// ErrComma means that commas were used to separate selections in a
+	// syntax that uses spaces instead.
+	ErrComma ParseErrorCode = "comma"
+
+	// ErrMissingComma means that selections were not separated by commas in
+	// a syntax that requires them.
+	ErrMissingComma ParseErrorCode = "missing-comma"
+
+	// ErrSinglePipe means that "|" was used where "||" was probably intended.
+	ErrSinglePipe ParseErrorCode = "single-pipe"
+
+	// ErrIncompleteAlternative means that "||" was not followed by another
+	// selection set.
+	ErrIncompleteAlternative ParseErrorCode = "incomplete-alternative"
+
+	// ErrIncompleteRange means that a range operator "-" was not followed by
+	// an upper bound.
+	ErrIncompleteRange ParseErrorCode = "incomplete-range"
+
+	// ErrRangeBoundOperator means that a bound of a range using the "-"
+	// operator has its own operator.
+	ErrRangeBoundOperator ParseErrorCode = "range-bound-operator"
+
+	// ErrRangeNotAllowed means that a range was given where ranges are not
+	// supported.
+	ErrRangeNotAllowed ParseErrorCode = "range-not-allowed"
+
+	// ErrMultipleNotAllowed means that multiple versions or constraints were
+	// given where only one is allowed.
+	ErrMultipleNotAllowed ParseErrorCode = "multiple-not-allowed"
+)
+
+// newParseError creates a ParseError for the given range of the given input,
+// clamping the range to the bounds of the input if necessary.
+func newParseError(input string, offset, length int, code ParseErrorCode, format string, args ...interface{}) *ParseError {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(input) {
+		offset = len(input)
+	}
+	if length < 0 {
+		length = 0
+	}
+	if offset+length > len(input) {
+		length = len(input) - offset
+	}
+	return &ParseError{
+		Code:    code,
+		Offset:  offset,
+		Length:  length,
+		Text:    input[offset : offset+length],
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// rawOffsets describes the positions of the parts of a rawConstraint relative
+// to the start of the string it was scanned from.
+type rawOffsets struct {
+	op      int
+	sep     int
+	nums    [4]int // the fourth element is the start of any excess segments
+	numsEnd int
+	pre     int
+	meta    int
+	end     int
+}
+
+// offsets calculates the positions of each of the parts of the receiver,
+// given the length of the string that was consumed when scanning it.
+//
+// This must be called before any modifications are made to the receiver.
+func (raw rawConstraint) offsets(consumed int) rawOffsets {
+	var ret rawOffsets
+	ret.sep = len(raw.op)
+	ret.nums[0] = ret.sep + len(raw.sep)
+	for i := 1; i < len(ret.nums); i++ {
+		ret.nums[i] = ret.nums[i-1]
+		if i-1 < len(raw.nums) && i-1 < raw.numCt {
+			ret.nums[i] += len(raw.nums[i-1]) + 1
+		}
+	}
+	ret.end = consumed
+	ret.meta = consumed
+	if raw.meta != "" {
+		ret.meta -= len(raw.meta) + 1
+	}
+	ret.pre = ret.meta
+	if raw.pre != "" {
+		ret.pre -= len(raw.pre) + 1
+	}
+	ret.numsEnd = ret.pre
+	return ret
+}
+
+// prefixParseError returns a copy of the given error, which must be a
+// *ParseError, with the given prefix added to its message.
+func prefixParseError(err error, format string, args ...interface{}) error {
+	ret := *err.(*ParseError)
+	ret.Message = fmt.Sprintf(format, args...) + ret.Message
+	return &ret
+}
@@ -0,0 +1,101 @@
+package constraints
+
+import (
+	"testing"
+)
+
+func TestParseErrorPositions(t *testing.T) {
+	tests := []struct {
+		Parse func(string) error
+		Input string
+		Code  ParseErrorCode
+		Text  string
+	}{
+		{parseCanon, "", ErrEmpty, ""},
+		{parseCanon, "v1.0.0", ErrVPrefix, "v"},
+		{parseCanon, ">= v1.0.0", ErrVPrefix, "v"},
+		{parseCanon, ">= 1.0.0", ErrSpaceAfterOperator, " "},
+		{parseCanon, ">=1.0.0 | <2", ErrSinglePipe, "|"},
+		{parseCanon, "  >=1.0.0 ||  ", ErrIncompleteAlternative, "||"},
+		{parseCanon, ">=1.0.0, <2", ErrComma, ","},
+		{parseCanon, "1.*.2", ErrWildcardAfterExact, "2"},
+		{parseCanon, ">=1.x.0-beta", ErrWildcardAfterExact, "0"},
+		{parseCanon, "1.0.0.0", ErrTooManySegments, ".0"},
+		{parseCanon, "1.* 1.0.0.0.1", ErrTooManySegments, ".0.1"},
+		{parseCanon, "1.*-beta", ErrPrereleaseWithWildcard, "-beta"},
+		{parseCanon, "1.*+abc", ErrMetadataWithWildcard, "+abc"},
+		{parseCanon, "99999999999999999999.0.0", ErrNumberTooLarge, "99999999999999999999"},
+		{parseCanon, ">=1.0.0 <1.99999999999999999999", ErrNumberTooLarge, "99999999999999999999"},
+		{parseCanon, "~1.18446744073709551616", ErrNumberTooLarge, "18446744073709551616"},
+		{parseCanon, "1.0.0 =<2.0.0", ErrInvalidOperator, "=<"},
+		{parseCanon, "1.0.0 -", ErrIncompleteRange, "-"},
+		{parseCanon, "1.0.0 - <2.0.0", ErrRangeBoundOperator, "<2.0.0"},
+		{parseCanon, "1.0.0 foo", ErrInvalidSequence, "foo"},
+		{parseRuby, "~>  1.0", ErrSpaceAfterOperator, "  "},
+		{parseRuby, ">= 1.0.*", ErrWildcardNotAllowed, "*"},
+		{parseRuby, ">= 1.0.0, < 2", ErrMultipleNotAllowed, ", < 2"},
+		{parseRuby, "1.0.0 - 2.0.0", ErrRangeNotAllowed, "- 2.0.0"},
+		{parseRuby, "1.0.0 ", ErrExtraneousSpace, " "},
+		{parseRuby, "~> 1.99999999999999999999", ErrNumberTooLarge, "99999999999999999999"},
+		{parseRubyMulti, ">= 1.0, <= 2.0.0.0", ErrTooManySegments, ".0"},
+		{parseRubyMulti, ">= 1.0 < 2.0", ErrMissingComma, ""},
+		{parseRubyMulti, ">= 1.0, => 2.0", ErrInvalidOperator, "=>"},
+		{parseExact, ">=1.0.0", ErrOperatorNotAllowed, ">="},
+		{parseExact, "1.0.x", ErrWildcardNotAllowed, "x"},
+		{parseExact, "1.0.0 || 1.0.1", ErrMultipleNotAllowed, "|| 1.0.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			err := test.Parse(test.Input)
+			if err == nil {
+				t.Fatalf("unexpected success")
+			}
+			pErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("wrong error type %T; want *ParseError", err)
+			}
+			if got, want := pErr.Code, test.Code; got != want {
+				t.Errorf("wrong code\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := pErr.Text, test.Text; got != want {
+				t.Errorf("wrong text\ngot:  %q\nwant: %q", got, want)
+			}
+			if got, want := test.Input[pErr.Offset:pErr.Offset+pErr.Length], pErr.Text; got != want {
+				t.Errorf("offset and length do not match text\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseErrorCodeWildcardAfterExact(t *testing.T) {
+	// The codes are part of the public API, so their values must not change.
+	_, err := Parse("1.*.2")
+	pErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("wrong error type %T; want *ParseError", err)
+	}
+	if got, want := pErr.Code, ParseErrorCode("wildcard-after-exact"); got != want {
+		t.Errorf("wrong code\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func parseCanon(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+func parseRuby(s string) error {
+	_, err := ParseRubyStyle(s)
+	return err
+}
+
+func parseRubyMulti(s string) error {
+	_, err := ParseRubyStyleMulti(s)
+	return err
+}
+
+func parseExact(s string) error {
+	_, err := ParseExactVersion(s)
+	return err
+}
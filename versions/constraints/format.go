@@ -0,0 +1,272 @@
+package constraints
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Format returns a constraint string in the canonical syntax accepted by
+// Parse that describes the same versions as the given spec.
+//
+// The result is not necessarily identical to any string the spec was
+// originally parsed from, since the constraint model does not retain details
+// such as redundant whitespace or which of several equivalent operators was
+// used. For example, a range written as "1.0.0 - 2.0.0" is formatted as
+// ">=1.0.0 <=2.0.0". However, parsing the result always produces a spec
+// that selects the same versions.
+//
+// An empty UnionSpec or IntersectionSpec, which selects all versions, is
+// formatted as "*". Format returns an error if the spec contains a selection
+// with an operator that isn't one of the SelectionOp constants, since such a
+// selection has no representation that Parse would accept.
+func Format(spec Spec) (string, error) {
+	switch ts := spec.(type) {
+	case UnionSpec:
+		for _, ss := range ts {
+			if _, err := Format(ss); err != nil {
+				return "", err
+			}
+		}
+		return ts.String(), nil
+	case IntersectionSpec:
+		for _, ss := range ts {
+			if _, err := Format(ss); err != nil {
+				return "", err
+			}
+		}
+		return ts.String(), nil
+	case SelectionSpec:
+		if _, ok := selectionOpNames[ts.Operator]; !ok {
+			return "", fmt.Errorf("unsupported constraint operator %s", ts.Operator)
+		}
+		return ts.String(), nil
+	case VersionSpec:
+		return SelectionSpec{Operator: OpMatch, Boundary: ts}.String(), nil
+	case nil:
+		return "*", nil
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+// String returns a representation of the receiver in the canonical constraint
+// syntax accepted by Parse, as described in the documentation for Format.
+func (s UnionSpec) String() string {
+	if len(s) == 0 {
+		return "*"
+	}
+	var buf bytes.Buffer
+	for i, ss := range s {
+		if i != 0 {
+			buf.WriteString(" || ")
+		}
+		buf.WriteString(ss.String())
+	}
+	return buf.String()
+}
+
+// String returns a representation of the receiver in the canonical constraint
+// syntax accepted by Parse, as described in the documentation for Format.
+func (s IntersectionSpec) String() string {
+	if len(s) == 0 {
+		return "*"
+	}
+	var buf bytes.Buffer
+	for i, ss := range s {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(ss.String())
+	}
+	return buf.String()
+}
+
+// String returns a representation of the receiver in the canonical constraint
+// syntax accepted by Parse, as described in the documentation for Format.
+//
+// A small number of selections have no single-selection representation in
+// the canonical syntax, such as a "^" selection whose boundary has a major
+// version of zero or any OpGreaterThanOrEqualPrereleaseOnly selection, and so
+// are written as two selections that have the same effect when they appear
+// within a selection set.
+//
+// If the operator of the receiver is not one of the SelectionOp constants
+// declared in this package then the selection has no representation that
+// Parse would accept, and so String instead returns a description of it that
+// Parse rejects. Use Format to get an error for such selections instead.
+func (s SelectionSpec) String() string {
+	if s.Operator == OpUnconstrained || s.Operator == OpMatch {
+		return formatMatch(s.Boundary)
+	}
+
+	// All of the other operators use the zero-constrained form of their
+	// boundary, which is equivalent to how Parse treats wildcards used
+	// with explicit operators.
+	boundary := s.Boundary.ConstrainToZero()
+	switch s.Operator {
+	case OpEqual:
+		return boundary.String()
+	case OpNotEqual:
+		return "!" + boundary.String()
+	case OpGreaterThan:
+		return ">" + boundary.String()
+	case OpGreaterThanOrEqual:
+		return ">=" + boundary.String()
+	case OpLessThan:
+		return "<" + boundary.String()
+	case OpLessThanOrEqual:
+		return "<=" + boundary.String()
+	case OpGreaterThanOrEqualPatchOnly:
+		// A "~" with all three segments present is always patch-only.
+		return "~" + boundary.String()
+	case OpGreaterThanOrEqualMinorOnly:
+		switch {
+		case boundary.Major.Num != 0:
+			return "^" + boundary.String()
+		case boundary == (VersionSpec{}):
+			// "~" with only one segment is minor-only even when the major
+			// version is zero, whereas "^" would become patch-only.
+			return "~0"
+		default:
+			upper := boundary
+			upper.Major.Num++
+			upper.Minor.Num = 0
+			upper.Patch.Num = 0
+			upper.Prerelease = ""
+			upper.Metadata = ""
+			return fmt.Sprintf(">=%s <%s", boundary, upper)
+		}
+	case OpGreaterThanOrEqualPrereleaseOnly:
+		upper := boundary
+		upper.Patch.Num++
+		upper.Prerelease = ""
+		upper.Metadata = ""
+		return fmt.Sprintf(">=%s <%s", boundary, upper)
+	default:
+		// should never happen for a selection produced by a parser, because
+		// the above cases are exhaustive for all of the valid operators.
+		return fmt.Sprintf("%s(%s)", s.Operator, boundary)
+	}
+}
+
+// formatMatch formats the given boundary as a selection with an implied
+// "match" operator, using wildcards for any unconstrained segments.
+func formatMatch(boundary VersionSpec) string {
+	switch boundary.ConstraintDepth() {
+	case Unconstrained:
+		return "*"
+	case ConstrainedMajor:
+		return fmt.Sprintf("%s.*", boundary.Major)
+	case ConstrainedMinor:
+		return fmt.Sprintf("%s.%s.*", boundary.Major, boundary.Minor)
+	default:
+		return boundary.String()
+	}
+}
+
+// FormatRubyStyle returns a constraint string in the syntax accepted by
+// ParseRubyStyleMulti that describes the same versions as the given spec,
+// with multiple selections separated by commas.
+//
+// Ruby-style constraints cannot express alternatives, so this function returns
+// an error if given a UnionSpec with more than one element. Specs that select
+// all versions are formatted as ">= 0", which is the conventional rubygems
+// spelling of that idea.
+func FormatRubyStyle(spec Spec) (string, error) {
+	switch ts := spec.(type) {
+	case UnionSpec:
+		switch len(ts) {
+		case 0:
+			return ">= 0", nil
+		case 1:
+			return FormatRubyStyle(ts[0])
+		default:
+			return "", fmt.Errorf("can't represent alternative selection sets in Ruby-style syntax")
+		}
+	case IntersectionSpec:
+		if len(ts) == 0 {
+			return ">= 0", nil
+		}
+		parts := make([]string, len(ts))
+		for i, ss := range ts {
+			part, err := FormatRubyStyle(ss)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ", "), nil
+	case SelectionSpec:
+		return formatRubyStyleSelection(ts)
+	case VersionSpec:
+		return formatRubyStyleSelection(SelectionSpec{Operator: OpMatch, Boundary: ts})
+	case nil:
+		return ">= 0", nil
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+func formatRubyStyleSelection(s SelectionSpec) (string, error) {
+	if s.Operator == OpUnconstrained || s.Operator == OpMatch {
+		// Ruby-style syntax has no wildcards, but the pessimistic operator
+		// can describe the same ranges.
+		switch s.Boundary.ConstraintDepth() {
+		case Unconstrained:
+			return ">= 0", nil
+		case ConstrainedMajor:
+			return fmt.Sprintf("~> %s.0", s.Boundary.Major), nil
+		case ConstrainedMinor:
+			return fmt.Sprintf("~> %s.%s.0", s.Boundary.Major, s.Boundary.Minor), nil
+		default:
+			return "= " + s.Boundary.String(), nil
+		}
+	}
+
+	boundary := s.Boundary.ConstrainToZero()
+	switch s.Operator {
+	case OpEqual:
+		return "= " + boundary.String(), nil
+	case OpNotEqual:
+		return "!= " + boundary.String(), nil
+	case OpGreaterThan:
+		return "> " + boundary.String(), nil
+	case OpGreaterThanOrEqual:
+		if boundary == (VersionSpec{}) {
+			return ">= 0", nil
+		}
+		return ">= " + boundary.String(), nil
+	case OpLessThan:
+		return "< " + boundary.String(), nil
+	case OpLessThanOrEqual:
+		return "<= " + boundary.String(), nil
+	case OpGreaterThanOrEqualPatchOnly:
+		// The pessimistic operator with three segments is patch-only.
+		return "~> " + boundary.String(), nil
+	case OpGreaterThanOrEqualMinorOnly:
+		if boundary.Patch.Num == 0 && boundary.Prerelease == "" && boundary.Metadata == "" {
+			// The pessimistic operator with two segments is minor-only.
+			return fmt.Sprintf("~> %s.%s", boundary.Major, boundary.Minor), nil
+		}
+		upper := boundary
+		upper.Major.Num++
+		upper.Minor.Num = 0
+		upper.Patch.Num = 0
+		upper.Prerelease = ""
+		upper.Metadata = ""
+		return fmt.Sprintf(">= %s, < %s", boundary, upper), nil
+	case OpGreaterThanOrEqualPrereleaseOnly:
+		upper := boundary
+		upper.Patch.Num++
+		upper.Prerelease = ""
+		upper.Metadata = ""
+		return fmt.Sprintf(">= %s, < %s", boundary, upper), nil
+	default:
+		return "", fmt.Errorf("unsupported constraint operator %s", s.Operator)
+	}
+}
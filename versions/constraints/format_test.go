@@ -0,0 +1,204 @@
+package constraints
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{"1", "1.0.0"},
+		{"1.0.0-beta.1+abc", "1.0.0-beta.1+abc"},
+		{"=1.0.0", "1.0.0"},
+		{"!1.0.0", "!1.0.0"},
+		{">1.0.0", ">1.0.0"},
+		{">1.*", ">=2.0.0"},
+		{">=1.0", ">=1.0.0"},
+		{"<1", "<1.0.0"},
+		{"<=1.2", "<=1.2.0"},
+		{"<=1.2.*", "<1.3.0"},
+		{"~1", "^1.0.0"},
+		{"~1.2", "~1.2.0"},
+		{"~1.2.3", "~1.2.3"},
+		{"^1.2.3", "^1.2.3"},
+		{"^0.1.2", "~0.1.2"},
+		{"~0", "~0"},
+		{"*", "*"},
+		{"1.*", "1.*"},
+		{"1.x.x", "1.*"},
+		{"1.2.X", "1.2.*"},
+		{"1.0.0 - 2.0.0", ">=1.0.0 <=2.0.0"},
+		{"1.0 - 2.*", ">=1.0.0 <3.0.0"},
+		{">=1.0.0 <2.0.0 || 1.0.0-beta1 || =2.0.2", ">=1.0.0 <2.0.0 || 1.0.0-beta1 || 2.0.2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, err := Parse(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Format(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+
+			// The formatted string must parse to the same spec, aside from
+			// the range operator which has no explicit representation.
+			again, err := Parse(got)
+			if err != nil {
+				t.Fatalf("result is not valid: %s", err)
+			}
+			if got, _ := Format(again); got != test.Want {
+				t.Errorf("formatting the result again changed it\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestFormatHandBuilt(t *testing.T) {
+	tests := []struct {
+		Input Spec
+		Want  string
+	}{
+		{
+			nil,
+			"*",
+		},
+		{
+			UnionSpec{},
+			"*",
+		},
+		{
+			VersionSpec{
+				Major: NumConstraint{Num: 2},
+				Minor: NumConstraint{Unconstrained: true},
+				Patch: NumConstraint{Unconstrained: true},
+			},
+			"2.*",
+		},
+		{
+			SelectionSpec{
+				Operator: OpGreaterThanOrEqualMinorOnly,
+				Boundary: VersionSpec{
+					Major: NumConstraint{Num: 0},
+					Minor: NumConstraint{Num: 2},
+					Patch: NumConstraint{Num: 1},
+				},
+			},
+			">=0.2.1 <1.0.0",
+		},
+		{
+			SelectionSpec{
+				Operator: OpGreaterThanOrEqual,
+				Boundary: VersionSpec{
+					Major: NumConstraint{Num: 1},
+					Minor: NumConstraint{Unconstrained: true},
+					Patch: NumConstraint{Unconstrained: true},
+				},
+			},
+			">=1.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Want, func(t *testing.T) {
+			got, err := Format(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestFormatInvalidOperator(t *testing.T) {
+	sel := SelectionSpec{
+		Operator: SelectionOp('?'),
+		Boundary: VersionSpec{Major: NumConstraint{Num: 1}},
+	}
+
+	for _, spec := range []Spec{sel, IntersectionSpec{sel}, UnionSpec{{sel}}} {
+		_, err := Format(spec)
+		if err == nil {
+			t.Fatalf("Format succeeded for %#v; want error", spec)
+		}
+		if got, want := err.Error(), "unsupported constraint operator SelectionOp(63)"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	}
+
+	// String is called implicitly by the fmt package, so it must not panic
+	// even though the result isn't valid constraint syntax.
+	if got, want := fmt.Sprint(sel), "SelectionOp(63)(1.0.0)"; got != want {
+		t.Errorf("wrong String result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestFormatRubyStyle(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr string
+	}{
+		{"1", "= 1.0.0", ""},
+		{"1.0.0-beta.1", "= 1.0.0-beta.1", ""},
+		{"!1.0.0", "!= 1.0.0", ""},
+		{">1.0.0 <2", "> 1.0.0, < 2.0.0", ""},
+		{"~1.2.3", "~> 1.2.3", ""},
+		{"~1", "~> 1.0", ""},
+		{"^1.2.3", ">= 1.2.3, < 2.0.0", ""},
+		{"^0.1.2", "~> 0.1.2", ""},
+		{"1.*", "~> 1.0", ""},
+		{"1.2.*", "~> 1.2.0", ""},
+		{"*", ">= 0", ""},
+		{"1 || 2", "", "can't represent alternative selection sets in Ruby-style syntax"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, err := Parse(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := FormatRubyStyle(spec)
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != test.WantErr {
+				t.Fatalf("wrong error\ngot:  %s\nwant: %s", gotErr, test.WantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+
+			again, err := ParseRubyStyleMulti(got)
+			if err != nil {
+				t.Fatalf("result is not valid: %s", err)
+			}
+			gotAgain, err := FormatRubyStyle(again)
+			if err != nil {
+				t.Fatalf("can't format result again: %s", err)
+			}
+			for _, problem := range deep.Equal(gotAgain, test.Want) {
+				t.Error(problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package constraints
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// selectionOpNames are the names used for each SelectionOp in JSON.
+var selectionOpNames = map[SelectionOp]string{
+	OpUnconstrained:                    "unconstrained",
+	OpGreaterThan:                      "greater_than",
+	OpLessThan:                         "less_than",
+	OpGreaterThanOrEqual:               "greater_than_or_equal",
+	OpGreaterThanOrEqualPatchOnly:      "greater_than_or_equal_patch_only",
+	OpGreaterThanOrEqualMinorOnly:      "greater_than_or_equal_minor_only",
+	OpGreaterThanOrEqualPrereleaseOnly: "greater_than_or_equal_prerelease_only",
+	OpLessThanOrEqual:                  "less_than_or_equal",
+	OpEqual:                            "equal",
+	OpNotEqual:                         "not_equal",
+	OpMatch:                            "match",
+}
+
+// MarshalText is an implementation of encoding.TextMarshaler that returns the
+// spelled-out name of the receiver used in the JSON representation of specs,
+// such as "greater_than_or_equal" for OpGreaterThanOrEqual.
+func (op SelectionOp) MarshalText() ([]byte, error) {
+	name, ok := selectionOpNames[op]
+	if !ok {
+		return nil, fmt.Errorf("unsupported selection operator %s", op)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText is an implementation of encoding.TextUnmarshaler that accepts
+// the names produced by MarshalText.
+func (op *SelectionOp) UnmarshalText(text []byte) error {
+	name := string(text)
+	for candidate, candidateName := range selectionOpNames {
+		if candidateName == name {
+			*op = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported selection operator %q", name)
+}
+
+// selectionJSON has the same fields as SelectionSpec but with the tags of
+// its JSON representation, and without its methods.
+type selectionJSON struct {
+	Operator SelectionOp `json:"operator"`
+	Boundary VersionSpec `json:"boundary"`
+}
+
+// MarshalJSON is an implementation of json.Marshaler, producing the JSON
+// representation described in the package documentation.
+func (s SelectionSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(selectionJSON{
+		Operator: s.Operator,
+		Boundary: s.Boundary,
+	})
+}
+
+// UnmarshalJSON is an implementation of json.Unmarshaler, accepting the JSON
+// representation described in the package documentation and returning an
+// error if the result is not valid as defined by Validate.
+func (s *SelectionSpec) UnmarshalJSON(data []byte) error {
+	var raw selectionJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = SelectionSpec{
+		Operator: raw.Operator,
+		Boundary: raw.Boundary,
+	}
+	return Validate(*s)
+}
+
+// versionSpecJSON has the same fields as VersionSpec but not its methods, so
+// that VersionSpec.UnmarshalJSON can use the default decoding behavior.
+type versionSpecJSON VersionSpec
+
+// UnmarshalJSON is an implementation of json.Unmarshaler, accepting the JSON
+// representation described in the package documentation and returning an
+// error if the result would not be consistent as defined by ConstraintDepth.
+func (s *VersionSpec) UnmarshalJSON(data []byte) error {
+	var raw versionSpecJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	spec := VersionSpec(raw)
+	if err := spec.checkConsistent(); err != nil {
+		return err
+	}
+	*s = spec
+	return nil
+}
+
+// checkConsistent returns an error if the receiver is not consistent as
+// defined by ConstraintDepth.
+func (s VersionSpec) checkConsistent() error {
+	switch {
+	case s.Major.Unconstrained && !s.Minor.Unconstrained:
+		return fmt.Errorf("minor version is constrained but major version is a wildcard")
+	case s.Minor.Unconstrained && !s.Patch.Unconstrained:
+		return fmt.Errorf("patch version is constrained but minor version is a wildcard")
+	case s.Patch.Unconstrained && s.Prerelease != "":
+		return fmt.Errorf("prerelease %q is given but patch version is a wildcard", s.Prerelease)
+	case s.Patch.Unconstrained && s.Metadata != "":
+		return fmt.Errorf("metadata %q is given but patch version is a wildcard", s.Metadata)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package constraints
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestSpecJSON(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{
+			"*",
+			`[[{"operator":"match","boundary":{"major":{"num":0,"unconstrained":true},"minor":{"num":0,"unconstrained":true},"patch":{"num":0,"unconstrained":true}}}]]`,
+		},
+		{
+			">=1.2 <2",
+			`[[{"operator":"greater_than_or_equal","boundary":{"major":{"num":1},"minor":{"num":2},"patch":{"num":0}}},{"operator":"less_than","boundary":{"major":{"num":2},"minor":{"num":0},"patch":{"num":0}}}]]`,
+		},
+		{
+			"1.x || 2.0.0-beta.1+abc",
+			`[[{"operator":"match","boundary":{"major":{"num":1},"minor":{"num":0,"unconstrained":true},"patch":{"num":0,"unconstrained":true}}}],[{"operator":"equal","boundary":{"major":{"num":2},"minor":{"num":0},"patch":{"num":0},"prerelease":"beta.1","metadata":"abc"}}]]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, err := Parse(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buf, err := json.Marshal(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(buf), test.Want; got != want {
+				t.Errorf("wrong JSON\ngot:  %s\nwant: %s", got, want)
+			}
+
+			var got UnionSpec
+			if err := json.Unmarshal(buf, &got); err != nil {
+				t.Fatal(err)
+			}
+			for _, problem := range deep.Equal(got, spec) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func TestSpecJSONErrors(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{
+			`[[{"operator":"approximately","boundary":{"major":{"num":1}}}]]`,
+			`unsupported selection operator "approximately"`,
+		},
+		{
+			`[[{"operator":"equal","boundary":{"major":{"unconstrained":true},"minor":{"num":2}}}]]`,
+			`minor version is constrained but major version is a wildcard`,
+		},
+		{
+			`[[{"operator":"equal","boundary":{"major":{"num":1},"minor":{"num":2},"patch":{"unconstrained":true},"prerelease":"beta"}}]]`,
+			`prerelease "beta" is given but patch version is a wildcard`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			var got UnionSpec
+			err := json.Unmarshal([]byte(test.Input), &got)
+			if err == nil {
+				t.Fatalf("succeeded; want error")
+			}
+			if got, want := err.Error(), test.Want; got != want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestSpecJSONProgrammatic(t *testing.T) {
+	var got IntersectionSpec
+	err := json.Unmarshal([]byte(`[{"operator":"greater_than_or_equal_patch_only","boundary":{"major":{"num":1},"minor":{"num":2},"patch":{"num":3}}}]`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := got.String(), "~1.2.3"; got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,479 @@
+package constraints
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseNPM parses a constraint string using the range syntax of the npm
+// package manager, as implemented by its "node-semver" library.
+//
+// Whereas Parse only aims to be familiar to npm users, ParseNPM follows the
+// node-semver range grammar and its rules for expanding each kind of
+// selection into simpler comparisons. Strings are interpreted as node-semver
+// does when not in its "loose" mode. In particular, and unlike Parse:
+//
+//   - Versions may have a "v" or "=" prefix, and operators may be followed
+//     by spaces, as in ">= v1.2.3".
+//   - "~>" is accepted as another spelling of "~".
+//   - Partial versions like "1.2" are treated as if the missing segments
+//     were wildcards, even with explicit operators. For example, "<=1.2"
+//     is equivalent to "<1.3.0-0".
+//   - The upper bounds implied by "~", "^", wildcards and hyphen ranges
+//     exclude any pre-releases of the upper bound version, as in
+//     "~1.2.3" being equivalent to ">=1.2.3 <1.3.0-0".
+//   - An empty string, or an empty selection set between "||" operators,
+//     matches all versions.
+//   - Build metadata is accepted but ignored.
+//
+// The result is a UnionSpec containing only the operators OpEqual,
+// OpGreaterThan, OpGreaterThanOrEqual, OpLessThan and OpLessThanOrEqual,
+// simplified in the same way that node-semver simplifies its ranges. A
+// selection set that matches all versions is represented as an empty
+// IntersectionSpec.
+//
+// npm also has special rules for matching pre-release versions against a
+// range, which are not represented in the result. Use the
+// MeetingConstraintsNPM function in the "versions" package, in the parent
+// directory, to find the versions that an npm range matches.
+//
+// If there are syntax errors in the provided string then an error is
+// returned. All errors returned by this function are of type *ParseError
+// and are suitable for display to English-speaking end-users.
+func ParseNPM(str string) (UnionSpec, error) {
+	spec, _, err := ParseNPMWithSources(str)
+	return spec, err
+}
+
+// ParseNPMWithSources is like ParseNPM except that it also returns the
+// sources of the selections in the result, so that messages about them can
+// refer back to the portions of the string they were parsed from.
+func ParseNPMWithSources(str string) (UnionSpec, Sources, error) {
+	var uspec UnionSpec
+	var sources Sources
+
+	// remain is always a suffix of str, so that we can find the offset of
+	// any problem we detect in order to report it.
+	remain := trimLeftSpace(str)
+	for {
+		var ispec IntersectionSpec
+		var isources []Source
+		var err error
+		ispec, isources, remain, err = parseNPMSet(str, len(str)-len(remain))
+		if err != nil {
+			return nil, nil, err
+		}
+		uspec = append(uspec, ispec)
+		sources = append(sources, isources)
+
+		if remain == "" {
+			break
+		}
+
+		// parseNPMSet only stops early at a "|", which must be the start
+		// of an "||" operator.
+		if !strings.HasPrefix(remain, "||") {
+			return nil, nil, newParseError(str, len(str)-len(remain), 1, ErrSinglePipe, `single "|" is not a valid operator; did you mean "||" to specify an alternative?`)
+		}
+		remain = trimLeftSpace(remain[2:])
+	}
+
+	uspec, sources = simplifyNPMUnion(uspec, sources)
+	return uspec, sources, nil
+}
+
+// npmComparator is a single selection from an npm range, before it is
+// expanded into simpler selections.
+type npmComparator struct {
+	op      string
+	version VersionSpec
+
+	// start and end are the offsets of the comparator within the input.
+	start, end int
+}
+
+// parseNPMSet parses one space-separated selection set from the given string,
+// starting at the given offset, and returns it along with the sources of its
+// selections and the remaining unconsumed string with any leading whitespace
+// removed.
+//
+// A selection set ends at the end of the string or at the start of a "||"
+// operator.
+func parseNPMSet(input string, offset int) (IntersectionSpec, []Source, string, error) {
+	var comps []npmComparator
+	remain := input[offset:]
+	for remain != "" && remain[0] != '|' {
+		comp, newRemain, err := parseNPMComparator(input, len(input)-len(remain))
+		if err != nil {
+			return nil, nil, remain, err
+		}
+		remain = trimLeftSpace(newRemain)
+
+		if isNPMHyphen(remain) {
+			// Looks like a hyphen range, which must then be the only
+			// selection in its selection set.
+			opStart := len(input) - len(remain)
+			if len(comps) != 0 {
+				return nil, nil, remain, newParseError(input, comps[0].start, opStart-comps[0].start+1, ErrInvalidSequence, `range specified with "-" operator must not be combined with other version selections`)
+			}
+			remain = trimLeftSpace(remain[1:])
+			if remain == "" || remain[0] == '|' {
+				return nil, nil, remain, newParseError(input, opStart, 1, ErrIncompleteRange, `operator "-" must be followed by another version selection to specify the upper limit of the range`)
+			}
+
+			var upper npmComparator
+			upper, newRemain, err = parseNPMComparator(input, len(input)-len(remain))
+			if err != nil {
+				return nil, nil, remain, err
+			}
+			remain = trimLeftSpace(newRemain)
+
+			if comp.op != "" {
+				return nil, nil, remain, newParseError(input, comp.start, comp.end-comp.start, ErrRangeBoundOperator, `lower bound of range specified with "-" operator must be an exact version`)
+			}
+			if upper.op != "" {
+				return nil, nil, remain, newParseError(input, upper.start, upper.end-upper.start, ErrRangeBoundOperator, `upper bound of range specified with "-" operator must be an exact version`)
+			}
+			if remain != "" && remain[0] != '|' {
+				return nil, nil, remain, newParseError(input, comp.start, len(input)-len(remain)-comp.start, ErrInvalidSequence, `range specified with "-" operator must not be combined with other version selections`)
+			}
+
+			sels := npmHyphenRange(comp.version, upper.version)
+			source := sourceFor(input, comp.start, upper.end)
+			sources := make([]Source, len(sels))
+			for i := range sels {
+				sources[i] = source
+			}
+			ispec, sources := simplifyNPMSet(sels, sources)
+			return ispec, sources, remain, nil
+		}
+
+		comps = append(comps, comp)
+	}
+
+	var sels []SelectionSpec
+	var sources []Source
+	for _, comp := range comps {
+		source := sourceFor(input, comp.start, comp.end)
+		for _, sel := range comp.selections() {
+			sels = append(sels, sel)
+			sources = append(sources, source)
+		}
+	}
+	ispec, sources := simplifyNPMSet(sels, sources)
+	return ispec, sources, remain, nil
+}
+
+// isNPMHyphen returns true if the given string starts with a hyphen range
+// operator, which must be followed by whitespace or the end of the string to
+// distinguish it from a stray character.
+func isNPMHyphen(s string) bool {
+	if !strings.HasPrefix(s, "-") {
+		return false
+	}
+	rest := s[1:]
+	return rest == "" || unicode.IsSpace(rune(rest[0]))
+}
+
+// parseNPMComparator parses one npm comparator from the given string,
+// starting at the given offset, returning the result along with the remaining
+// unconsumed string.
+func parseNPMComparator(input string, offset int) (npmComparator, string, error) {
+	str := input[offset:]
+	raw, remain := scanConstraint(str)
+	pos := raw.offsets(len(str) - len(remain))
+	comp := npmComparator{
+		start: offset,
+		end:   offset + len(str) - len(remain),
+	}
+
+	if len(str) == len(remain) || raw.numCt == 0 {
+		end := strings.IndexFunc(str, unicode.IsSpace)
+		if end == -1 {
+			end = len(str)
+		}
+		return comp, remain, newParseError(input, offset, end, ErrInvalidSequence, "the sequence %q is not valid", str[:end])
+	}
+
+	switch raw.op {
+	case "", "=":
+		// An explicit "=" is the same as no operator at all.
+		comp.op = ""
+	case "<", "<=", ">", ">=", "~", "^":
+		comp.op = raw.op
+	case "~>":
+		comp.op = "~"
+	case "=<":
+		return comp, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \"<=\"?", raw.op)
+	case "=>":
+		return comp, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \">=\"?", raw.op)
+	default:
+		return comp, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q", raw.op)
+	}
+
+	if raw.numCt > 3 {
+		return comp, remain, newParseError(input, offset+pos.nums[3]-1, pos.numsEnd-pos.nums[3]+1, ErrTooManySegments, "too many numbered portions; only three are allowed (major, minor, patch)")
+	}
+
+	for i, s := range raw.nums[:raw.numCt] {
+		if isWildcardNum(s) {
+			continue
+		}
+		if len(s) > 1 && s[0] == '0' {
+			return comp, remain, newParseError(input, offset+pos.nums[i], len(s), ErrLeadingZero, "%s number must not have leading zeros", rawNumNames[i])
+		}
+		if n, err := strconv.ParseUint(s, 10, 64); err != nil || n > npmMaxNum {
+			return comp, remain, newParseError(input, offset+pos.nums[i], len(s), ErrNumberTooLarge, "%s number is too large", rawNumNames[i])
+		}
+	}
+
+	// npm allows prerelease and build metadata segments only after three
+	// numbered segments, although the patch segment may be a wildcard.
+	if raw.numCt < 3 {
+		if raw.pre != "" {
+			return comp, remain, newParseError(input, offset+pos.pre, len(raw.pre)+1, ErrPrereleaseWithWildcard, `can't use prerelease segment (introduced by "-") in a version with wildcards`)
+		}
+		if raw.meta != "" {
+			return comp, remain, newParseError(input, offset+pos.meta, len(raw.meta)+1, ErrMetadataWithWildcard, `can't use build metadata segment (introduced by "+") in a version with wildcards`)
+		}
+	}
+
+	if remain != "" && remain[0] != '|' && !unicode.IsSpace(rune(remain[0])) {
+		remainOffset := offset + len(str) - len(remain)
+		if remain[0] == ',' {
+			return comp, remain, newParseError(input, remainOffset, 1, ErrComma, `commas are not needed to separate version selections; separate with spaces instead`)
+		}
+		end := strings.IndexFunc(remain, unicode.IsSpace)
+		if end == -1 {
+			end = len(remain)
+		}
+		return comp, remain, newParseError(input, remainOffset, end, ErrInvalidCharacters, "invalid characters %q", remain[:end])
+	}
+
+	// npm treats all segments after the first wildcard as wildcards too,
+	// so "1.x.3" is the same as "1.x".
+	seenWild := false
+	for i := range raw.nums {
+		if i >= raw.numCt || isWildcardNum(raw.nums[i]) {
+			seenWild = true
+		}
+		if seenWild {
+			raw.nums[i] = "*"
+		}
+	}
+	raw.meta = "" // npm ignores build metadata entirely
+	comp.version = raw.VersionSpec()
+
+	return comp, remain, nil
+}
+
+// npmMaxNum is the largest version number segment that npm allows, which is
+// the largest integer that JavaScript can represent exactly.
+const npmMaxNum = 1<<53 - 1
+
+// selections expands the receiver into the equivalent simple selections,
+// using the same rules as node-semver. The result is empty if the comparator
+// matches all versions.
+func (c npmComparator) selections() []SelectionSpec {
+	v := c.version
+	xM, xm, xp := v.Major.Unconstrained, v.Minor.Unconstrained, v.Patch.Unconstrained
+	M, m, p := v.Major.Num, v.Minor.Num, v.Patch.Num
+
+	switch c.op {
+	case "~":
+		switch {
+		case xM:
+			return nil
+		case xm:
+			return npmBounds(npmVersion(M, 0, 0, ""), npmVersion(M+1, 0, 0, "0"))
+		case xp:
+			return npmBounds(npmVersion(M, m, 0, ""), npmVersion(M, m+1, 0, "0"))
+		default:
+			return npmBounds(v, npmVersion(M, m+1, 0, "0"))
+		}
+	case "^":
+		switch {
+		case xM:
+			return nil
+		case xm:
+			return npmBounds(npmVersion(M, 0, 0, ""), npmVersion(M+1, 0, 0, "0"))
+		case xp && M == 0:
+			return npmBounds(npmVersion(M, m, 0, ""), npmVersion(M, m+1, 0, "0"))
+		case xp:
+			return npmBounds(npmVersion(M, m, 0, ""), npmVersion(M+1, 0, 0, "0"))
+		case M == 0 && m == 0:
+			return npmBounds(v, npmVersion(M, m, p+1, "0"))
+		case M == 0:
+			return npmBounds(v, npmVersion(M, m+1, 0, "0"))
+		default:
+			return npmBounds(v, npmVersion(M+1, 0, 0, "0"))
+		}
+	}
+
+	op := c.op
+	switch {
+	case xM:
+		if op == ">" || op == "<" {
+			return []SelectionSpec{npmNullSet}
+		}
+		return nil
+	case op != "" && (xm || xp):
+		if xm {
+			m = 0
+		}
+		p = 0
+		pre := ""
+		switch op {
+		case ">":
+			op = ">="
+			if xm {
+				M++
+				m = 0
+			} else {
+				m++
+			}
+		case "<=":
+			op = "<"
+			if xm {
+				M++
+			} else {
+				m++
+			}
+		}
+		if op == "<" {
+			pre = "0"
+		}
+		return []SelectionSpec{{Operator: npmOps[op], Boundary: npmVersion(M, m, p, pre)}}
+	case xm:
+		return npmBounds(npmVersion(M, 0, 0, ""), npmVersion(M+1, 0, 0, "0"))
+	case xp:
+		return npmBounds(npmVersion(M, m, 0, ""), npmVersion(M, m+1, 0, "0"))
+	default:
+		return []SelectionSpec{{Operator: npmOps[op], Boundary: v}}
+	}
+}
+
+// npmHyphenRange expands a hyphen range with the given bounds into the
+// equivalent simple selections.
+func npmHyphenRange(from, to VersionSpec) []SelectionSpec {
+	var ret []SelectionSpec
+	switch {
+	case from.Major.Unconstrained:
+		// no lower bound
+	case from.Minor.Unconstrained:
+		ret = append(ret, SelectionSpec{Operator: OpGreaterThanOrEqual, Boundary: npmVersion(from.Major.Num, 0, 0, "")})
+	case from.Patch.Unconstrained:
+		ret = append(ret, SelectionSpec{Operator: OpGreaterThanOrEqual, Boundary: npmVersion(from.Major.Num, from.Minor.Num, 0, "")})
+	default:
+		ret = append(ret, SelectionSpec{Operator: OpGreaterThanOrEqual, Boundary: from})
+	}
+	switch {
+	case to.Major.Unconstrained:
+		// no upper bound
+	case to.Minor.Unconstrained:
+		ret = append(ret, SelectionSpec{Operator: OpLessThan, Boundary: npmVersion(to.Major.Num+1, 0, 0, "0")})
+	case to.Patch.Unconstrained:
+		ret = append(ret, SelectionSpec{Operator: OpLessThan, Boundary: npmVersion(to.Major.Num, to.Minor.Num+1, 0, "0")})
+	default:
+		ret = append(ret, SelectionSpec{Operator: OpLessThanOrEqual, Boundary: to})
+	}
+	return ret
+}
+
+// simplifyNPMSet applies the same simplifications to a selection set that
+// node-semver applies: selections of ">=0.0.0" match all versions and so are
+// removed, a selection set containing a selection that matches nothing is
+// reduced to just that selection, and duplicate selections are removed.
+//
+// The given sources correspond to the given selections, and the sources of
+// the selections that are retained are returned alongside them.
+func simplifyNPMSet(sels []SelectionSpec, sources []Source) (IntersectionSpec, []Source) {
+	ret := make(IntersectionSpec, 0, len(sels))
+	retSources := make([]Source, 0, len(sels))
+	seen := make(map[SelectionSpec]struct{}, len(sels))
+	for i, sel := range sels {
+		switch sel {
+		case npmNullSet:
+			return IntersectionSpec{sel}, sources[i : i+1]
+		case npmAny:
+			continue
+		}
+		if _, exists := seen[sel]; exists {
+			continue
+		}
+		seen[sel] = struct{}{}
+		ret = append(ret, sel)
+		retSources = append(retSources, sources[i])
+	}
+	return ret, retSources
+}
+
+// simplifyNPMUnion applies the same simplifications to a set of alternatives
+// that node-semver applies: alternatives that match nothing are removed
+// unless all of them match nothing, and if any alternative matches all
+// versions then it is the only one retained.
+//
+// As with simplifyNPMSet, the sources of the retained selections are
+// returned alongside them.
+func simplifyNPMUnion(uspec UnionSpec, sources Sources) (UnionSpec, Sources) {
+	if len(uspec) < 2 {
+		return uspec, sources
+	}
+	ret := make(UnionSpec, 0, len(uspec))
+	retSources := make(Sources, 0, len(uspec))
+	for i, ispec := range uspec {
+		if len(ispec) == 1 && ispec[0] == npmNullSet {
+			continue
+		}
+		ret = append(ret, ispec)
+		retSources = append(retSources, sources[i])
+	}
+	if len(ret) == 0 {
+		return uspec[:1], sources[:1]
+	}
+	for i, ispec := range ret {
+		if len(ispec) == 0 {
+			return UnionSpec{ispec}, Sources{retSources[i]}
+		}
+	}
+	return ret, retSources
+}
+
+// npmNullSet is the selection that node-semver uses to represent a
+// comparison that matches nothing.
+var npmNullSet = SelectionSpec{
+	Operator: OpLessThan,
+	Boundary: npmVersion(0, 0, 0, "0"),
+}
+
+// npmAny is a selection that matches all versions, which node-semver removes
+// from selection sets.
+var npmAny = SelectionSpec{
+	Operator: OpGreaterThanOrEqual,
+	Boundary: npmVersion(0, 0, 0, ""),
+}
+
+var npmOps = map[string]SelectionOp{
+	"":   OpEqual,
+	"<":  OpLessThan,
+	"<=": OpLessThanOrEqual,
+	">":  OpGreaterThan,
+	">=": OpGreaterThanOrEqual,
+}
+
+func npmBounds(lower, upper VersionSpec) []SelectionSpec {
+	return []SelectionSpec{
+		{Operator: OpGreaterThanOrEqual, Boundary: lower},
+		{Operator: OpLessThan, Boundary: upper},
+	}
+}
+
+func npmVersion(major, minor, patch uint64, pre string) VersionSpec {
+	return VersionSpec{
+		Major:      NumConstraint{Num: major},
+		Minor:      NumConstraint{Num: minor},
+		Patch:      NumConstraint{Num: patch},
+		Prerelease: pre,
+	}
+}
@@ -0,0 +1,143 @@
+package constraints
+
+import (
+	"testing"
+)
+
+func TestParseNPM(t *testing.T) {
+	// Most of these cases are ported from the "range-parse" fixtures in
+	// node-semver, with the expected results rewritten in the syntax used by
+	// Format, which separates alternatives with " || " rather than "||".
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr ParseErrorCode
+	}{
+		{"1.0.0 - 2.0.0", ">=1.0.0 <=2.0.0", ""},
+		{"1.0.0 - 2.0.0 ", ">=1.0.0 <=2.0.0", ""},
+		{"1 - 2", ">=1.0.0 <3.0.0-0", ""},
+		{"1.0 - 2.0", ">=1.0.0 <2.1.0-0", ""},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", ">=1.2.3-pre <=2.4.3-pre", ""},
+		{"1.2.3+asdf - 2.4.3+asdf", ">=1.2.3 <=2.4.3", ""},
+		{"1.0.0", "1.0.0", ""},
+		{"=1.0.0", "1.0.0", ""},
+		{"v1.0.0", "1.0.0", ""},
+		{"=v1.0.0", "1.0.0", ""},
+		{">=*", "*", ""},
+		{"", "*", ""},
+		{"*", "*", ""},
+		{">=1.0.0", ">=1.0.0", ""},
+		{">1.0.0", ">1.0.0", ""},
+		{"<=2.0.0", "<=2.0.0", ""},
+		{"1", ">=1.0.0 <2.0.0-0", ""},
+		{"<2.0.0", "<2.0.0", ""},
+		{">= 1.0.0", ">=1.0.0", ""},
+		{">=  1.0.0", ">=1.0.0", ""},
+		{"> 1.0.0", ">1.0.0", ""},
+		{"<=   2.0.0", "<=2.0.0", ""},
+		{"<\t2.0.0", "<2.0.0", ""},
+		{">=0.1.97", ">=0.1.97", ""},
+		{"0.1.20 || 1.2.4", "0.1.20 || 1.2.4", ""},
+		{">=0.2.3 || <0.0.1", ">=0.2.3 || <0.0.1", ""},
+		{"||", "*", ""},
+		{"1.2.3 ||", "*", ""},
+		{"2.x.x", ">=2.0.0 <3.0.0-0", ""},
+		{"1.2.x", ">=1.2.0 <1.3.0-0", ""},
+		{"1.2.x || 2.x", ">=1.2.0 <1.3.0-0 || >=2.0.0 <3.0.0-0", ""},
+		{"x", "*", ""},
+		{"2.*.*", ">=2.0.0 <3.0.0-0", ""},
+		{"1.2.*", ">=1.2.0 <1.3.0-0", ""},
+		{"1.x.3", ">=1.0.0 <2.0.0-0", ""},
+		{"2", ">=2.0.0 <3.0.0-0", ""},
+		{"2.3", ">=2.3.0 <2.4.0-0", ""},
+		{"~2.4", ">=2.4.0 <2.5.0-0", ""},
+		{"~>3.2.1", ">=3.2.1 <3.3.0-0", ""},
+		{"~> 3.2.1", ">=3.2.1 <3.3.0-0", ""},
+		{"~1", ">=1.0.0 <2.0.0-0", ""},
+		{"~>1", ">=1.0.0 <2.0.0-0", ""},
+		{"~ 1.0", ">=1.0.0 <1.1.0-0", ""},
+		{"~1.0", ">=1.0.0 <1.1.0-0", ""},
+		{"~1.2.3-beta.2", ">=1.2.3-beta.2 <1.3.0-0", ""},
+		{"^0", "<1.0.0-0", ""},
+		{"^ 1", ">=1.0.0 <2.0.0-0", ""},
+		{"^0.1", ">=0.1.0 <0.2.0-0", ""},
+		{"^1.0", ">=1.0.0 <2.0.0-0", ""},
+		{"^1.2", ">=1.2.0 <2.0.0-0", ""},
+		{"^0.0.1", ">=0.0.1 <0.0.2-0", ""},
+		{"^0.0.1-beta", ">=0.0.1-beta <0.0.2-0", ""},
+		{"^0.1.2", ">=0.1.2 <0.2.0-0", ""},
+		{"^1.2.3", ">=1.2.3 <2.0.0-0", ""},
+		{"^1.2.3-beta.4", ">=1.2.3-beta.4 <2.0.0-0", ""},
+		{"^0.0.x", "<0.1.0-0", ""},
+		{"^1.2.3+build", ">=1.2.3 <2.0.0-0", ""},
+		{"<1", "<1.0.0-0", ""},
+		{"< 1", "<1.0.0-0", ""},
+		{">=1", ">=1.0.0", ""},
+		{">= 1", ">=1.0.0", ""},
+		{"<1.2", "<1.2.0-0", ""},
+		{"< 1.2", "<1.2.0-0", ""},
+		{">1", ">=2.0.0", ""},
+		{">1.2", ">=1.3.0", ""},
+		{"<=1.2", "<1.3.0-0", ""},
+		{"=0.7.x", ">=0.7.0 <0.8.0-0", ""},
+		{"<=0.7.x", "<0.8.0-0", ""},
+		{">=0.7.x", ">=0.7.0", ""},
+		{"<0.7.x", "<0.7.0-0", ""},
+		{"~1.2.1 >=1.2.3", ">=1.2.1 <1.3.0-0 >=1.2.3", ""},
+		{"~1.2.1 =1.2.3", ">=1.2.1 <1.3.0-0 1.2.3", ""},
+		{"~1.2.1 1.2.3 >=1.2.3", ">=1.2.1 <1.3.0-0 1.2.3 >=1.2.3", ""},
+		{">=1.2.1 >=1.2.1", ">=1.2.1", ""},
+		{">X", "<0.0.0-0", ""},
+		{"<X", "<0.0.0-0", ""},
+		{"<x <* || >* 2.x", "<0.0.0-0", ""},
+		{">x 2.x || * || <x", "*", ""},
+		{"x - 1.0.0", "<=1.0.0", ""},
+		{"x - 1.x", "<2.0.0-0", ""},
+		{"1.0.0 - x", ">=1.0.0", ""},
+		{"1.x - x", ">=1.0.0", ""},
+
+		{"1.0.0 -", "", ErrIncompleteRange},
+		{"1.0.0 - 2.0.0 >3.0.0", "", ErrInvalidSequence},
+		{">3.0.0 1.0.0 - 2.0.0", "", ErrInvalidSequence},
+		{"^1.0.0 - 2.0.0", "", ErrRangeBoundOperator},
+		{"1.0.0 - ~2.0.0", "", ErrRangeBoundOperator},
+		{">=1.0.0 | <2.0.0", "", ErrSinglePipe},
+		{">=1.0.0, <2.0.0", "", ErrComma},
+		{"=>1.0.0", "", ErrInvalidOperator},
+		{"<>1.0.0", "", ErrInvalidOperator},
+		{"1.0.0.0", "", ErrTooManySegments},
+		{"01.0.0", "", ErrLeadingZero},
+		{"1.0.9007199254740992", "", ErrNumberTooLarge},
+		{"1.2-beta", "", ErrPrereleaseWithWildcard},
+		{"1.2+abc", "", ErrMetadataWithWildcard},
+		{"1.2.3foo", "", ErrInvalidCharacters},
+		{"blerg", "", ErrInvalidSequence},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, err := ParseNPM(test.Input)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot:  %s\nwant error: %s", spec, test.WantErr)
+				}
+				if got, want := err.(*ParseError).Code, test.WantErr; got != want {
+					t.Fatalf("wrong error code\ngot:  %s (%s)\nwant: %s", got, err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got, err := Format(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package constraints
 
 import (
 	"strconv"
+	"strings"
 )
 
 //go:generate ragel -G1 -Z raw_scan.rl
@@ -72,3 +73,41 @@ func parseRawNumConstraint(s string) NumConstraint {
 		}
 	}
 }
+
+// invalidPrereleaseIdent finds the first identifier in the given prerelease
+// string that is not valid per the semver specification, because it is either
+// empty or numeric with a leading zero. If there is such an identifier then
+// the result is its offset and length within the string and true.
+//
+// This assumes that the string contains only the characters that
+// scanConstraint accepts in prerelease strings.
+func invalidPrereleaseIdent(pre string) (int, int, bool) {
+	offset := 0
+	for _, ident := range strings.Split(pre, ".") {
+		switch {
+		case ident == "":
+			return offset, 0, true
+		case len(ident) > 1 && ident[0] == '0' && strings.Trim(ident, "0123456789") == "":
+			return offset, len(ident), true
+		}
+		offset += len(ident) + 1
+	}
+	return 0, 0, false
+}
+
+// emptyMetadataIdent returns the offset of the first empty identifier in the
+// given metadata string, or -1 if there is none. Unlike in prerelease strings,
+// numeric identifiers in metadata may have leading zeros.
+func emptyMetadataIdent(meta string) int {
+	if meta == "" {
+		return -1
+	}
+	offset := 0
+	for _, ident := range strings.Split(meta, ".") {
+		if ident == "" {
+			return offset
+		}
+		offset += len(ident) + 1
+	}
+	return -1
+}
@@ -1,7 +1,7 @@
 package constraints
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -16,30 +16,35 @@ import (
 // rubygems approach of providing each selection as a separate string.
 // The result can be combined with other results to create an IntersectionSpec
 // that describes the effect of multiple such constraints.
+//
+// Any error returned is of type *ParseError, describing the position of the
+// problem within the given string.
 func ParseRubyStyle(str string) (SelectionSpec, error) {
 	if strings.TrimSpace(str) == "" {
-		return SelectionSpec{}, fmt.Errorf("empty specification")
+		return SelectionSpec{}, newParseError(str, 0, len(str), ErrEmpty, "empty specification")
 	}
-	spec, remain, err := parseRubyStyle(str)
+	spec, remain, err := parseRubyStyle(str, 0)
 	if err != nil {
 		return spec, err
 	}
 	if remain != "" {
-		remain = strings.TrimSpace(remain)
+		remainOffset := len(str) - len(remain)
+		trimmed := strings.TrimSpace(remain)
+		trimmedOffset := remainOffset + strings.Index(remain, trimmed)
 		switch {
-		case remain == "":
-			return spec, fmt.Errorf("extraneous spaces at end of specification")
-		case strings.HasPrefix(remain, "v"):
+		case trimmed == "":
+			return spec, newParseError(str, remainOffset, len(remain), ErrExtraneousSpace, "extraneous spaces at end of specification")
+		case strings.HasPrefix(trimmed, "v"):
 			// User seems to be trying to use a "v" prefix, like "v1.0.0"
-			return spec, fmt.Errorf(`a "v" prefix should not be used`)
-		case strings.HasPrefix(remain, "||") || strings.HasPrefix(remain, ","):
+			return spec, newParseError(str, trimmedOffset, 1, ErrVPrefix, `a "v" prefix should not be used`)
+		case strings.HasPrefix(trimmed, "||") || strings.HasPrefix(trimmed, ","):
 			// User seems to be trying to specify multiple constraints
-			return spec, fmt.Errorf(`only one constraint may be specified`)
-		case strings.HasPrefix(remain, "-"):
+			return spec, newParseError(str, trimmedOffset, len(trimmed), ErrMultipleNotAllowed, `only one constraint may be specified`)
+		case strings.HasPrefix(trimmed, "-"):
 			// User seems to be trying to use npm-style range constraints
-			return spec, fmt.Errorf(`range constraints are not supported`)
+			return spec, newParseError(str, trimmedOffset, len(trimmed), ErrRangeNotAllowed, `range constraints are not supported`)
 		default:
-			return spec, fmt.Errorf("invalid characters %q", remain)
+			return spec, newParseError(str, trimmedOffset, len(trimmed), ErrInvalidCharacters, "invalid characters %q", trimmed)
 		}
 	}
 
@@ -49,12 +54,15 @@ func ParseRubyStyle(str string) (SelectionSpec, error) {
 // ParseRubyStyleAll is a helper wrapper around ParseRubyStyle that accepts
 // multiple selection strings and combines them together into a single
 // IntersectionSpec.
+//
+// Any error returned is of type *ParseError, describing the position of the
+// problem within whichever of the given strings it was found in.
 func ParseRubyStyleAll(strs ...string) (IntersectionSpec, error) {
 	spec := make(IntersectionSpec, 0, len(strs))
 	for _, str := range strs {
 		subSpec, err := ParseRubyStyle(str)
 		if err != nil {
-			return nil, fmt.Errorf("invalid specification %q: %s", str, err)
+			return nil, prefixParseError(err, "invalid specification %q: ", str)
 		}
 		spec = append(spec, subSpec)
 	}
@@ -65,9 +73,24 @@ func ParseRubyStyleAll(strs ...string) (IntersectionSpec, error) {
 // only a single selection specification it instead expects one or more
 // comma-separated specifications, returning the result as an
 // IntersectionSpec.
+//
+// Any error returned is of type *ParseError, describing the position of the
+// problem within the given string.
 func ParseRubyStyleMulti(str string) (IntersectionSpec, error) {
+	spec, _, err := ParseRubyStyleMultiWithSources(str)
+	return spec, err
+}
+
+// ParseRubyStyleMultiWithSources is like ParseRubyStyleMulti except that it
+// also returns the sources of the selections in the result, so that messages
+// about them can refer back to the portions of the string they were parsed
+// from.
+func ParseRubyStyleMultiWithSources(str string) (IntersectionSpec, Sources, error) {
 	var spec IntersectionSpec
-	remain := strings.TrimSpace(str)
+	var sources []Source
+	// remain is always a suffix of str, so that we can find the offset of
+	// any problem we detect in order to report it.
+	remain := trimLeftSpace(str)
 	for remain != "" {
 		if strings.TrimSpace(remain) == "" {
 			break
@@ -76,35 +99,44 @@ func ParseRubyStyleMulti(str string) (IntersectionSpec, error) {
 		var subSpec SelectionSpec
 		var err error
 		var newRemain string
-		subSpec, newRemain, err = parseRubyStyle(remain)
+		start := len(str) - len(remain)
+		subSpec, newRemain, err = parseRubyStyle(str, start)
 		consumed := remain[:len(remain)-len(newRemain)]
 		if err != nil {
-			return nil, fmt.Errorf("invalid specification %q: %s", consumed, err)
+			return nil, nil, prefixParseError(err, "invalid specification %q: ", consumed)
 		}
-		remain = strings.TrimSpace(newRemain)
+		end := len(str) - len(newRemain)
+		remain = trimLeftSpace(newRemain)
 
 		if remain != "" {
+			offset := len(str) - len(remain)
 			if strings.HasPrefix(remain, "v") {
-				return nil, fmt.Errorf(`a "v" prefix should not be used when specifying versions`)
+				return nil, nil, newParseError(str, offset, 1, ErrVPrefix, `a "v" prefix should not be used when specifying versions`)
 			}
 			if !strings.HasPrefix(remain, ",") {
-				return nil, fmt.Errorf("missing comma after %q", consumed)
+				return nil, nil, newParseError(str, offset, 0, ErrMissingComma, "missing comma after %q", consumed)
 			}
 			// Eat the separator comma
-			remain = strings.TrimSpace(remain[1:])
+			remain = trimLeftSpace(remain[1:])
 		}
 
 		spec = append(spec, subSpec)
+		sources = append(sources, sourceFor(str, start, end))
 	}
 
-	return spec, nil
+	return spec, Sources{sources}, nil
 }
 
-// parseRubyStyle parses a ruby-style constraint from the prefix of the given
-// string and returns the remaining unconsumed string for the caller to use
-// for further processing.
-func parseRubyStyle(str string) (SelectionSpec, string, error) {
+// parseRubyStyle parses a ruby-style constraint from the given string,
+// starting at the given offset, and returns the remaining unconsumed string
+// for the caller to use for further processing.
+//
+// The full input string is required, rather than just the portion to be
+// parsed, so that any errors can describe positions within it.
+func parseRubyStyle(input string, offset int) (SelectionSpec, string, error) {
+	str := input[offset:]
 	raw, remain := scanConstraint(str)
+	pos := raw.offsets(len(str) - len(remain))
 	var spec SelectionSpec
 
 	switch raw.op {
@@ -130,11 +162,11 @@ func parseRubyStyle(str string) (SelectionSpec, string, error) {
 			spec.Operator = OpGreaterThanOrEqualMinorOnly
 		}
 	case "=<":
-		return spec, remain, fmt.Errorf("invalid constraint operator %q; did you mean \"<=\"?", raw.op)
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \"<=\"?", raw.op)
 	case "=>":
-		return spec, remain, fmt.Errorf("invalid constraint operator %q; did you mean \">=\"?", raw.op)
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q; did you mean \">=\"?", raw.op)
 	default:
-		return spec, remain, fmt.Errorf("invalid constraint operator %q", raw.op)
+		return spec, remain, newParseError(input, offset+pos.op, len(raw.op), ErrInvalidOperator, "invalid constraint operator %q", raw.op)
 	}
 
 	switch raw.sep {
@@ -144,21 +176,21 @@ func parseRubyStyle(str string) (SelectionSpec, string, error) {
 		// require it.
 	case " ":
 		if raw.op == "" {
-			return spec, remain, fmt.Errorf("extraneous spaces at start of specification")
+			return spec, remain, newParseError(input, offset+pos.sep, len(raw.sep), ErrExtraneousSpace, "extraneous spaces at start of specification")
 		}
 	default:
-		if strings.ContainsRune(raw.sep, 'v') {
-			return spec, remain, fmt.Errorf(`a "v" prefix should not be used when specifying versions`)
+		if i := strings.IndexRune(raw.sep, 'v'); i >= 0 {
+			return spec, remain, newParseError(input, offset+pos.sep+i, 1, ErrVPrefix, `a "v" prefix should not be used when specifying versions`)
 		}
 		if raw.op == "" {
-			return spec, remain, fmt.Errorf("extraneous spaces at start of specification")
+			return spec, remain, newParseError(input, offset+pos.sep, len(raw.sep), ErrExtraneousSpace, "extraneous spaces at start of specification")
 		} else {
-			return spec, remain, fmt.Errorf("only one space is expected after the operator %q", raw.op)
+			return spec, remain, newParseError(input, offset+pos.sep, len(raw.sep), ErrSpaceAfterOperator, "only one space is expected after the operator %q", raw.op)
 		}
 	}
 
 	if raw.numCt > 3 {
-		return spec, remain, fmt.Errorf("too many numbered portions; only three are allowed (major, minor, patch)")
+		return spec, remain, newParseError(input, offset+pos.nums[3]-1, pos.numsEnd-pos.nums[3]+1, ErrTooManySegments, "too many numbered portions; only three are allowed (major, minor, patch)")
 	}
 
 	// Ruby-style doesn't use explicit wildcards
@@ -166,7 +198,11 @@ func parseRubyStyle(str string) (SelectionSpec, string, error) {
 		switch {
 		case isWildcardNum(s):
 			// Can't use wildcards in an exact specification
-			return spec, remain, fmt.Errorf("can't use wildcard for %s number; omit segments that should be unconstrained", rawNumNames[i])
+			return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrWildcardNotAllowed, "can't use wildcard for %s number; omit segments that should be unconstrained", rawNumNames[i])
+		case s != "":
+			if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+				return spec, remain, newParseError(input, offset+pos.nums[i], len(s), ErrNumberTooLarge, "%s number is too large", rawNumNames[i])
+			}
 		}
 	}
 
@@ -14,6 +14,7 @@ func _() {
 	_ = x[OpGreaterThanOrEqual-8805]
 	_ = x[OpGreaterThanOrEqualPatchOnly-126]
 	_ = x[OpGreaterThanOrEqualMinorOnly-94]
+	_ = x[OpGreaterThanOrEqualPrereleaseOnly-8776]
 	_ = x[OpLessThanOrEqual-8804]
 	_ = x[OpEqual-61]
 	_ = x[OpNotEqual-8800]
@@ -26,13 +27,14 @@ const (
 	_SelectionOp_name_2 = "OpLessThanOpEqualOpGreaterThan"
 	_SelectionOp_name_3 = "OpGreaterThanOrEqualMinorOnly"
 	_SelectionOp_name_4 = "OpGreaterThanOrEqualPatchOnly"
-	_SelectionOp_name_5 = "OpNotEqual"
-	_SelectionOp_name_6 = "OpLessThanOrEqualOpGreaterThanOrEqual"
+	_SelectionOp_name_5 = "OpGreaterThanOrEqualPrereleaseOnly"
+	_SelectionOp_name_6 = "OpNotEqual"
+	_SelectionOp_name_7 = "OpLessThanOrEqualOpGreaterThanOrEqual"
 )
 
 var (
 	_SelectionOp_index_2 = [...]uint8{0, 10, 17, 30}
-	_SelectionOp_index_6 = [...]uint8{0, 17, 37}
+	_SelectionOp_index_7 = [...]uint8{0, 17, 37}
 )
 
 func (i SelectionOp) String() string {
@@ -48,11 +50,13 @@ func (i SelectionOp) String() string {
 		return _SelectionOp_name_3
 	case i == 126:
 		return _SelectionOp_name_4
-	case i == 8800:
+	case i == 8776:
 		return _SelectionOp_name_5
+	case i == 8800:
+		return _SelectionOp_name_6
 	case 8804 <= i && i <= 8805:
 		i -= 8804
-		return _SelectionOp_name_6[_SelectionOp_index_6[i]:_SelectionOp_index_6[i+1]]
+		return _SelectionOp_name_7[_SelectionOp_index_7[i]:_SelectionOp_index_7[i+1]]
 	default:
 		return "SelectionOp(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -0,0 +1,101 @@
+package constraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Source describes where a selection came from, so that messages about the
+// selection can refer back to what the user actually wrote.
+//
+// The parsers whose names end in "WithSources" return the source of each
+// selection they produce in a Sources table alongside the spec.
+type Source struct {
+	// Filename is the name of the file or other location that the
+	// constraint string was read from, such as a manifest file. The parsers
+	// don't know this and so leave it empty, but callers can set it using
+	// Sources.WithFilename.
+	Filename string `json:"filename,omitempty"`
+
+	// Text is the portion of the constraint string that the selection was
+	// parsed from, such as "~> 1.2". A single portion can produce more than
+	// one selection, as with the range "1.0.0 - 2.0.0", in which case all of
+	// those selections have the same source.
+	Text string `json:"text"`
+
+	// Offset is the byte offset of Text within the constraint string.
+	Offset int `json:"offset"`
+}
+
+// IsZero returns true if the receiver is the zero value of Source, meaning
+// that the source of a selection is unknown.
+func (s Source) IsZero() bool {
+	return s == Source{}
+}
+
+// String returns a short description of the receiver for use in messages,
+// such as "\"~> 1.2\" in Gemfile". The result is empty if the receiver is
+// the zero value.
+func (s Source) String() string {
+	switch {
+	case s.IsZero():
+		return ""
+	case s.Filename == "":
+		return fmt.Sprintf("%q", s.Text)
+	default:
+		return fmt.Sprintf("%q in %s", s.Text, s.Filename)
+	}
+}
+
+// sourceFor returns the source of a selection found between the given
+// offsets of the given constraint string, ignoring any trailing whitespace.
+func sourceFor(input string, start, end int) Source {
+	return Source{
+		Text:   strings.TrimRight(input[start:end], " \t\r\n"),
+		Offset: start,
+	}
+}
+
+// Sources records where each of the selections of a spec came from. It is
+// kept separately from the spec, rather than in the selections themselves,
+// so that selections with the same meaning are equal however they were
+// written.
+//
+// The sources are arranged in the same way as the selections of a UnionSpec,
+// so that the source of spec[i][j] is sources[i][j]. The sources for an
+// IntersectionSpec are the single element of the table, as if the spec were
+// the only alternative of a UnionSpec, and likewise the source for a single
+// SelectionSpec is the single element of that element.
+//
+// Specs that are combined can have their sources combined in the same way.
+// For example, appending the alternatives of one UnionSpec to those of
+// another corresponds to appending the elements of their sources.
+type Sources [][]Source
+
+// Get returns the source of the selection at the given position, as
+// described for Sources, or the zero value of Source if the receiver doesn't
+// have a source for that position.
+func (s Sources) Get(alt, sel int) Source {
+	if alt >= len(s) || sel >= len(s[alt]) {
+		return Source{}
+	}
+	return s[alt][sel]
+}
+
+// WithFilename returns a copy of the receiver in which all of the sources
+// have the given filename, which is useful when combining specs that were
+// read from different files.
+func (s Sources) WithFilename(filename string) Sources {
+	if s == nil {
+		return nil
+	}
+	ret := make(Sources, len(s))
+	for i, alt := range s {
+		ret[i] = make([]Source, len(alt))
+		for j, src := range alt {
+			src.Filename = filename
+			ret[i][j] = src
+		}
+	}
+	return ret
+}
@@ -0,0 +1,153 @@
+package constraints
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseWithSources(t *testing.T) {
+	tests := []struct {
+		Parse func(string) (UnionSpec, Sources, error)
+		Input string
+		Want  Sources
+	}{
+		{
+			ParseWithSources,
+			">=1.0.0 <2",
+			Sources{{
+				{Text: ">=1.0.0", Offset: 0},
+				{Text: "<2", Offset: 8},
+			}},
+		},
+		{
+			ParseWithSources,
+			"^1.2 || 2.0.0-beta1",
+			Sources{
+				{{Text: "^1.2", Offset: 0}},
+				{{Text: "2.0.0-beta1", Offset: 8}},
+			},
+		},
+		{
+			ParseWithSources,
+			"1.0.0 - 2.0.0",
+			Sources{{
+				{Text: "1.0.0 - 2.0.0", Offset: 0},
+				{Text: "1.0.0 - 2.0.0", Offset: 0},
+			}},
+		},
+		{
+			parseIntersectionWithSources(ParseRubyStyleMultiWithSources),
+			"~> 1.2, != 1.2.5",
+			Sources{{
+				{Text: "~> 1.2", Offset: 0},
+				{Text: "!= 1.2.5", Offset: 8},
+			}},
+		},
+		{
+			ParseNPMWithSources,
+			"1.0.0 - 2.0.0 || >= 3",
+			Sources{
+				{
+					{Text: "1.0.0 - 2.0.0", Offset: 0},
+					{Text: "1.0.0 - 2.0.0", Offset: 0},
+				},
+				{{Text: ">= 3", Offset: 17}},
+			},
+		},
+		{
+			// Simplification removes the duplicate selection and the one
+			// that matches all versions, along with their sources.
+			ParseNPMWithSources,
+			">=0.0.0 >=1.0.0 >=1.0.0 <2",
+			Sources{{
+				{Text: ">=1.0.0", Offset: 8},
+				{Text: "<2", Offset: 24},
+			}},
+		},
+		{
+			parseIntersectionWithSources(ParseCargoWithSources),
+			">= 1.2, < 1.5",
+			Sources{{
+				{Text: ">= 1.2", Offset: 0},
+				{Text: "< 1.5", Offset: 8},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, got, err := test.Parse(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, problem := range deep.Equal(got, test.Want) {
+				t.Error(problem)
+			}
+			for i := range spec {
+				if len(got[i]) != len(spec[i]) {
+					t.Errorf("alternative %d has %d selections but %d sources", i, len(spec[i]), len(got[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithSourcesEqual(t *testing.T) {
+	// The sources are not part of the spec, so specs with the same meaning
+	// are equal even if they were written differently.
+	a, aSources, err := ParseWithSources(">=1.0.0 <2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, bSources, err := ParseWithSources(">=1.0.0  <2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("specs are not equal\na: %#v\nb: %#v", a, b)
+	}
+	if a[0][1] != b[0][1] {
+		t.Errorf("selections are not equal\na: %#v\nb: %#v", a[0][1], b[0][1])
+	}
+	if aSources.Get(0, 1) == bSources.Get(0, 1) {
+		t.Errorf("sources are equal, but should have different offsets")
+	}
+}
+
+func TestSourcesWithFilename(t *testing.T) {
+	_, sources, err := ParseWithSources("^1.2 || 2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sources.WithFilename("manifest.json")
+
+	if got, want := got.Get(1, 0).String(), `"2.0.0" in manifest.json`; got != want {
+		t.Errorf("wrong source\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := sources.Get(1, 0).String(), `"2.0.0"`; got != want {
+		t.Errorf("original sources were modified\ngot:  %s\nwant: %s", got, want)
+	}
+	if got := got.Get(2, 0); !got.IsZero() {
+		t.Errorf("unexpected source for missing alternative: %#v", got)
+	}
+}
+
+// parseIntersection adapts a parser that returns an IntersectionSpec to
+// return a UnionSpec with a single alternative instead.
+func parseIntersection(parse func(string) (IntersectionSpec, error)) func(string) (UnionSpec, error) {
+	return func(str string) (UnionSpec, error) {
+		spec, err := parse(str)
+		return UnionSpec{spec}, err
+	}
+}
+
+// parseIntersectionWithSources is like parseIntersection but for parsers
+// that also return the sources of the selections.
+func parseIntersectionWithSources(parse func(string) (IntersectionSpec, Sources, error)) func(string) (UnionSpec, Sources, error) {
+	return func(str string) (UnionSpec, Sources, error) {
+		spec, sources, err := parse(str)
+		return UnionSpec{spec}, sources, err
+	}
+}
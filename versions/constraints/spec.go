@@ -41,11 +41,11 @@ func (s SelectionSpec) isSpec() {}
 
 // VersionSpec represents the boundary within a SelectionSpec.
 type VersionSpec struct {
-	Major      NumConstraint
-	Minor      NumConstraint
-	Patch      NumConstraint
-	Prerelease string
-	Metadata   string
+	Major      NumConstraint `json:"major"`
+	Minor      NumConstraint `json:"minor"`
+	Patch      NumConstraint `json:"patch"`
+	Prerelease string        `json:"prerelease,omitempty"`
+	Metadata   string        `json:"metadata,omitempty"`
 }
 
 func (s VersionSpec) isSpec() {}
@@ -218,15 +218,23 @@ const (
 	OpGreaterThanOrEqual          SelectionOp = '≥'
 	OpGreaterThanOrEqualPatchOnly SelectionOp = '~'
 	OpGreaterThanOrEqualMinorOnly SelectionOp = '^'
-	OpLessThanOrEqual             SelectionOp = '≤'
-	OpEqual                       SelectionOp = '='
-	OpNotEqual                    SelectionOp = '≠'
-	OpMatch                       SelectionOp = '*'
+
+	// OpGreaterThanOrEqualPrereleaseOnly selects versions that are greater
+	// than or equal to the boundary but lower than the next patch version,
+	// so that only the pre-release portion may vary. It is produced by
+	// ParseCargo for "^0.0.x" selections, and has no equivalent in the
+	// canonical syntax.
+	OpGreaterThanOrEqualPrereleaseOnly SelectionOp = '≈'
+
+	OpLessThanOrEqual SelectionOp = '≤'
+	OpEqual           SelectionOp = '='
+	OpNotEqual        SelectionOp = '≠'
+	OpMatch           SelectionOp = '*'
 )
 
 type NumConstraint struct {
-	Num           uint64
-	Unconstrained bool
+	Num           uint64 `json:"num"`
+	Unconstrained bool   `json:"unconstrained,omitempty"`
 }
 
 func (c NumConstraint) String() string {
@@ -0,0 +1,97 @@
+package constraints
+
+import (
+	"fmt"
+)
+
+// SpecError is the type of the errors returned by Validate, describing a
+// problem with a particular selection in a spec.
+//
+// The result of Error is a message suitable for display to English-speaking
+// developers, describing the selection and its problem.
+type SpecError struct {
+	// Selection is the selection that has the problem. If the problem is
+	// with a VersionSpec given directly to Validate, this is a selection
+	// with the operator OpMatch and that version as its boundary.
+	Selection SelectionSpec
+
+	// Message is a description of the problem.
+	Message string
+}
+
+func (e *SpecError) Error() string {
+	// We don't use the String method of the selection here, because it
+	// can't write invalid selections in the constraint syntax.
+	return fmt.Sprintf("invalid selection %s %s: %s", e.Selection.Operator, e.Selection.Boundary, e.Message)
+}
+
+// Validate returns an error if the given spec does not meet the invariants
+// that the parsers in this package guarantee, and that functions such as
+// versions.MeetingConstraints rely on. The result is always nil for a spec
+// produced by one of the parsers.
+//
+// This is intended for checking specs that were constructed directly rather
+// than parsed, such as from structured configuration. The problems it
+// detects are:
+//
+//   - An operator that isn't one of the SelectionOp constants.
+//   - A wildcard version number followed by a number that isn't a wildcard,
+//     such as the minor version in 1.*.2.
+//   - A prerelease or metadata string given with a wildcard version number.
+//   - A selection with the operator OpMatch whose boundary has no wildcards,
+//     which should instead use OpEqual.
+//
+// If the spec has more than one problem then only the first is reported, as
+// a *SpecError.
+func Validate(spec Spec) error {
+	switch ts := spec.(type) {
+	case nil:
+		return nil
+	case UnionSpec:
+		for _, ispec := range ts {
+			if err := Validate(ispec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case IntersectionSpec:
+		for _, sel := range ts {
+			if err := Validate(sel); err != nil {
+				return err
+			}
+		}
+		return nil
+	case SelectionSpec:
+		if msg := ts.problem(); msg != "" {
+			return &SpecError{Selection: ts, Message: msg}
+		}
+		return nil
+	case VersionSpec:
+		if err := ts.checkConsistent(); err != nil {
+			return &SpecError{
+				Selection: SelectionSpec{Operator: OpMatch, Boundary: ts},
+				Message:   err.Error(),
+			}
+		}
+		return nil
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+// problem returns a description of the first problem with the receiver that
+// Validate detects, or an empty string if there are none.
+func (s SelectionSpec) problem() string {
+	if _, ok := selectionOpNames[s.Operator]; !ok {
+		return "unsupported operator"
+	}
+	if err := s.Boundary.checkConsistent(); err != nil {
+		return err.Error()
+	}
+	if s.Operator == OpMatch && s.Boundary.IsExact() {
+		return "the match operator requires a boundary with at least one wildcard; use OpEqual to select an exact version"
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package constraints
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	v123 := VersionSpec{
+		Major: NumConstraint{Num: 1},
+		Minor: NumConstraint{Num: 2},
+		Patch: NumConstraint{Num: 3},
+	}
+	v1x := VersionSpec{
+		Major: NumConstraint{Num: 1},
+		Minor: NumConstraint{Unconstrained: true},
+		Patch: NumConstraint{Unconstrained: true},
+	}
+
+	tests := []struct {
+		Spec Spec
+		Want string
+	}{
+		{nil, ""},
+		{UnionSpec(nil), ""},
+		{v123, ""},
+		{v1x, ""},
+		{SelectionSpec{Operator: OpEqual, Boundary: v123}, ""},
+		{SelectionSpec{Operator: OpMatch, Boundary: v1x}, ""},
+		{SelectionSpec{Operator: OpGreaterThanOrEqualMinorOnly, Boundary: v1x}, ""},
+		{SelectionSpec{}, ""},
+		{
+			SelectionSpec{Operator: SelectionOp('!'), Boundary: v123},
+			"invalid selection SelectionOp(33) 1.2.3: unsupported operator",
+		},
+		{
+			SelectionSpec{Operator: OpMatch, Boundary: v123},
+			"invalid selection OpMatch 1.2.3: the match operator requires a boundary with at least one wildcard; use OpEqual to select an exact version",
+		},
+		{
+			UnionSpec{
+				{{Operator: OpGreaterThan, Boundary: v123}},
+				{{
+					Operator: OpGreaterThanOrEqual,
+					Boundary: VersionSpec{
+						Major: NumConstraint{Num: 1},
+						Minor: NumConstraint{Unconstrained: true},
+						Patch: NumConstraint{Num: 2},
+					},
+				}},
+			},
+			"invalid selection OpGreaterThanOrEqual 1.*.2: patch version is constrained but minor version is a wildcard",
+		},
+		{
+			IntersectionSpec{
+				{
+					Operator: OpLessThan,
+					Boundary: VersionSpec{
+						Major:      NumConstraint{Num: 2},
+						Minor:      NumConstraint{Unconstrained: true},
+						Patch:      NumConstraint{Unconstrained: true},
+						Prerelease: "beta",
+					},
+				},
+			},
+			`invalid selection OpLessThan 2.*.*-beta: prerelease "beta" is given but patch version is a wildcard`,
+		},
+		{
+			VersionSpec{
+				Major: NumConstraint{Unconstrained: true},
+				Minor: NumConstraint{Num: 2},
+			},
+			"invalid selection OpMatch *.2.0: minor version is constrained but major version is a wildcard",
+		},
+	}
+
+	for i, test := range tests {
+		// We can't use Format to name the tests, because it may panic for
+		// invalid specs.
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := Validate(test.Spec)
+			var got string
+			if err != nil {
+				got = err.Error()
+				if _, ok := err.(*SpecError); !ok {
+					t.Errorf("wrong error type %T", err)
+				}
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestValidateParsed(t *testing.T) {
+	// The parsers must always produce valid specs.
+	tests := []struct {
+		Parse func(string) (UnionSpec, error)
+		Input string
+	}{
+		{Parse, "1.2.3"},
+		{Parse, "1.*"},
+		{Parse, "*"},
+		{Parse, ">1.2 <=3 || ~1.2.3 || ^0.1"},
+		{Parse, "1.0.0 - 2.*"},
+		{parseIntersection(ParseRubyStyleMulti), "~> 1.2, != 1.2.5, >= 1.0.0-beta"},
+		{ParseNPM, "1.x || >=2.0.0-rc.1 <2.1 || ~1.2 || ^0.0.1 || 1 - 2"},
+		{parseIntersection(ParseCargo), "^0.0.1, ~1, 1.*, =1.2, >1.2, <=1.2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			spec, err := test.Parse(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(spec); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
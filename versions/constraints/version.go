@@ -1,7 +1,7 @@
 package constraints
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -12,58 +12,86 @@ import (
 // constraint grammar, and isn't very useful for direct use from calling
 // applications.
 func ParseExactVersion(vs string) (VersionSpec, error) {
+	return parseExactVersion(vs, false)
+}
+
+// ParseExactVersionStrict is like ParseExactVersion except that it accepts
+// only strings that conform exactly to the grammar in the Semantic Versioning
+// 2.0.0 specification, for situations where a version must be acceptable to
+// other implementations of semver too.
+//
+// As well as the forms that ParseExactVersion rejects, this rejects:
+//
+//   - Versions with fewer than three version numbers, such as "1.2".
+//   - Version numbers with leading zeros, such as "1.02.3".
+//   - Empty prerelease or metadata identifiers, as in "1.2.3-" or "1.2.3+a..b".
+//   - Numeric prerelease identifiers with leading zeros, as in "1.2.3-rc.01".
+//
+// Numeric metadata identifiers may have leading zeros, as the specification
+// allows.
+func ParseExactVersionStrict(vs string) (VersionSpec, error) {
+	return parseExactVersion(vs, true)
+}
+
+func parseExactVersion(vs string, strict bool) (VersionSpec, error) {
 	spec := VersionSpec{}
 
 	if strings.TrimSpace(vs) == "" {
-		return spec, fmt.Errorf("empty specification")
+		return spec, newParseError(vs, 0, len(vs), ErrEmpty, "empty specification")
 	}
 
 	raw, remain := scanConstraint(vs)
+	pos := raw.offsets(len(vs) - len(remain))
 
 	switch strings.TrimSpace(raw.op) {
 	case ">", ">=", "<", "<=", "!", "!=", "~>", "^", "~":
 		// If it looks like the user was trying to write a constraint string
 		// then we'll help them out with a more specialized error.
-		return spec, fmt.Errorf("can't use constraint operator %q; an exact version is required", raw.op)
+		return spec, newParseError(vs, pos.op, len(raw.op), ErrOperatorNotAllowed, "can't use constraint operator %q; an exact version is required", raw.op)
 	case "":
 		// Empty operator is okay as long as we don't also have separator spaces.
 		// (Caller can trim off spaces beforehand if they want to tolerate this.)
 		if raw.sep != "" {
 			if strings.ContainsRune(raw.sep, 'v') {
-				return spec, fmt.Errorf(`a "v" prefix should not be used`)
+				return spec, newParseError(vs, pos.sep+strings.IndexRune(raw.sep, 'v'), 1, ErrVPrefix, `a "v" prefix should not be used`)
 			}
-			return spec, fmt.Errorf("extraneous spaces at start of specification")
+			return spec, newParseError(vs, pos.sep, len(raw.sep), ErrExtraneousSpace, "extraneous spaces at start of specification")
 		}
 	default:
-		return spec, fmt.Errorf("invalid sequence %q at start of specification", raw.op)
+		return spec, newParseError(vs, pos.op, len(raw.op), ErrInvalidOperator, "invalid sequence %q at start of specification", raw.op)
 	}
 
 	if remain != "" {
-		remain = strings.TrimSpace(remain)
+		remainOffset := len(vs) - len(remain)
+		trimmed := strings.TrimSpace(remain)
+		trimmedOffset := remainOffset + strings.Index(remain, trimmed)
 		switch {
-		case remain == "":
-			return spec, fmt.Errorf("extraneous spaces at end of specification")
+		case trimmed == "":
+			return spec, newParseError(vs, remainOffset, len(remain), ErrExtraneousSpace, "extraneous spaces at end of specification")
 		case strings.HasPrefix(vs, "v"):
 			// User seems to be trying to use a "v" prefix, like "v1.0.0"
-			return spec, fmt.Errorf(`a "v" prefix should not be used`)
-		case strings.HasPrefix(remain, ",") || strings.HasPrefix(remain, "|"):
+			return spec, newParseError(vs, 0, 1, ErrVPrefix, `a "v" prefix should not be used`)
+		case strings.HasPrefix(trimmed, ",") || strings.HasPrefix(trimmed, "|"):
 			// User seems to be trying to list/combine multiple versions
-			return spec, fmt.Errorf("can't specify multiple versions; a single exact version is required")
-		case strings.HasPrefix(remain, "-"):
+			return spec, newParseError(vs, trimmedOffset, len(trimmed), ErrMultipleNotAllowed, "can't specify multiple versions; a single exact version is required")
+		case strings.HasPrefix(trimmed, "-"):
 			// User seems to be trying to use the npm-style range operator
-			return spec, fmt.Errorf("can't specify version range; a single exact version is required")
-		case strings.HasPrefix(strings.TrimSpace(vs), remain):
+			return spec, newParseError(vs, trimmedOffset, len(trimmed), ErrRangeNotAllowed, "can't specify version range; a single exact version is required")
+		case strings.HasPrefix(strings.TrimSpace(vs), trimmed):
 			// Whole string is invalid, then.
-			return spec, fmt.Errorf("invalid specification; required format is three positive integers separated by periods")
+			return spec, newParseError(vs, trimmedOffset, len(trimmed), ErrInvalidSequence, "invalid specification; required format is three positive integers separated by periods")
 		default:
-			return spec, fmt.Errorf("invalid characters %q", remain)
+			return spec, newParseError(vs, trimmedOffset, len(trimmed), ErrInvalidCharacters, "invalid characters %q", trimmed)
 		}
 	}
 
 	if raw.numCt > 3 {
-		return spec, fmt.Errorf("too many numbered portions; only three are allowed (major, minor, patch)")
+		return spec, newParseError(vs, pos.nums[3]-1, pos.numsEnd-pos.nums[3]+1, ErrTooManySegments, "too many numbered portions; only three are allowed (major, minor, patch)")
 	}
 
+	if strict && raw.numCt < 3 {
+		return spec, newParseError(vs, pos.numsEnd, 0, ErrMissingSegments, "%s number is required", rawNumNames[raw.numCt])
+	}
 	for i := raw.numCt; i < len(raw.nums); i++ {
 		raw.nums[i] = "0"
 	}
@@ -72,7 +100,31 @@ func ParseExactVersion(vs string) (VersionSpec, error) {
 		switch {
 		case isWildcardNum(s):
 			// Can't use wildcards in an exact specification
-			return spec, fmt.Errorf("can't use wildcard for %s number; an exact version is required", rawNumNames[i])
+			return spec, newParseError(vs, pos.nums[i], len(s), ErrWildcardNotAllowed, "can't use wildcard for %s number; an exact version is required", rawNumNames[i])
+		case strict && len(s) > 1 && s[0] == '0':
+			return spec, newParseError(vs, pos.nums[i], len(s), ErrLeadingZero, "%s number must not have leading zeros", rawNumNames[i])
+		default:
+			if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+				return spec, newParseError(vs, pos.nums[i], len(s), ErrNumberTooLarge, "%s number is too large", rawNumNames[i])
+			}
+		}
+	}
+
+	if strict {
+		// The scanner treats a "-" or "+" with nothing after it as if it
+		// were absent, so we must look for those in the input instead.
+		tail := vs[pos.nums[2]+len(raw.nums[2]):]
+		switch {
+		case raw.pre == "" && strings.HasPrefix(tail, "-"):
+			return spec, newParseError(vs, len(vs)-len(tail)+1, 0, ErrInvalidPrerelease, "prerelease identifiers must not be empty")
+		case raw.meta == "" && strings.HasSuffix(tail, "+"):
+			return spec, newParseError(vs, len(vs), 0, ErrInvalidMetadata, "metadata identifiers must not be empty")
+		}
+		if i, l, invalid := invalidPrereleaseIdent(raw.pre); raw.pre != "" && invalid {
+			return spec, newParseError(vs, pos.pre+1+i, l, ErrInvalidPrerelease, "prerelease identifiers must not be empty or have leading zeros")
+		}
+		if i := emptyMetadataIdent(raw.meta); i >= 0 {
+			return spec, newParseError(vs, pos.meta+1+i, 0, ErrInvalidMetadata, "metadata identifiers must not be empty")
 		}
 	}
 
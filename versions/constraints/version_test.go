@@ -155,6 +155,11 @@ func TestParseExactVersion(t *testing.T) {
 			VersionSpec{},
 			`can't specify version range; a single exact version is required`,
 		},
+		{
+			"99999999999999999999.0.0",
+			VersionSpec{},
+			`major number is too large`,
+		},
 	}
 
 	for _, test := range tests {
@@ -178,3 +183,151 @@ func TestParseExactVersion(t *testing.T) {
 		})
 	}
 }
+
+// TestParseExactVersionStrict checks ParseExactVersionStrict against the
+// test strings published alongside the regular expressions in the Semantic
+// Versioning 2.0.0 specification.
+func TestParseExactVersionStrict(t *testing.T) {
+	valid := []string{
+		"0.0.4",
+		"1.2.3",
+		"10.20.30",
+		"1.1.2-prerelease+meta",
+		"1.1.2+meta",
+		"1.1.2+meta-valid",
+		"1.0.0-alpha",
+		"1.0.0-beta",
+		"1.0.0-alpha.beta",
+		"1.0.0-alpha.beta.1",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha0.valid",
+		"1.0.0-alpha.0valid",
+		"1.0.0-alpha-a.b-c-somethinglong+build.1-aef.1-its-okay",
+		"1.0.0-rc.1+build.1",
+		"2.0.0-rc.1+build.123",
+		"1.2.3-beta",
+		"10.2.3-DEV-SNAPSHOT",
+		"1.2.3-SNAPSHOT-123",
+		"1.0.0",
+		"2.0.0",
+		"1.1.7",
+		"2.0.0+build.1848",
+		"2.0.1-alpha.1227",
+		"1.0.0-alpha+beta",
+		"1.2.3----RC-SNAPSHOT.12.9.1--.12+788",
+		"1.2.3----R-S.12.9.1--.12+meta",
+		"1.2.3----RC-SNAPSHOT.12.9.1--.12",
+		"1.0.0+0.build.1-rc.10000aaa-kk-0.1",
+		"1.0.0-0A.is.legal",
+
+		// The corpus also includes the following, which is valid but has
+		// version numbers too large to represent, and so is tested below
+		// with the invalid versions instead:
+		// 99999999999999999999999.999999999999999999.99999999999999999
+
+		// Leading zeros are allowed in metadata, but not in prerelease.
+		"1.0.0+001",
+	}
+	invalid := []string{
+		"1",
+		"1.2",
+		"1.2.3-0123",
+		"1.2.3-0123.0123",
+		"1.1.2+.123",
+		"+invalid",
+		"-invalid",
+		"-invalid+invalid",
+		"-invalid.01",
+		"alpha",
+		"alpha.beta",
+		"alpha.beta.1",
+		"alpha.1",
+		"alpha+beta",
+		"alpha_beta",
+		"alpha.",
+		"alpha..",
+		"beta",
+		"1.0.0-alpha_beta",
+		"-alpha.",
+		"1.0.0-alpha..",
+		"1.0.0-alpha..1",
+		"1.0.0-alpha...1",
+		"1.0.0-alpha....1",
+		"1.0.0-alpha.....1",
+		"1.0.0-alpha......1",
+		"1.0.0-alpha.......1",
+		"01.1.1",
+		"1.01.1",
+		"1.1.01",
+		"1.2.3.DEV",
+		"1.2-SNAPSHOT",
+		"1.2.31.2.3----RC-SNAPSHOT.12.09.1--..12+788",
+		"1.2-RC-SNAPSHOT",
+		"-1.0.3-gamma+b7718",
+		"+justmeta",
+		"9.8.7+meta+meta",
+		"9.8.7-whatever+meta+meta",
+		"99999999999999999999999.999999999999999999.99999999999999999----RC-SNAPSHOT.12.09.1--------------------------------..12",
+		"99999999999999999999999.999999999999999999.99999999999999999",
+
+		// The scanner ignores a trailing separator unless we check for it.
+		"1.2.3-",
+		"1.2.3+",
+		"1.2.3-rc+",
+	}
+
+	for _, input := range valid {
+		t.Run(input, func(t *testing.T) {
+			got, err := ParseExactVersionStrict(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// A strictly-valid version must be unchanged by a round trip.
+			if got.String() != input {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, input)
+			}
+		})
+	}
+	for _, input := range invalid {
+		t.Run(input, func(t *testing.T) {
+			got, err := ParseExactVersionStrict(input)
+			if err == nil {
+				t.Fatalf("succeeded with %s; want error", got)
+			}
+		})
+	}
+}
+
+func TestParseExactVersionStrictErrors(t *testing.T) {
+	tests := []struct {
+		Input    string
+		WantCode ParseErrorCode
+		WantText string
+	}{
+		{"1.2", ErrMissingSegments, ""},
+		{"1.02.3", ErrLeadingZero, "02"},
+		{"1.2.3-rc.01", ErrInvalidPrerelease, "01"},
+		{"1.2.3-rc..1", ErrInvalidPrerelease, ""},
+		{"1.2.3-", ErrInvalidPrerelease, ""},
+		{"1.2.3+a..b", ErrInvalidMetadata, ""},
+		{"1.2.3-rc+", ErrInvalidMetadata, ""},
+		{"1.2.99999999999999999999", ErrNumberTooLarge, "99999999999999999999"},
+		{"v1.2.3", ErrVPrefix, "v"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			_, err := ParseExactVersionStrict(test.Input)
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("wrong error %#v; want *ParseError", err)
+			}
+			if perr.Code != test.WantCode {
+				t.Errorf("wrong code\ngot:  %s\nwant: %s", perr.Code, test.WantCode)
+			}
+			if perr.Text != test.WantText {
+				t.Errorf("wrong text\ngot:  %q\nwant: %q", perr.Text, test.WantText)
+			}
+		})
+	}
+}
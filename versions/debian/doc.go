@@ -0,0 +1,15 @@
+// Package debian is a companion to package versions that deals with the
+// version numbers of Debian packages, as compared by dpkg.
+//
+// Debian versions have the form "[epoch:]upstream_version[-debian_revision]",
+// such as "1:2.30-0ubuntu1~18.04", and are ordered using rules that allow
+// any number of alphanumeric segments and that sort "~" before everything,
+// even the end of the string. This package therefore has its own Version
+// type, whose Scheme lets Debian versions be used with versions.Set and
+// versions.List, and with the functions of package versions that create
+// sets, such as versions.AtLeast.
+//
+// Sets can be created from the version relations used in package
+// relationship fields, such as ">= 1.2" or "<< 2.0". See ParseRelation for
+// details.
+package debian
@@ -0,0 +1,129 @@
+package debian
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+func TestParseRelations(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr bool
+	}{
+		{">= 1.2", ">= 1.2", false},
+		{"(<< 2.0)", "<< 2.0", false},
+		{"=1:1.0-1", "= 1:1.0-1", false},
+		{">= 1.2, << 2.0", ">= 1.2, << 2.0", false},
+		{"(>= 1.2), (<< 2.0)", ">= 1.2, << 2.0", false},
+
+		{"", "", true},
+		{"1.2", "", true},
+		{"< 1.2", "", true},
+		{"> 1.2", "", true},
+		{"=> 1.2", "", true},
+		{"(>= 1.2", "", true},
+		{">=", "", true},
+		{">= 1.2,", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			rels, err := ParseRelations(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %s", rels)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := rels.String(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMeetingRelations(t *testing.T) {
+	tests := []struct {
+		Relations string
+		Version   string
+		Want      bool
+	}{
+		{">= 1.2", "1.2", true},
+		{">= 1.2", "1.2~rc1", false},
+		{">= 1.2", "1:0.1", true},
+		{"<< 2.0", "2.0~beta1", true},
+		{"<< 2.0", "2.0", false},
+		{"<= 2.0", "2.0", true},
+		{"<= 2.0", "2.0-1", false},
+		{"= 1.0", "1.0", true},
+		{"= 1.0", "1.0-1", false},
+		{">> 1.0", "1.0", false},
+		{">> 1.0", "1.0-1", true},
+		{">= 1.2, << 2.0", "1.5", true},
+		{">= 1.2, << 2.0", "2.1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s has %s", test.Relations, test.Version), func(t *testing.T) {
+			set := MustMakeSet(MeetingRelationsString(test.Relations))
+			if got, want := set.Has(MustParse(test.Version)), test.Want; got != want {
+				t.Errorf("wrong result\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
+
+func TestListNewestInSet(t *testing.T) {
+	l := versions.List{
+		MustParse("2.30-0ubuntu1"),
+		MustParse("2.30-0ubuntu1~18.04"),
+		MustParse("2.29-1"),
+		MustParse("1:1.0-1"),
+	}
+	set := MustMakeSet(MeetingRelationsString("<< 2.30"))
+
+	if got, want := l.NewestInSet(set).String(), "2.29-1"; got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := l.Newest().String(), "1:1.0-1"; got != want {
+		t.Errorf("wrong newest version\ngot:  %s\nwant: %s", got, want)
+	}
+
+	l.Sort()
+	if got, want := l[0].String(), "2.29-1"; got != want {
+		t.Errorf("wrong oldest version after sort\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestMeetingRelationsCompare(t *testing.T) {
+	tests := []struct {
+		A, B     string
+		Subset   bool // A is a subset of B
+		Overlaps bool
+	}{
+		{">= 1.2, << 2.0", ">= 1.0", true, true},
+		{">= 1.2, << 2.0", ">= 2.0", false, false},
+		{"= 1.5", ">= 1.2, << 2.0", true, true},
+		{"<= 2.0", "<< 2.0", false, true},
+		{">> 1.0", ">= 1.0-1", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s and %s", test.A, test.B), func(t *testing.T) {
+			a := MustMakeSet(MeetingRelationsString(test.A))
+			b := MustMakeSet(MeetingRelationsString(test.B))
+			if got, want := a.IsSubsetOf(b), test.Subset; got != want {
+				t.Errorf("wrong IsSubsetOf result\ngot:  %t\nwant: %t", got, want)
+			}
+			if got, want := a.Overlaps(b), test.Overlaps; got != want {
+				t.Errorf("wrong Overlaps result\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package debian
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version represents a single Debian package version.
+type Version struct {
+	// Epoch is the number before the first colon, which is zero if not
+	// specified.
+	Epoch uint64
+
+	// Upstream is the upstream version, which always starts with a digit
+	// for any version returned by Parse.
+	Upstream string
+
+	// Revision is the Debian revision, which follows the last hyphen in
+	// the version string, or the empty string if there is none.
+	Revision string
+}
+
+// Unspecified is the zero value of Version and represents the absense of a
+// version number.
+var Unspecified Version
+
+// Parse parses the given string as a Debian package version, using the
+// same rules as dpkg.
+//
+// If the given string is not parseable then an error is returned that is
+// suitable for display directly to a hypothetical end-user that provided this
+// version string, as long as they can read English.
+func Parse(s string) (Version, error) {
+	var v Version
+	remain := strings.TrimSpace(s)
+	if remain == "" {
+		return Unspecified, fmt.Errorf("empty version string")
+	}
+	if strings.ContainsAny(remain, " \t\n") {
+		return Unspecified, fmt.Errorf("invalid version %q; must not contain whitespace", s)
+	}
+
+	if colon := strings.IndexByte(remain, ':'); colon >= 0 {
+		epoch := remain[:colon]
+		if epoch == "" {
+			return Unspecified, fmt.Errorf("invalid version %q; epoch before colon is empty", s)
+		}
+		n, err := strconv.ParseUint(epoch, 10, 31)
+		if err != nil {
+			return Unspecified, fmt.Errorf("invalid version %q; epoch must be a number no greater than %d", s, 1<<31-1)
+		}
+		v.Epoch = n
+		remain = remain[colon+1:]
+	}
+
+	if hyphen := strings.LastIndexByte(remain, '-'); hyphen >= 0 {
+		v.Revision = remain[hyphen+1:]
+		if v.Revision == "" {
+			return Unspecified, fmt.Errorf("invalid version %q; revision after hyphen is empty", s)
+		}
+		remain = remain[:hyphen]
+	}
+	v.Upstream = remain
+
+	switch {
+	case v.Upstream == "":
+		return Unspecified, fmt.Errorf("invalid version %q; upstream version is empty", s)
+	case !isDigit(v.Upstream[0]):
+		return Unspecified, fmt.Errorf("invalid version %q; upstream version must start with a digit", s)
+	}
+	for _, c := range []byte(v.Upstream) {
+		if !isAlnum(c) && !strings.ContainsRune(".-+~:", rune(c)) {
+			return Unspecified, fmt.Errorf("invalid version %q; upstream version contains invalid character %q", s, c)
+		}
+	}
+	for _, c := range []byte(v.Revision) {
+		if !isAlnum(c) && !strings.ContainsRune(".+~", rune(c)) {
+			return Unspecified, fmt.Errorf("invalid version %q; revision contains invalid character %q", s, c)
+		}
+	}
+
+	return v, nil
+}
+
+// MustParse is the same as Parse except that it will panic instead of
+// returning an error.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the receiver in the usual string representation, omitting
+// the epoch if it is zero.
+func (v Version) String() string {
+	var buf strings.Builder
+	if v.Epoch != 0 {
+		fmt.Fprintf(&buf, "%d:", v.Epoch)
+	}
+	buf.WriteString(v.Upstream)
+	if v.Revision != "" {
+		buf.WriteByte('-')
+		buf.WriteString(v.Revision)
+	}
+	return buf.String()
+}
+
+func (v Version) GoString() string {
+	return fmt.Sprintf("debian.MustParse(%q)", v.String())
+}
+
+// IsPrerelease returns true if the receiver's upstream version contains a
+// "~", which by convention marks a pre-release such as "1.0~rc1" because it
+// sorts before the final release. A "~" in the revision, as in backports like
+// "2.30-0ubuntu1~18.04", does not make a version a pre-release.
+func (v Version) IsPrerelease() bool {
+	return strings.Contains(v.Upstream, "~")
+}
+
+// Compare returns -1, 0 or 1 depending on whether the receiver has lower,
+// the same or higher precedence than the other given version, using the
+// same ordering as "dpkg --compare-versions".
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.Epoch < other.Epoch:
+		return -1
+	case v.Epoch > other.Epoch:
+		return 1
+	}
+	if c := compareParts(v.Upstream, other.Upstream); c != 0 {
+		return c
+	}
+	return compareParts(v.Revision, other.Revision)
+}
+
+// LessThan returns true if the receiver has a lower precedence than the
+// other given version.
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// GreaterThan returns true if the receiver has a higher precedence than the
+// other given version.
+func (v Version) GreaterThan(other Version) bool {
+	return v.Compare(other) > 0
+}
+
+// Same returns true if the receiver has the same precedence as the other
+// given version. Versions with different strings can have the same
+// precedence, such as "1.0" and "1.00" or "0:1.0" and "1.0".
+func (v Version) Same(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// MarshalText is an implementation of encoding.TextMarshaler, allowing versions
+// to be automatically marshalled for text-based serialization formats,
+// including encoding/json.
+//
+// The format used is that returned by String, which can be parsed using
+// Parse.
+func (v Version) MarshalText() (text []byte, err error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText is an implementation of encoding.TextUnmarshaler, allowing
+// versions to be automatically unmarshalled from strings in text-based
+// serialization formats, including encoding/json.
+//
+// The format expected is what is accepted by Parse. Any parser errors
+// are passed on verbatim to the caller.
+func (v *Version) UnmarshalText(text []byte) error {
+	new, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = new
+	return nil
+}
+
+// compareParts compares two upstream versions or two revisions using the
+// algorithm of dpkg's verrevcmp function. The strings are split into
+// alternating non-digit and digit segments, with the non-digit segments
+// compared character by character using charOrder and the digit segments
+// compared numerically.
+func compareParts(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := charOrder(a, i), charOrder(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// charOrder returns the sort weight of the character at index i of s.
+// A tilde sorts before everything, including the end of the string, and
+// letters sort before all other non-digit characters.
+func charOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
@@ -0,0 +1,113 @@
+package debian
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    Version
+		WantErr bool
+	}{
+		{"1.0", Version{0, "1.0", ""}, false},
+		{"1.0-1", Version{0, "1.0", "1"}, false},
+		{"1:2.30-0ubuntu1~18.04", Version{1, "2.30", "0ubuntu1~18.04"}, false},
+		{"0:1.0", Version{0, "1.0", ""}, false},
+		{"1.0-rc1-2", Version{0, "1.0-rc1", "2"}, false},
+		{"2:1.0:1-1", Version{2, "1.0:1", "1"}, false},
+		{" 1.0+dfsg~beta ", Version{0, "1.0+dfsg~beta", ""}, false},
+
+		{"", Version{}, true},
+		{"1.0 1", Version{}, true},
+		{":1.0", Version{}, true},
+		{"a:1.0", Version{}, true},
+		{"-1:1.0", Version{}, true},
+		{"1.0-", Version{}, true},
+		{"-1", Version{}, true},
+		{"a1.0", Version{}, true},
+		{"1.0_1", Version{}, true},
+		{"1.0-1:2", Version{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got, err := Parse(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Most of these cases are from the test suite of dpkg itself.
+	tests := []struct {
+		A, B string
+		Want int
+	}{
+		{"0", "0", 0},
+		{"0", "00", 0},
+		{"1.0", "1.00", 0},
+		{"0:1.0", "1.0", 0},
+		{"1.0", "1.0-0", 0},
+		{"1.0", "1.0.0", -1},
+		{"1.2", "1.10", -1},
+		{"1:1.0", "2.0", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc1~1", 1},
+		{"1.0~~", "1.0~~a", -1},
+		{"1.0~~a", "1.0~", -1},
+		{"1.0~", "1.0", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0a", "1.0+", -1},
+		{"1.0+", "1.0.1", -1},
+		{"1.0-1", "1.0-1ubuntu1", -1},
+		{"2.30-0ubuntu1~18.04", "2.30-0ubuntu1", -1},
+		{"3.4.5-1.el8", "3.4.5-1.el9", -1},
+		{"1.0-a", "1.0-1", 1},
+		{"1.0.9", "1.0.10", -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A+" "+test.B, func(t *testing.T) {
+			a, b := MustParse(test.A), MustParse(test.B)
+			if got := a.Compare(b); got != test.Want {
+				t.Errorf("wrong result\ngot:  %d\nwant: %d", got, test.Want)
+			}
+			if got := b.Compare(a); got != -test.Want {
+				t.Errorf("wrong result for reverse comparison\ngot:  %d\nwant: %d", got, -test.Want)
+			}
+		})
+	}
+}
+
+func TestVersionIsPrerelease(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  bool
+	}{
+		{"1.0", false},
+		{"1.0~rc1", true},
+		{"1.0~rc1-1", true},
+		{"2.30-0ubuntu1~18.04", false},
+		{"1:2.0+dfsg-1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			if got, want := MustParse(test.Input).IsPrerelease(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+// Package gomod is a companion to package versions that deals with the
+// conventions Go modules use for version numbers.
+//
+// Go module versions are semantic versions written with a "v" prefix, and so
+// this package's parser returns ordinary versions.Version values that can be
+// used with all of the sets and lists in package versions. The precedence
+// rules of package versions match those of golang.org/x/mod/semver, so
+// sorting a versions.List orders module versions the same way the go command
+// does.
+//
+// This package also recognizes pseudo-versions, which the go command
+// generates to refer to specific untagged commits, and the major version
+// suffixes like "/v2" that appear at the end of module paths.
+package gomod
@@ -0,0 +1,109 @@
+package gomod
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// SplitPathVersion splits the major version suffix from the end of the given
+// module path, returning the path without the suffix and the suffix itself.
+//
+// For most paths the suffix has the form "/vN", where N is 2 or greater,
+// while for paths starting with "gopkg.in/" it has the form ".vN", where N
+// may also be 0 or 1, optionally followed by "-unstable". The suffix is the
+// empty string for a path that has none.
+//
+// The final result is false if the path ends with something that looks like
+// a suffix but is not valid, such as "/v1" or "/v2.0".
+func SplitPathVersion(path string) (prefix, pathMajor string, ok bool) {
+	if strings.HasPrefix(path, "gopkg.in/") {
+		return splitGopkgIn(path)
+	}
+
+	i := len(path)
+	dot := false
+	for i > 0 && (('0' <= path[i-1] && path[i-1] <= '9') || path[i-1] == '.') {
+		if path[i-1] == '.' {
+			dot = true
+		}
+		i--
+	}
+	if i <= 1 || i == len(path) || path[i-1] != 'v' || path[i-2] != '/' {
+		return path, "", true
+	}
+	prefix, pathMajor = path[:i-2], path[i-2:]
+	if dot || len(pathMajor) <= 2 || pathMajor[2] == '0' || pathMajor == "/v1" {
+		return path, "", false
+	}
+	return prefix, pathMajor, true
+}
+
+func splitGopkgIn(path string) (prefix, pathMajor string, ok bool) {
+	if strings.HasSuffix(path, "-unstable") {
+		prefix, pathMajor, ok = splitGopkgIn(strings.TrimSuffix(path, "-unstable"))
+		if !ok {
+			return path, "", false
+		}
+		return prefix, pathMajor + "-unstable", true
+	}
+
+	i := len(path)
+	for i > 0 && '0' <= path[i-1] && path[i-1] <= '9' {
+		i--
+	}
+	if i <= 1 || i == len(path) || path[i-1] != 'v' || path[i-2] != '.' {
+		// All gopkg.in paths must end in a version suffix
+		return path, "", false
+	}
+	prefix, pathMajor = path[:i-2], path[i-2:]
+	if len(pathMajor) > 3 && pathMajor[2] == '0' {
+		return path, "", false
+	}
+	return prefix, pathMajor, true
+}
+
+// PathMajor returns the major version suffix that the path of a module must
+// have for the given version to be a valid version of that module, in the
+// "/vN" form used for paths that don't start with "gopkg.in/".
+//
+// The result is the empty string for versions with major version 0 or 1,
+// or with the "+incompatible" suffix.
+func PathMajor(v versions.Version) string {
+	if v.Major < 2 || IsIncompatible(v) {
+		return ""
+	}
+	return "/v" + strconv.FormatUint(v.Major, 10)
+}
+
+// CheckPathMajor returns an error if the given version is not valid for a
+// module whose path has the given major version suffix, as returned by
+// SplitPathVersion.
+func CheckPathMajor(v versions.Version, pathMajor string) error {
+	pathMajor = strings.TrimSuffix(pathMajor, "-unstable")
+
+	if pathMajor == ".v1" && v.Major == 0 && v.Minor == 0 && v.Patch == 0 && IsPseudoVersion(v) {
+		// gopkg.in paths with .v1 allow v0 pseudo-versions, because the
+		// go command generated them before it understood gopkg.in.
+		return nil
+	}
+
+	var want string
+	switch {
+	case pathMajor == "":
+		if v.Major < 2 || IsIncompatible(v) {
+			return nil
+		}
+		want = "v0 or v1"
+	case IsIncompatible(v):
+		return fmt.Errorf("invalid version %s for module path suffix %q; \"+incompatible\" is allowed only for modules without a major version suffix", FormatVersion(v), pathMajor)
+	default:
+		want = pathMajor[1:]
+		if "v"+strconv.FormatUint(v.Major, 10) == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid version %s; major version should be %s, not v%d", FormatVersion(v), want, v.Major)
+}
@@ -0,0 +1,77 @@
+package gomod
+
+import (
+	"testing"
+)
+
+func TestSplitPathVersion(t *testing.T) {
+	tests := []struct {
+		Path      string
+		Prefix    string
+		PathMajor string
+		OK        bool
+	}{
+		{"example.com/foo", "example.com/foo", "", true},
+		{"example.com/foo/v2", "example.com/foo", "/v2", true},
+		{"example.com/foo/v10", "example.com/foo", "/v10", true},
+		{"example.com/foo/v1", "example.com/foo/v1", "", false},
+		{"example.com/foo/v0", "example.com/foo/v0", "", false},
+		{"example.com/foo/v02", "example.com/foo/v02", "", false},
+		{"example.com/foo/v2.0", "example.com/foo/v2.0", "", false},
+		{"example.com/foov2", "example.com/foov2", "", true},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml", ".v2", true},
+		{"gopkg.in/yaml.v0", "gopkg.in/yaml", ".v0", true},
+		{"gopkg.in/check.v1-unstable", "gopkg.in/check", ".v1-unstable", true},
+		{"gopkg.in/yaml", "gopkg.in/yaml", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Path, func(t *testing.T) {
+			prefix, pathMajor, ok := SplitPathVersion(test.Path)
+			if prefix != test.Prefix || pathMajor != test.PathMajor || ok != test.OK {
+				t.Errorf(
+					"wrong result\ngot:  %q, %q, %t\nwant: %q, %q, %t",
+					prefix, pathMajor, ok,
+					test.Prefix, test.PathMajor, test.OK,
+				)
+			}
+		})
+	}
+}
+
+func TestCheckPathMajor(t *testing.T) {
+	tests := []struct {
+		Version   string
+		PathMajor string
+		WantErr   bool
+	}{
+		{"v0.1.0", "", false},
+		{"v1.2.3", "", false},
+		{"v2.0.0", "", true},
+		{"v2.0.0+incompatible", "", false},
+		{"v2.0.0", "/v2", false},
+		{"v2.0.0+incompatible", "/v2", true},
+		{"v3.0.0", "/v2", true},
+		{"v1.0.0", "/v2", true},
+		{"v2.0.0", ".v2", false},
+		{"v1.0.0", ".v1-unstable", false},
+		{"v0.0.0-20191109021931-daa7c04131f5", ".v1", false},
+		{"v0.1.0", ".v1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Version+" "+test.PathMajor, func(t *testing.T) {
+			err := CheckPathMajor(MustParseVersion(test.Version), test.PathMajor)
+			if test.WantErr && err == nil {
+				t.Fatalf("unexpected success")
+			}
+			if !test.WantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+
+	if got, want := PathMajor(MustParseVersion("v3.1.0")), "/v3"; got != want {
+		t.Errorf("wrong PathMajor result\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package gomod
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// PseudoVersion describes the parts of a pseudo-version, which the go command
+// uses to refer to a specific commit that has no version tag.
+//
+// Pseudo-versions take one of three forms, depending on the most recent
+// tagged version before the commit:
+//
+//	vX.0.0-yyyymmddhhmmss-abcdefabcdef      (no earlier tag with major version X)
+//	vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef (most recent tag is vX.Y.Z-pre)
+//	vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef (most recent tag is vX.Y.Z)
+//
+// Each form is a prerelease version that sorts after the tag it is based on
+// and before any later tags, so pseudo-versions are ordered correctly
+// relative to tagged versions without any special handling.
+type PseudoVersion struct {
+	// Version is the full pseudo-version.
+	Version versions.Version
+
+	// Base is the tagged version the pseudo-version is based on, or
+	// versions.Unspecified if there was no earlier tag.
+	Base versions.Version
+
+	// Time is the commit time, in UTC, at a precision of one second.
+	Time time.Time
+
+	// Revision is the abbreviated commit identifier, which is usually the
+	// first twelve hexadecimal digits of a git commit hash.
+	Revision string
+}
+
+const pseudoTimeFormat = "20060102150405"
+
+// ParsePseudoVersion parses the given string as a Go module version and
+// then returns its pseudo-version components, or an error if it is not a
+// valid pseudo-version.
+func ParsePseudoVersion(s string) (PseudoVersion, error) {
+	v, err := ParseVersion(s)
+	if err != nil {
+		return PseudoVersion{}, err
+	}
+	return SplitPseudoVersion(v)
+}
+
+// IsPseudoVersion returns true if the given version has the form of a
+// pseudo-version.
+func IsPseudoVersion(v versions.Version) bool {
+	_, err := SplitPseudoVersion(v)
+	return err == nil
+}
+
+// SplitPseudoVersion returns the pseudo-version components of the given
+// version, or an error if it is not a valid pseudo-version.
+func SplitPseudoVersion(v versions.Version) (PseudoVersion, error) {
+	ret := PseudoVersion{Version: v}
+	notPseudo := fmt.Errorf("%s is not a pseudo-version", FormatVersion(v))
+
+	// The timestamp and revision are always the last two identifiers of the
+	// prerelease portion, although the timestamp may be preceded by
+	// a dot rather than a dash.
+	pre := string(v.Prerelease)
+	dash := strings.LastIndexByte(pre, '-')
+	if dash < 0 {
+		return ret, notPseudo
+	}
+	rest, rev := pre[:dash], pre[dash+1:]
+	if rev == "" || strings.Contains(rev, ".") {
+		return ret, notPseudo
+	}
+	var ts, prefix string
+	if dot := strings.LastIndexByte(rest, '.'); dot >= 0 {
+		prefix, ts = rest[:dot], rest[dot+1:]
+	} else {
+		ts = rest
+	}
+	if len(ts) != len(pseudoTimeFormat) {
+		return ret, notPseudo
+	}
+	t, err := time.Parse(pseudoTimeFormat, ts)
+	if err != nil {
+		return ret, notPseudo
+	}
+	ret.Time = t
+	ret.Revision = rev
+
+	switch {
+	case prefix == "" && strings.IndexByte(rest, '.') < 0:
+		// vX.0.0-yyyymmddhhmmss-abcdefabcdef
+		if v.Minor != 0 || v.Patch != 0 {
+			return ret, notPseudo
+		}
+		ret.Base = versions.Unspecified
+	case prefix == "0":
+		// vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef
+		if v.Patch == 0 {
+			return ret, notPseudo
+		}
+		ret.Base = versions.Version{
+			Major:    v.Major,
+			Minor:    v.Minor,
+			Patch:    v.Patch - 1,
+			Metadata: v.Metadata,
+		}
+	case strings.HasSuffix(prefix, ".0"):
+		// vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef
+		ret.Base = versions.Version{
+			Major:      v.Major,
+			Minor:      v.Minor,
+			Patch:      v.Patch,
+			Prerelease: versions.VersionExtra(strings.TrimSuffix(prefix, ".0")),
+			Metadata:   v.Metadata,
+		}
+	default:
+		return ret, notPseudo
+	}
+
+	return ret, nil
+}
+
+// NewPseudoVersion returns the pseudo-version for a commit with the given
+// time and revision identifier, whose most recent tagged ancestor is the
+// given base version. The base should be versions.Unspecified if there is no
+// such tag, in which case the pseudo-version has the given major version.
+func NewPseudoVersion(major uint64, base versions.Version, t time.Time, rev string) versions.Version {
+	suffix := t.UTC().Format(pseudoTimeFormat) + "-" + rev
+	switch {
+	case base == versions.Unspecified:
+		return versions.Version{
+			Major:      major,
+			Prerelease: versions.VersionExtra(suffix),
+		}
+	case base.Prerelease != "":
+		base.Prerelease = base.Prerelease + ".0." + versions.VersionExtra(suffix)
+		return base
+	default:
+		base.Patch++
+		base.Prerelease = versions.VersionExtra("0." + suffix)
+		return base
+	}
+}
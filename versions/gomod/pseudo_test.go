@@ -0,0 +1,70 @@
+package gomod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+func TestParsePseudoVersion(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Base     string
+		Time     string
+		Revision string
+		WantErr  bool
+	}{
+		{"v0.0.0-20191109021931-daa7c04131f5", "", "2019-11-09T02:19:31Z", "daa7c04131f5", false},
+		{"v2.0.0-20191109021931-daa7c04131f5+incompatible", "", "2019-11-09T02:19:31Z", "daa7c04131f5", false},
+		{"v1.2.4-0.20191109021931-daa7c04131f5", "v1.2.3", "2019-11-09T02:19:31Z", "daa7c04131f5", false},
+		{"v1.2.3-pre.0.20191109021931-daa7c04131f5", "v1.2.3-pre", "2019-11-09T02:19:31Z", "daa7c04131f5", false},
+		{"v1.2.3-rc.1.0.20191109021931-daa7c04131f5", "v1.2.3-rc.1", "2019-11-09T02:19:31Z", "daa7c04131f5", false},
+
+		{"v1.2.3", "", "", "", true},
+		{"v1.2.3-pre", "", "", "", true},
+		{"v1.2.0-20191109021931-daa7c04131f5", "", "", "", true},
+		{"v1.2.0-0.20191109021931-daa7c04131f5", "", "", "", true},
+		{"v1.2.3-pre.20191109021931-daa7c04131f5", "", "", "", true},
+		{"v0.0.0-2019110902193-daa7c04131f5", "", "", "", true},
+		{"v0.0.0-20191339021931-daa7c04131f5", "", "", "", true},
+		{"v0.0.0-20191109021931-", "", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			pv, err := ParsePseudoVersion(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %#v", pv)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			wantBase := versions.Unspecified
+			if test.Base != "" {
+				wantBase = MustParseVersion(test.Base)
+			}
+			if pv.Base != wantBase {
+				t.Errorf("wrong base\ngot:  %s\nwant: %s", FormatVersion(pv.Base), FormatVersion(wantBase))
+			}
+			if got, want := pv.Time.Format(time.RFC3339), test.Time; got != want {
+				t.Errorf("wrong time\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := pv.Revision, test.Revision; got != want {
+				t.Errorf("wrong revision\ngot:  %s\nwant: %s", got, want)
+			}
+
+			// Building a pseudo-version from the parts should give us back
+			// what we started with.
+			got := NewPseudoVersion(pv.Version.Major, pv.Base, pv.Time, pv.Revision)
+			got.Metadata = pv.Version.Metadata
+			if want := pv.Version; got != want {
+				t.Errorf("wrong rebuilt version\ngot:  %s\nwant: %s", FormatVersion(got), FormatVersion(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package gomod
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// Incompatible is the build metadata that the go command adds to versions
+// of major version 2 or later of a module that has no go.mod file, and thus
+// no major version suffix in its module path.
+const Incompatible versions.VersionExtra = "incompatible"
+
+// ParseVersion parses a Go module version string, such as "v1.2.3", and
+// returns the version it represents.
+//
+// As in golang.org/x/mod/semver, the minor and patch numbers may be omitted
+// when there is no prerelease or build metadata portion, so "v1.2" is the
+// same as "v1.2.0". Go modules do not permit build metadata other than the
+// "+incompatible" suffix, which is returned as the version's Metadata.
+//
+// If the given string is not a valid module version then an error is
+// returned that is suitable for display directly to a hypothetical end-user
+// that provided this version string, as long as they can read English.
+func ParseVersion(s string) (versions.Version, error) {
+	if s == "" {
+		return versions.Unspecified, fmt.Errorf("empty version string")
+	}
+	if s[0] != 'v' {
+		return versions.Unspecified, fmt.Errorf("invalid version %q; Go module versions must start with \"v\"", s)
+	}
+
+	var v versions.Version
+	var ok bool
+	remain := s[1:]
+	v.Major, remain, ok = parseNum(remain)
+	if !ok {
+		return versions.Unspecified, invalidVersion(s)
+	}
+	if remain == "" {
+		return v, nil
+	}
+	if remain[0] != '.' {
+		return versions.Unspecified, invalidVersion(s)
+	}
+	v.Minor, remain, ok = parseNum(remain[1:])
+	if !ok {
+		return versions.Unspecified, invalidVersion(s)
+	}
+	if remain == "" {
+		return v, nil
+	}
+	if remain[0] != '.' {
+		return versions.Unspecified, invalidVersion(s)
+	}
+	v.Patch, remain, ok = parseNum(remain[1:])
+	if !ok {
+		return versions.Unspecified, invalidVersion(s)
+	}
+
+	if strings.HasPrefix(remain, "-") {
+		var pre string
+		pre, remain = splitAt(remain[1:], '+')
+		if !validIdents(pre, true) {
+			return versions.Unspecified, invalidVersion(s)
+		}
+		v.Prerelease = versions.VersionExtra(pre)
+	}
+	if strings.HasPrefix(remain, "+") {
+		meta := remain[1:]
+		if !validIdents(meta, false) {
+			return versions.Unspecified, invalidVersion(s)
+		}
+		if versions.VersionExtra(meta) != Incompatible {
+			return versions.Unspecified, fmt.Errorf("invalid version %q; Go module versions must not have build metadata other than \"+incompatible\"", s)
+		}
+		if v.Major < 2 {
+			return versions.Unspecified, fmt.Errorf("invalid version %q; \"+incompatible\" is allowed only for major version 2 or later", s)
+		}
+		v.Metadata = Incompatible
+		remain = ""
+	}
+	if remain != "" {
+		return versions.Unspecified, invalidVersion(s)
+	}
+
+	return v, nil
+}
+
+// MustParseVersion is the same as ParseVersion except that it will panic
+// instead of returning an error.
+func MustParseVersion(s string) versions.Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FormatVersion returns the canonical Go module version string for the given
+// version, which is its usual string representation with a "v" prefix.
+func FormatVersion(v versions.Version) string {
+	return "v" + v.String()
+}
+
+// IsIncompatible returns true if the given version has the "+incompatible"
+// suffix.
+func IsIncompatible(v versions.Version) bool {
+	return v.Metadata == Incompatible
+}
+
+func invalidVersion(s string) error {
+	return fmt.Errorf("invalid version %q; must be \"v\" followed by a semantic version number", s)
+}
+
+// parseNum consumes a decimal number without leading zeros from the start of
+// the given string.
+func parseNum(s string) (uint64, string, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 || (i > 1 && s[0] == '0') {
+		return 0, s, false
+	}
+	n, err := strconv.ParseUint(s[:i], 10, 64)
+	if err != nil {
+		return 0, s, false
+	}
+	return n, s[i:], true
+}
+
+// validIdents returns true if the given string is a valid dot-separated
+// sequence of prerelease or build identifiers. Numeric prerelease identifiers
+// may not have leading zeros.
+func validIdents(s string, pre bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, ident := range strings.Split(s, ".") {
+		if ident == "" {
+			return false
+		}
+		numeric := true
+		for _, c := range ident {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+		if pre && numeric && len(ident) > 1 && ident[0] == '0' {
+			return false
+		}
+	}
+	return true
+}
+
+func splitAt(s string, sep byte) (string, string) {
+	if i := strings.IndexByte(s, sep); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
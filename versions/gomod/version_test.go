@@ -0,0 +1,96 @@
+package gomod
+
+import (
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr bool
+	}{
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2", "v1.2.0", false},
+		{"v1", "v1.0.0", false},
+		{"v0.0.0", "v0.0.0", false},
+		{"v1.2.3-pre", "v1.2.3-pre", false},
+		{"v1.2.3-pre.0.1", "v1.2.3-pre.0.1", false},
+		{"v2.0.0+incompatible", "v2.0.0+incompatible", false},
+		{"v2.0.0-beta+incompatible", "v2.0.0-beta+incompatible", false},
+		{"v0.0.0-20191109021931-daa7c04131f5", "v0.0.0-20191109021931-daa7c04131f5", false},
+
+		{"", "", true},
+		{"1.2.3", "", true},
+		{"v", "", true},
+		{"v1.", "", true},
+		{"v01.2.3", "", true},
+		{"v1.2.03", "", true},
+		{"v1.2-pre", "", true},
+		{"v1.2.3-01", "", true},
+		{"v1.2.3-", "", true},
+		{"v1.2.3-pre..1", "", true},
+		{"v1.2.3-pre_1", "", true},
+		{"v1.2.3+build", "", true},
+		{"v1.2.3+incompatible", "", true},
+		{"v1.2.3.4", "", true},
+		{"v99999999999999999999.0.0", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			v, err := ParseVersion(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %s", FormatVersion(v))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := FormatVersion(v), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestVersionOrdering(t *testing.T) {
+	// These are in the order given by the tests for golang.org/x/mod/semver,
+	// with pseudo-versions inserted to show that they sort between the
+	// tags they are based on.
+	ordered := []string{
+		"v0.0.0-20191109021931-daa7c04131f5",
+		"v0.1.0",
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.0.20200101000000-abcdefabcdef",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1",
+		"v1.0.1-0.20200101000000-abcdefabcdef",
+		"v1.2",
+		"v1.2.3",
+		"v1.2.4",
+		"v2.0.0+incompatible",
+		"v2.1.0",
+	}
+
+	var list versions.List
+	for i := len(ordered) - 1; i >= 0; i-- {
+		list = append(list, MustParseVersion(ordered[i]))
+	}
+	list.Sort()
+
+	for i, v := range list {
+		if got, want := v.(versions.Version), MustParseVersion(ordered[i]); !got.Same(want) {
+			t.Errorf("wrong version at index %d\ngot:  %s\nwant: %s", i, FormatVersion(got), FormatVersion(want))
+		}
+	}
+}
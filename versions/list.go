@@ -4,9 +4,13 @@ import (
 	"sort"
 )
 
-// List is a slice of Version that implements sort.Interface, and also includes
-// some other helper functions.
-type List []Version
+// List is a slice of versions that implements sort.Interface, and also
+// includes some other helper functions.
+//
+// A list may contain versions of any scheme, as described for SchemeVersion,
+// but the methods that order versions compare them using their scheme and so
+// panic if the list contains versions of more than one scheme.
+type List []SchemeVersion
 
 // Filter removes from the receiver any elements that are not in the given
 // set, moving retained elements to lower indices to close any gaps and
@@ -40,15 +44,10 @@ func (l List) Filter(set Set) List {
 //
 // Since build metadata does not participate in precedence, it is possible
 // that a given list may have multiple equally-new versions; in that case
-// Newest will return an arbitrary version from that subset.
-func (l List) Newest() Version {
-	ret := Unspecified
-	for i := len(l) - 1; i >= 0; i-- {
-		if l[i].GreaterThan(ret) {
-			ret = l[i]
-		}
-	}
-	return ret
+// Newest will return an arbitrary version from that subset. Use NewestWith to
+// choose between such versions deterministically.
+func (l List) Newest() SchemeVersion {
+	return l.NewestInSet(All)
 }
 
 // NewestInSet is like Filter followed by Newest, except that it does not
@@ -59,17 +58,27 @@ func (l List) Newest() Version {
 // Similar to Newest, the result is Unspecified if the list is empty or if
 // none of the items are in the given set. Also similar to newest, if there
 // are multiple newest versions (possibly differentiated only by metadata)
-// then one is arbitrarily chosen.
-func (l List) NewestInSet(set Set) Version {
-	ret := Unspecified
+// then one is arbitrarily chosen. Use NewestInSetWith to choose between such
+// versions deterministically.
+func (l List) NewestInSet(set Set) SchemeVersion {
+	var ret SchemeVersion = Unspecified
 	for i := len(l) - 1; i >= 0; i-- {
-		if l[i].GreaterThan(ret) && set.Has(l[i]) {
+		if newer(l[i], ret) && set.Has(l[i]) {
 			ret = l[i]
 		}
 	}
 	return ret
 }
 
+// newer returns true if v has a higher precedence than the newest version
+// found so far, where Unspecified means that none has been found yet.
+func newer(v, newest SchemeVersion) bool {
+	if newest == SchemeVersion(Unspecified) {
+		return !isUnspecified(v)
+	}
+	return compareVersions(v, newest) > 0
+}
+
 // NewestList returns a List containing all of the list items that have the
 // highest precedence.
 //
@@ -91,7 +100,7 @@ func (l List) NewestList() List {
 		var i int
 		n := len(l)
 		for i = n - 1; i >= 0; i-- {
-			if !l[i].Same(l[n-1]) {
+			if compareVersions(l[i], l[n-1]) != 0 {
 				break
 			}
 		}
@@ -106,7 +115,7 @@ func (l List) NewestList() List {
 	ret := make(List, 0, 1) // one item is the common case, in the absense of build metadata
 	example := l.Newest()
 	for _, v := range l {
-		if v.Same(example) {
+		if compareVersions(v, example) == 0 {
 			ret = append(ret, v)
 		}
 	}
@@ -127,7 +136,7 @@ func (l List) Len() int {
 }
 
 func (l List) Less(i, j int) bool {
-	return l[i].LessThan(l[j])
+	return compareVersions(l[i], l[j]) < 0
 }
 
 func (l List) Swap(i, j int) {
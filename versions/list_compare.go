@@ -0,0 +1,142 @@
+package versions
+
+import (
+	"sort"
+	"strings"
+)
+
+// Comparator is a function that orders versions, returning -1, 0 or 1
+// depending on whether a sorts before, the same as or after b.
+//
+// The methods of List whose names end in "With" accept a comparator to use in
+// place of the precedence rules of semantic versioning, which consider
+// versions that differ only in build metadata to be equal. A comparator is
+// usually created using ByPrecedenceThenMetadata, so that only the order of
+// such versions is changed, but any consistent ordering is allowed.
+type Comparator func(a, b SchemeVersion) int
+
+// MetadataComparator is a function that orders the build metadata strings of
+// versions that otherwise have the same precedence, returning -1, 0 or 1
+// depending on whether a sorts before, the same as or after b. The strings
+// may be empty, for versions that have no metadata.
+type MetadataComparator func(a, b VersionExtra) int
+
+// MetadataLexical is a MetadataComparator that orders metadata strings
+// lexically by their bytes, so that "darwin.arm64" sorts before
+// "linux.amd64". Versions without metadata sort before those with metadata.
+var MetadataLexical MetadataComparator = func(a, b VersionExtra) int {
+	return strings.Compare(string(a), string(b))
+}
+
+// MetadataNumeric is a MetadataComparator that orders metadata strings by
+// comparing their identifiers in turn, using the rules that the semantic
+// versioning specification defines for prerelease identifiers. Identifiers
+// that are entirely digits are therefore compared numerically, so that
+// "build.9" sorts before "build.10", which is suitable for build numbers.
+// Versions without metadata sort before those with metadata.
+var MetadataNumeric MetadataComparator = func(a, b VersionExtra) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	case a.LessThan(b):
+		return -1
+	default:
+		return 1
+	}
+}
+
+// ByPrecedenceThenMetadata returns a comparator that orders versions by
+// their precedence, as for Version.LessThan, and then orders any versions
+// that have the same precedence using the given metadata comparator.
+//
+// For example, using MetadataNumeric as the tie-breaker orders 1.0.0+2 after
+// 1.0.0+1 but before 1.0.1+1. Versions of other schemes are ordered using
+// only the Compare method of their scheme, since they have no metadata.
+func ByPrecedenceThenMetadata(tieBreak MetadataComparator) Comparator {
+	return func(a, b SchemeVersion) int {
+		if c := compareVersions(a, b); c != 0 {
+			return c
+		}
+		av, aok := a.(Version)
+		bv, bok := b.(Version)
+		if !aok || !bok {
+			return 0
+		}
+		return tieBreak(av.Metadata, bv.Metadata)
+	}
+}
+
+// SortWith is like Sort but orders the versions using the given comparator,
+// preserving the relative order of any elements that it considers to be
+// equal.
+func (l List) SortWith(cmp Comparator) {
+	sort.Stable(comparatorList{l, cmp})
+}
+
+// IsSortedWith is like IsSorted but checks the order using the given
+// comparator.
+func (l List) IsSortedWith(cmp Comparator) bool {
+	return sort.IsSorted(comparatorList{l, cmp})
+}
+
+// NewestWith is like Newest but compares versions using the given
+// comparator.
+//
+// Unlike Newest, the result is not arbitrary if several versions are equally
+// new: it is the one that appears last in the list. With a comparator that
+// orders versions differing only in metadata, such as one returned by
+// ByPrecedenceThenMetadata, the result depends only on the versions in the
+// list and not on their order.
+func (l List) NewestWith(cmp Comparator) SchemeVersion {
+	return l.NewestInSetWith(All, cmp)
+}
+
+// NewestInSetWith is like NewestInSet but compares versions using the given
+// comparator, choosing between equally-new versions as described for
+// NewestWith.
+func (l List) NewestInSetWith(set Set, cmp Comparator) SchemeVersion {
+	var ret SchemeVersion = Unspecified
+	found := false
+	for i := len(l) - 1; i >= 0; i-- {
+		if (!found || cmp(l[i], ret) > 0) && set.Has(l[i]) {
+			ret = l[i]
+			found = true
+		}
+	}
+	return ret
+}
+
+// NewestListWith is like NewestList but compares versions using the given
+// comparator, returning all of the list items that it considers to be equal
+// to the newest. Unlike NewestList, the result is always a new array, or nil
+// if the receiver is empty.
+//
+// Relative ordering of elements in the receiver is preserved in the output.
+func (l List) NewestListWith(cmp Comparator) List {
+	if len(l) == 0 {
+		return nil
+	}
+	newest := l.NewestWith(cmp)
+	var ret List
+	for _, v := range l {
+		if cmp(v, newest) == 0 {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// comparatorList is an implementation of sort.Interface that orders a list
+// using a comparator.
+type comparatorList struct {
+	List
+	cmp Comparator
+}
+
+func (l comparatorList) Less(i, j int) bool {
+	return l.cmp(l.List[i], l.List[j]) < 0
+}
@@ -0,0 +1,123 @@
+package versions
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestListSortWith(t *testing.T) {
+	tests := map[string]struct {
+		Cmp  Comparator
+		Want string
+	}{
+		"lexical": {
+			ByPrecedenceThenMetadata(MetadataLexical),
+			"[1.0.0 1.0.0+build.10 1.0.0+build.9 1.0.0+darwin.arm64 1.0.0+linux.amd64 1.1.0-rc.1+a 1.1.0]",
+		},
+		"numeric": {
+			ByPrecedenceThenMetadata(MetadataNumeric),
+			"[1.0.0 1.0.0+build.9 1.0.0+build.10 1.0.0+darwin.arm64 1.0.0+linux.amd64 1.1.0-rc.1+a 1.1.0]",
+		},
+		"caller-defined": {
+			// Prefer Linux builds over all others.
+			ByPrecedenceThenMetadata(func(a, b VersionExtra) int {
+				aLinux, bLinux := a.HasPrefixParts("linux"), b.HasPrefixParts("linux")
+				switch {
+				case aLinux == bLinux:
+					return 0
+				case bLinux:
+					return -1
+				default:
+					return 1
+				}
+			}),
+			"[1.0.0+build.10 1.0.0+darwin.arm64 1.0.0 1.0.0+build.9 1.0.0+linux.amd64 1.1.0-rc.1+a 1.1.0]",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			l := List{
+				MustParseVersion("1.1.0"),
+				MustParseVersion("1.0.0+build.10"),
+				MustParseVersion("1.0.0+darwin.arm64"),
+				MustParseVersion("1.0.0"),
+				MustParseVersion("1.0.0+linux.amd64"),
+				MustParseVersion("1.0.0+build.9"),
+				MustParseVersion("1.1.0-rc.1+a"),
+			}
+			l.SortWith(test.Cmp)
+			if got := fmt.Sprint(l); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+			if !l.IsSortedWith(test.Cmp) {
+				t.Errorf("result is not sorted")
+			}
+			// The order of versions with the same precedence must not affect
+			// the order of those with different precedence.
+			if !l.IsSorted() {
+				t.Errorf("result is not sorted by precedence")
+			}
+		})
+	}
+}
+
+func TestListNewestWith(t *testing.T) {
+	forward := List{
+		MustParseVersion("1.0.0+linux.amd64"),
+		MustParseVersion("1.2.3+build.10"),
+		MustParseVersion("1.2.3+build.9"),
+		MustParseVersion("1.2.3-rc.1+build.11"),
+	}
+	reverse := make(List, len(forward))
+	for i, v := range forward {
+		reverse[len(forward)-1-i] = v
+	}
+	lexical := ByPrecedenceThenMetadata(MetadataLexical)
+	numeric := ByPrecedenceThenMetadata(MetadataNumeric)
+
+	// The result must not depend on the order of the list.
+	for _, l := range []List{forward, reverse} {
+		t.Run(fmt.Sprint(l), func(t *testing.T) {
+			if got, want := l.NewestWith(lexical), MustParseVersion("1.2.3+build.9"); got != want {
+				t.Errorf("wrong lexical result\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := l.NewestWith(numeric), MustParseVersion("1.2.3+build.10"); got != want {
+				t.Errorf("wrong numeric result\ngot:  %s\nwant: %s", got, want)
+			}
+			set := OlderThan(MustParseVersion("1.2.3"))
+			if got, want := l.NewestInSetWith(set, numeric), MustParseVersion("1.2.3-rc.1+build.11"); got != want {
+				t.Errorf("wrong NewestInSetWith result\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := l.NewestInSetWith(None, numeric), Unspecified; got != want {
+				t.Errorf("wrong NewestInSetWith result for None\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	// Versions that the comparator considers equal are all returned by
+	// NewestListWith, in their original order.
+	platform := ByPrecedenceThenMetadata(func(a, b VersionExtra) int {
+		return strings.Compare(strings.SplitN(string(a), ".", 2)[0], strings.SplitN(string(b), ".", 2)[0])
+	})
+	l := List{
+		MustParseVersion("1.0.0+linux.arm64"),
+		MustParseVersion("1.0.0+darwin.arm64"),
+		MustParseVersion("1.0.0+linux.amd64"),
+	}
+	if got, want := fmt.Sprint(l.NewestListWith(platform)), "[1.0.0+linux.arm64 1.0.0+linux.amd64]"; got != want {
+		t.Errorf("wrong NewestListWith result\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := l.NewestWith(platform), MustParseVersion("1.0.0+linux.amd64"); got != want {
+		t.Errorf("wrong NewestWith result for equal versions\ngot:  %s\nwant: %s", got, want)
+	}
+	if got := List(nil).NewestListWith(platform); got != nil {
+		t.Errorf("wrong NewestListWith result for empty list: %s", got)
+	}
+
+	// Version.LessThan continues to ignore metadata.
+	if MustParseVersion("1.2.3+build.9").LessThan(MustParseVersion("1.2.3+build.10")) {
+		t.Errorf("LessThan considered metadata")
+	}
+}
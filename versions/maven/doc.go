@@ -0,0 +1,14 @@
+// Package maven is a companion to package versions that deals with the
+// version numbers and version ranges used by Maven and Gradle for JVM
+// artifacts.
+//
+// Maven versions are free-form strings such as "1.0-SNAPSHOT", "2.3.1.Final"
+// or "1.0-alpha-1", which are ordered using the rules of Maven's
+// ComparableVersion class rather than those of semantic versioning. This
+// package therefore has its own Version type, whose Scheme lets Maven versions
+// be used with versions.Set and versions.List, and with the functions of
+// package versions that create sets, such as versions.AtLeast.
+//
+// Version ranges use Maven's bracket syntax, such as "[1.0,2.0)" or
+// "(,1.5],[2.0,)". See ParseRange for details.
+package maven
@@ -0,0 +1,261 @@
+package maven
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// Range is a parsed Maven version range, as used in the version of a
+// dependency in a Maven POM file.
+type Range struct {
+	// Recommended is the version given by a range that is just a version
+	// number, such as "1.0", which Maven treats as a "soft" requirement
+	// that any version can satisfy. It is Unspecified for ranges written
+	// with brackets.
+	Recommended Version
+
+	// Restrictions are the intervals of versions that the range allows, in
+	// ascending order. A version is allowed if it is in any one of them.
+	// A range with a recommended version has no restrictions.
+	Restrictions []Restriction
+}
+
+// Restriction is a single interval of versions within a Range, such as
+// "[1.0,2.0)".
+type Restriction struct {
+	// Lower is the lower bound of the interval, or Unspecified if there
+	// is none.
+	Lower          Version
+	LowerInclusive bool
+
+	// Upper is the upper bound of the interval, or Unspecified if there
+	// is none.
+	Upper          Version
+	UpperInclusive bool
+}
+
+// ParseRange parses a Maven version range.
+//
+// A range is either a single version, which is a soft requirement that
+// Maven will replace with another version if needed to resolve conflicts,
+// or a comma-separated sequence of intervals given in brackets:
+//
+//	[1.0]         exactly 1.0
+//	[1.0,2.0)     1.0 <= x < 2.0
+//	[1.0,2.0]     1.0 <= x <= 2.0
+//	(,1.0]        x <= 1.0
+//	[1.5,)        x >= 1.5
+//	(,1.0],[1.2,) x <= 1.0 or x >= 1.2
+//
+// Intervals must be given in ascending order and must not overlap.
+//
+// If the given string is not a valid range then an error is returned that is
+// suitable for display directly to a hypothetical end-user that provided
+// this string, as long as they can read English.
+func ParseRange(s string) (Range, error) {
+	var ret Range
+	remain := strings.TrimSpace(s)
+	if remain == "" {
+		return ret, fmt.Errorf("empty version range")
+	}
+
+	var upper Version
+	for strings.HasPrefix(remain, "[") || strings.HasPrefix(remain, "(") {
+		end := strings.IndexAny(remain, ")]")
+		if end < 0 {
+			return ret, fmt.Errorf("unbounded range %q; missing closing bracket", s)
+		}
+		r, err := parseRestriction(remain[:end+1])
+		if err != nil {
+			return ret, err
+		}
+		if len(ret.Restrictions) > 0 && (r.Lower.raw == "" || upper.raw == "" || r.Lower.LessThan(upper)) {
+			return ret, fmt.Errorf("invalid range %q; intervals overlap or are not in ascending order", s)
+		}
+		ret.Restrictions = append(ret.Restrictions, r)
+		upper = r.Upper
+
+		remain = strings.TrimSpace(remain[end+1:])
+		if strings.HasPrefix(remain, ",") {
+			remain = strings.TrimSpace(remain[1:])
+		}
+	}
+
+	if remain != "" {
+		if len(ret.Restrictions) > 0 {
+			return ret, fmt.Errorf("invalid range %q; only bracketed intervals are allowed when there is more than one", s)
+		}
+		if strings.ContainsAny(remain, "[](),") {
+			return ret, fmt.Errorf("invalid range %q; intervals must start with \"[\" or \"(\"", s)
+		}
+		ret.Recommended = MustParse(remain)
+	}
+
+	return ret, nil
+}
+
+// MustParseRange is the same as ParseRange except that it will panic instead
+// of returning an error.
+func MustParseRange(s string) Range {
+	r, err := ParseRange(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func parseRestriction(spec string) (Restriction, error) {
+	var ret Restriction
+	ret.LowerInclusive = strings.HasPrefix(spec, "[")
+	ret.UpperInclusive = strings.HasSuffix(spec, "]")
+	inner := strings.TrimSpace(spec[1 : len(spec)-1])
+
+	comma := strings.IndexByte(inner, ',')
+	if comma < 0 {
+		if !ret.LowerInclusive || !ret.UpperInclusive {
+			return ret, fmt.Errorf("invalid interval %q; a single version must be surrounded by []", spec)
+		}
+		if inner == "" {
+			return ret, fmt.Errorf("invalid interval %q; must contain a version", spec)
+		}
+		v := MustParse(inner)
+		ret.Lower, ret.Upper = v, v
+		return ret, nil
+	}
+
+	lowerStr := strings.TrimSpace(inner[:comma])
+	upperStr := strings.TrimSpace(inner[comma+1:])
+	if strings.ContainsAny(upperStr, ",[(") {
+		return ret, fmt.Errorf("invalid interval %q; must have at most two versions", spec)
+	}
+	if lowerStr != "" {
+		ret.Lower = MustParse(lowerStr)
+	}
+	if upperStr != "" {
+		ret.Upper = MustParse(upperStr)
+	}
+	if lowerStr != "" && upperStr != "" {
+		switch c := ret.Upper.Compare(ret.Lower); {
+		case c < 0:
+			return ret, fmt.Errorf("invalid interval %q; the upper bound is lower than the lower bound", spec)
+		case c == 0 && !(ret.LowerInclusive && ret.UpperInclusive):
+			return ret, fmt.Errorf("invalid interval %q; an interval with the same version at both ends must be written as [%s]", spec, lowerStr)
+		}
+	}
+	return ret, nil
+}
+
+// Has returns true if the given version is within the receiving interval.
+func (r Restriction) Has(v Version) bool {
+	if r.Lower.raw != "" {
+		c := r.Lower.Compare(v)
+		if c > 0 || (c == 0 && !r.LowerInclusive) {
+			return false
+		}
+	}
+	if r.Upper.raw != "" {
+		c := r.Upper.Compare(v)
+		if c < 0 || (c == 0 && !r.UpperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the receiver in the syntax accepted by ParseRange.
+func (r Restriction) String() string {
+	var buf bytes.Buffer
+	if r.Lower.raw != "" && r.Upper.raw != "" && r.Lower.raw == r.Upper.raw && r.LowerInclusive && r.UpperInclusive {
+		fmt.Fprintf(&buf, "[%s]", r.Lower)
+		return buf.String()
+	}
+	if r.LowerInclusive {
+		buf.WriteByte('[')
+	} else {
+		buf.WriteByte('(')
+	}
+	fmt.Fprintf(&buf, "%s,%s", r.Lower, r.Upper)
+	if r.UpperInclusive {
+		buf.WriteByte(']')
+	} else {
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// String returns the receiver in the syntax accepted by ParseRange.
+func (r Range) String() string {
+	if r.Recommended.raw != "" {
+		return r.Recommended.String()
+	}
+	parts := make([]string, len(r.Restrictions))
+	for i, restriction := range r.Restrictions {
+		parts[i] = restriction.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set returns a set containing the versions within the receiving interval,
+// as defined by Has.
+func (r Restriction) Set() versions.Set {
+	var sets []versions.Set
+	switch {
+	case r.Lower.raw == "":
+		// no lower bound
+	case r.LowerInclusive:
+		sets = append(sets, versions.AtLeast(r.Lower))
+	default:
+		sets = append(sets, versions.NewerThan(r.Lower))
+	}
+	switch {
+	case r.Upper.raw == "":
+		// no upper bound
+	case r.UpperInclusive:
+		sets = append(sets, versions.AtMost(r.Upper))
+	default:
+		sets = append(sets, versions.OlderThan(r.Upper))
+	}
+	return versions.Intersection(sets...)
+}
+
+// MeetingRange returns a version set that contains all of the versions
+// allowed by the given range.
+//
+// A range that is just a version number, such as "1.0", is only a
+// recommendation in Maven and so the result in that case is versions.All.
+// Use versions.Only with the range's Recommended version to select just that
+// version instead.
+func MeetingRange(r Range) versions.Set {
+	if len(r.Restrictions) == 0 {
+		return versions.All
+	}
+	sets := make([]versions.Set, len(r.Restrictions))
+	for i, restriction := range r.Restrictions {
+		sets[i] = restriction.Set()
+	}
+	return versions.Union(sets...)
+}
+
+// MeetingRangeString attempts to parse the given range string and then
+// passes the result to MeetingRange, returning the resulting set.
+func MeetingRangeString(s string) (versions.Set, error) {
+	r, err := ParseRange(s)
+	if err != nil {
+		return versions.None, err
+	}
+	return MeetingRange(r), nil
+}
+
+// MustMakeSet can be used to wrap any function that returns a set and an error
+// to make it panic if an error occurs and return the set otherwise.
+//
+// This is intended for tests and initializing global variables.
+func MustMakeSet(set versions.Set, err error) versions.Set {
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
@@ -0,0 +1,138 @@
+package maven
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr bool
+	}{
+		{"1.0", "1.0", false},
+		{"[1.0]", "[1.0]", false},
+		{"[1.0,2.0)", "[1.0,2.0)", false},
+		{"[ 1.0 , 2.0 ]", "[1.0,2.0]", false},
+		{"(,1.0]", "(,1.0]", false},
+		{"[1.5,)", "[1.5,)", false},
+		{"(,1.0],[1.2,)", "(,1.0],[1.2,)", false},
+		{"(,1.1),(1.1,)", "(,1.1),(1.1,)", false},
+		{"[1.0,1.0]", "[1.0]", false},
+
+		{"", "", true},
+		{"[1.0", "", true},
+		{"(1.0)", "", true},
+		{"[1.0)", "", true},
+		{"[]", "", true},
+		{"[2.0,1.0]", "", true},
+		{"(1.0,1.0]", "", true},
+		{"[1.0,2.0,3.0]", "", true},
+		{"[1.0,2.0],[1.5,3.0]", "", true},
+		{"[1.5,3.0],[1.0,2.0]", "", true},
+		{"[1.0,),[2.0,)", "", true},
+		{"[1.0,2.0],3.0", "", true},
+		{"1.0]", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			r, err := ParseRange(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %s", r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := r.String(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMeetingRange(t *testing.T) {
+	tests := []struct {
+		Range   string
+		Version string
+		Want    bool
+	}{
+		{"1.0", "0.5", true},
+		{"1.0", "3.0", true},
+		{"[1.0]", "1.0", true},
+		{"[1.0]", "1.0.0", true},
+		{"[1.0]", "1.0.1", false},
+		{"[1.0,2.0)", "1.0", true},
+		{"[1.0,2.0)", "1.5-SNAPSHOT", true},
+		{"[1.0,2.0)", "2.0-SNAPSHOT", true},
+		{"[1.0,2.0)", "2.0", false},
+		{"(1.0,2.0]", "1.0", false},
+		{"(1.0,2.0]", "2.0", true},
+		{"(,1.0]", "0.1", true},
+		{"(,1.0]", "1.0.1", false},
+		{"[1.5,)", "1.5", true},
+		{"[1.5,)", "1.4", false},
+		{"(,1.0],[1.2,)", "1.1", false},
+		{"(,1.0],[1.2,)", "1.2", true},
+		{"(,1.1),(1.1,)", "1.1", false},
+		{"(,1.1),(1.1,)", "1.1.1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s has %s", test.Range, test.Version), func(t *testing.T) {
+			set := MustMakeSet(MeetingRangeString(test.Range))
+			if got, want := set.Has(MustParse(test.Version)), test.Want; got != want {
+				t.Errorf("wrong result\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
+
+func TestListNewestInSet(t *testing.T) {
+	l := versions.List{
+		MustParse("1.0"),
+		MustParse("1.1-SNAPSHOT"),
+		MustParse("1.1"),
+		MustParse("2.3.1.Final"),
+	}
+	set := MustMakeSet(MeetingRangeString("[1.0,2.0)")).Subtract(versions.Only(MustParse("1.1")))
+
+	if got, want := l.NewestInSet(set).String(), "1.1-SNAPSHOT"; got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := l.Newest().String(), "2.3.1.Final"; got != want {
+		t.Errorf("wrong newest version\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestMeetingRangeCompare(t *testing.T) {
+	tests := []struct {
+		A, B     string
+		Subset   bool // A is a subset of B
+		Overlaps bool
+	}{
+		{"[1.0,2.0)", "[1.0,)", true, true},
+		{"[1.0,)", "[1.0,2.0)", false, true},
+		{"[1.0.5,1.1]", "(,1.0],[1.2,)", false, false},
+		{"[1.0]", "[1.0.0]", true, true},
+		{"(,1.0)", "[1.0,)", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s and %s", test.A, test.B), func(t *testing.T) {
+			a, b := MustMakeSet(MeetingRangeString(test.A)), MustMakeSet(MeetingRangeString(test.B))
+			if got, want := a.IsSubsetOf(b), test.Subset; got != want {
+				t.Errorf("wrong IsSubsetOf result\ngot:  %t\nwant: %t", got, want)
+			}
+			if got, want := a.Overlaps(b), test.Overlaps; got != want {
+				t.Errorf("wrong Overlaps result\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package maven
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Version represents a single Maven version.
+//
+// Maven accepts any non-empty string as a version, so a Version retains the
+// string it was parsed from along with the sequence of items that Maven's
+// ComparableVersion class would derive from it for comparison.
+type Version struct {
+	raw   string
+	items listItem
+}
+
+// Unspecified is the zero value of Version and represents the absense of a
+// version number.
+var Unspecified Version
+
+// Parse parses the given string as a Maven version.
+//
+// Any non-empty string is a valid Maven version, so the only error this
+// function can return is for an empty string or one containing only
+// whitespace. Surrounding whitespace is ignored.
+func Parse(s string) (Version, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Unspecified, fmt.Errorf("empty version string")
+	}
+	return Version{
+		raw:   s,
+		items: parseItems(s),
+	}, nil
+}
+
+// MustParse is the same as Parse except that it will panic instead of
+// returning an error.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the string the receiver was parsed from.
+func (v Version) String() string {
+	return v.raw
+}
+
+func (v Version) GoString() string {
+	return fmt.Sprintf("maven.MustParse(%q)", v.raw)
+}
+
+// Canonical returns the canonical form of the receiver, as produced by
+// Maven's ComparableVersion.getCanonical. Two versions have the same
+// precedence if and only if they have the same canonical form.
+//
+// For example, the canonical form of "1.0.0-GA" is "1", and the canonical
+// form of "1.0-a1" is "1-alpha-1".
+func (v Version) Canonical() string {
+	return v.items.String()
+}
+
+// IsSnapshot returns true if the receiver is a snapshot version, which is
+// one whose string ends with "-SNAPSHOT" in any letter case.
+func (v Version) IsSnapshot() bool {
+	return strings.HasSuffix(strings.ToUpper(v.raw), "-SNAPSHOT")
+}
+
+// IsPrerelease returns true if the receiver has any of the qualifiers that
+// Maven orders before a release, which are "alpha", "beta", "milestone", "rc"
+// and "snapshot" along with their aliases, as in "1.0-alpha-1", "1.0-cr2" or
+// "1.0-SNAPSHOT".
+func (v Version) IsPrerelease() bool {
+	return v.items.hasPrereleaseQualifier()
+}
+
+// Compare returns -1, 0 or 1 depending on whether the receiver has lower,
+// the same or higher precedence than the other given version, using the
+// ordering of Maven's ComparableVersion class.
+//
+// Unspecified has lower precedence than all other versions.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.raw == "" && other.raw == "":
+		return 0
+	case v.raw == "":
+		return -1
+	case other.raw == "":
+		return 1
+	}
+	return v.items.compare(other.items)
+}
+
+// LessThan returns true if the receiver has a lower precedence than the
+// other given version.
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// GreaterThan returns true if the receiver has a higher precedence than the
+// other given version.
+func (v Version) GreaterThan(other Version) bool {
+	return v.Compare(other) > 0
+}
+
+// Same returns true if the receiver has the same precedence as the other
+// given version. Versions with different strings can have the same
+// precedence, such as "1.0" and "1.0.0" or "1.0-ga" and "1".
+func (v Version) Same(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// MarshalText is an implementation of encoding.TextMarshaler, allowing versions
+// to be automatically marshalled for text-based serialization formats,
+// including encoding/json.
+//
+// The format used is that returned by String, which can be parsed using
+// Parse.
+func (v Version) MarshalText() (text []byte, err error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText is an implementation of encoding.TextUnmarshaler, allowing
+// versions to be automatically unmarshalled from strings in text-based
+// serialization formats, including encoding/json.
+//
+// The format expected is what is accepted by Parse. Any parser errors
+// are passed on verbatim to the caller.
+func (v *Version) UnmarshalText(text []byte) error {
+	new, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = new
+	return nil
+}
+
+// item is one element of a parsed version, which is an intItem, a stringItem
+// or a nested listItem.
+type item interface {
+	// compare compares the receiver with the given item, which is nil if the
+	// other version has no corresponding item.
+	compare(other item) int
+	isNull() bool
+	String() string
+}
+
+// intItem is a numeric item, stored as a decimal string without leading zeros
+// so that numbers of any size can be compared.
+type intItem string
+
+func (i intItem) compare(other item) int {
+	switch other := other.(type) {
+	case nil:
+		if i == "0" {
+			return 0
+		}
+		return 1
+	case intItem:
+		if len(i) != len(other) {
+			if len(i) < len(other) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(string(i), string(other))
+	default:
+		// A number is newer than any qualifier or sub-list, so 1.1 > 1-sp
+		// and 1.1 > 1-1.
+		return 1
+	}
+}
+
+func (i intItem) isNull() bool {
+	return i == "0"
+}
+
+func (i intItem) String() string {
+	return string(i)
+}
+
+// stringItem is a qualifier item, whose value has already had Maven's
+// aliases applied.
+type stringItem string
+
+// qualifiers are the well-known qualifiers, in ascending order. Any other
+// qualifier sorts after all of these, in lexical order.
+var qualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var qualifierAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+// releaseQualifier is the comparable form of the empty qualifier, which is
+// what a missing item is compared as.
+var releaseQualifier = comparableQualifier("")
+
+func newStringItem(s string, followedByDigit bool) stringItem {
+	if followedByDigit && len(s) == 1 {
+		// a1 = alpha-1, b1 = beta-1, m1 = milestone-1
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := qualifierAliases[s]; ok {
+		s = alias
+	}
+	return stringItem(s)
+}
+
+func comparableQualifier(s string) string {
+	for i, q := range qualifiers {
+		if q == s {
+			return fmt.Sprint(i)
+		}
+	}
+	return fmt.Sprintf("%d-%s", len(qualifiers), s)
+}
+
+func (s stringItem) compare(other item) int {
+	switch other := other.(type) {
+	case nil:
+		// 1-rc < 1, 1-ga == 1, 1-sp > 1
+		return strings.Compare(comparableQualifier(string(s)), releaseQualifier)
+	case stringItem:
+		return strings.Compare(comparableQualifier(string(s)), comparableQualifier(string(other)))
+	default:
+		// 1.any < 1.1 and 1-any < 1-1
+		return -1
+	}
+}
+
+func (s stringItem) isNull() bool {
+	return s == ""
+}
+
+func (s stringItem) String() string {
+	return string(s)
+}
+
+// listItem is a sequence of items. Each hyphen in a version, and each
+// transition between digits and letters, starts a new nested list.
+type listItem []item
+
+func (l listItem) compare(other item) int {
+	switch other := other.(type) {
+	case nil:
+		if len(l) == 0 {
+			return 0
+		}
+		return l[0].compare(nil)
+	case intItem:
+		// 1-1 < 1.0.x
+		return -1
+	case stringItem:
+		// 1-1 > 1-sp
+		return 1
+	case listItem:
+		for i := 0; i < len(l) || i < len(other); i++ {
+			var li, ri item
+			if i < len(l) {
+				li = l[i]
+			}
+			if i < len(other) {
+				ri = other[i]
+			}
+			var c int
+			switch {
+			case li == nil && ri == nil:
+				c = 0
+			case li == nil:
+				c = -ri.compare(nil)
+			default:
+				c = li.compare(ri)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	default:
+		panic(fmt.Sprintf("unsupported item type %T", other))
+	}
+}
+
+// hasPrereleaseQualifier returns true if the receiver, or any list nested
+// inside it, contains a qualifier that sorts before a release.
+func (l listItem) hasPrereleaseQualifier() bool {
+	for _, it := range l {
+		switch it := it.(type) {
+		case stringItem:
+			if comparableQualifier(string(it)) < releaseQualifier {
+				return true
+			}
+		case listItem:
+			if it.hasPrereleaseQualifier() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l listItem) isNull() bool {
+	return len(l) == 0
+}
+
+func (l listItem) String() string {
+	var buf bytes.Buffer
+	for _, it := range l {
+		if buf.Len() > 0 {
+			if _, ok := it.(listItem); ok {
+				buf.WriteByte('-')
+			} else {
+				buf.WriteByte('.')
+			}
+		}
+		buf.WriteString(it.String())
+	}
+	return buf.String()
+}
+
+// normalize removes null items from the end of the list, stopping at the
+// last item that is not null or at the first nested list.
+func (l listItem) normalize() listItem {
+	for i := len(l) - 1; i >= 0; i-- {
+		if l[i].isNull() {
+			l = append(l[:i], l[i+1:]...)
+		} else if _, ok := l[i].(listItem); !ok {
+			break
+		}
+	}
+	return l
+}
+
+// parseItems splits a version string into items in the same way as Maven's
+// ComparableVersion.parseVersion.
+func parseItems(s string) listItem {
+	s = strings.ToLower(s)
+
+	// Nested lists are built from the innermost outwards once parsing is
+	// complete, because they must each be normalized before they are
+	// added to their parent.
+	stack := []listItem{nil}
+	push := func(it item) {
+		stack[len(stack)-1] = append(stack[len(stack)-1], it)
+	}
+	startList := func() {
+		stack = append(stack, nil)
+	}
+	parseItem := func(isDigit bool, buf string) item {
+		if isDigit {
+			buf = strings.TrimLeft(buf, "0")
+			if buf == "" {
+				buf = "0"
+			}
+			return intItem(buf)
+		}
+		return newStringItem(buf, false)
+	}
+
+	isDigit := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' || c == '-':
+			if i == start {
+				push(intItem("0"))
+			} else {
+				push(parseItem(isDigit, s[start:i]))
+			}
+			start = i + 1
+			if c == '-' {
+				startList()
+			}
+		case c >= '0' && c <= '9':
+			if !isDigit && i > start {
+				push(newStringItem(s[start:i], true))
+				start = i
+				startList()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				push(parseItem(true, s[start:i]))
+				start = i
+				startList()
+			}
+			isDigit = false
+		}
+	}
+	if len(s) > start {
+		push(parseItem(isDigit, s[start:]))
+	}
+
+	for len(stack) > 1 {
+		last := stack[len(stack)-1].normalize()
+		stack = stack[:len(stack)-1]
+		push(last)
+	}
+	return stack[0].normalize()
+}
@@ -0,0 +1,135 @@
+package maven
+
+import (
+	"testing"
+)
+
+func TestVersionCompare(t *testing.T) {
+	// These sequences are from the tests for Maven's ComparableVersion
+	// class. Each version has lower precedence than the one after it.
+	sequences := [][]string{
+		{
+			"1-alpha2snapshot", "1-alpha2", "1-alpha-123", "1-beta-2", "1-beta123",
+			"1-m2", "1-m11", "1-rc", "1-cr2", "1-rc123", "1-SNAPSHOT", "1", "1-sp",
+			"1-sp2", "1-sp123", "1-abc", "1-def", "1-pom-1", "1-1-snapshot",
+			"1-1", "1-2", "1-123",
+		},
+		{
+			"2.0", "2-1", "2.0.a", "2.0.0.a", "2.0.2", "2.0.123", "2.1.0", "2.1-a",
+			"2.1b", "2.1-c", "2.1-1", "2.1.0.1", "2.2", "2.123", "11.a2", "11.a11",
+			"11.b2", "11.b11", "11.m2", "11.m11", "11", "11.a", "11b", "11c", "11m",
+		},
+		{
+			"1.0-alpha-1", "1.0-beta-1", "1.0-SNAPSHOT", "1.0", "2.3.1.Final",
+			"2.3.1.SP1", "2.3.2",
+		},
+	}
+
+	for _, seq := range sequences {
+		for i := range seq {
+			for j := range seq {
+				a, b := MustParse(seq[i]), MustParse(seq[j])
+				want := 0
+				switch {
+				case i < j:
+					want = -1
+				case i > j:
+					want = 1
+				}
+				if got := a.Compare(b); got != want {
+					t.Errorf("wrong result for %s compared with %s\ngot:  %d\nwant: %d", a, b, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestVersionSame(t *testing.T) {
+	tests := []struct {
+		A, B string
+	}{
+		{"1", "1.0"},
+		{"1", "1.0.0"},
+		{"1.0", "1.0.0"},
+		{"1", "1-0"},
+		{"1", "1.0-0"},
+		{"1.0", "1.0-0"},
+		{"1a", "1-a"},
+		{"1a", "1.0-a"},
+		{"1a", "1.0.0-a"},
+		{"1.0a", "1-a"},
+		{"1.0.0a", "1-a"},
+		{"1x", "1-x"},
+		{"1x", "1.0-x"},
+		{"1ga", "1"},
+		{"1release", "1"},
+		{"1final", "1"},
+		{"1cr", "1rc"},
+		{"1a1", "1-alpha-1"},
+		{"1b2", "1-beta-2"},
+		{"1m3", "1-milestone-3"},
+		{"1X", "1x"},
+		{"1A", "1a"},
+		{"1RC", "1rc"},
+		{"1CR", "1cr"},
+		{"2.3.1.Final", "2.3.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A+" "+test.B, func(t *testing.T) {
+			a, b := MustParse(test.A), MustParse(test.B)
+			if !a.Same(b) {
+				t.Errorf("%s should be the same as %s", a, b)
+			}
+			if got, want := a.Canonical(), b.Canonical(); got != want {
+				t.Errorf("wrong canonical form\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestVersionCanonical(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{"1.0.0-GA", "1"},
+		{"1.0-a1", "1-alpha-1"},
+		{"1.0-SNAPSHOT", "1-snapshot"},
+		{"2.3.1.Final", "2.3.1"},
+		{"1.0.0.RC1", "1.0.0.rc-1"},
+		{"1-alpha-1", "1-alpha-1"},
+		{"007", "7"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			if got, want := MustParse(test.Input).Canonical(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestVersionIsPrerelease(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  bool
+	}{
+		{"1.0", false},
+		{"1.0-SNAPSHOT", true},
+		{"1.0-alpha-1", true},
+		{"1.0.0.RC1", true},
+		{"2.3.1.Final", false},
+		{"1.0-sp1", false},
+		{"1.0-foo", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			if got, want := MustParse(test.Input).IsPrerelease(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+}
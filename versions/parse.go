@@ -20,6 +20,21 @@ func ParseVersion(s string) (Version, error) {
 	return versionFromExactVersionSpec(spec), nil
 }
 
+// ParseVersionStrict is like ParseVersion except that it accepts only strings
+// that conform exactly to the grammar in the Semantic Versioning 2.0.0
+// specification, as described for constraints.ParseExactVersionStrict.
+//
+// Use this when a version must be acceptable to other implementations of
+// semver too, such as before publishing it. To check a Version value that was
+// constructed directly, pass the result of its String method.
+func ParseVersionStrict(s string) (Version, error) {
+	spec, err := constraints.ParseExactVersionStrict(s)
+	if err != nil {
+		return Unspecified, err
+	}
+	return versionFromExactVersionSpec(spec), nil
+}
+
 // MustParseVersion is the same as ParseVersion except that it will panic
 // instead of returning an error.
 func MustParseVersion(s string) Version {
@@ -44,9 +59,39 @@ func MustParseVersion(s string) Version {
 // This function expects an internally-consistent Spec like what would be
 // generated by that package's constraint parsers. Behavior is undefined --
 // including the possibility of panics -- if specs are hand-created and the
-// expected invariants aren't met.
+// expected invariants aren't met. Use MeetingConstraintsChecked instead for
+// specs that might not meet those invariants.
 func MeetingConstraints(spec constraints.Spec) Set {
-	exact := MeetingConstraintsExact(spec)
+	return meetingConstraints(spec, nil)
+}
+
+// MeetingConstraintsWithSources is like MeetingConstraints except that the
+// resulting set also retains the given sources of the selections in the
+// spec, as returned by the parsers in package constraints whose names end in
+// "WithSources", so that they are available from Set.Sources and can be
+// mentioned by Set.Explain.
+func MeetingConstraintsWithSources(spec constraints.Spec, sources constraints.Sources) Set {
+	return meetingConstraints(spec, sources)
+}
+
+// MeetingConstraintsChecked is like MeetingConstraints except that it first
+// checks the given spec using constraints.Validate, returning the error from
+// that function if the spec doesn't meet the invariants that
+// MeetingConstraints relies on.
+//
+// This is intended for specs that were constructed directly rather than
+// parsed, such as from structured configuration. Specs produced by the
+// parsers in package constraints are always valid, and so can be passed to
+// MeetingConstraints directly.
+func MeetingConstraintsChecked(spec constraints.Spec) (Set, error) {
+	if err := constraints.Validate(spec); err != nil {
+		return None, err
+	}
+	return MeetingConstraints(spec), nil
+}
+
+func meetingConstraints(spec constraints.Spec, sources constraints.Sources) Set {
+	exact := meetingConstraintsExact(spec, sources)
 	reqd := exact.AllRequested().List()
 	set := Intersection(Released, exact)
 	reqd = reqd.Filter(Prerelease).Filter(exact)
@@ -69,6 +114,19 @@ func MeetingConstraints(spec constraints.Spec) Set {
 // such as intersecting it with the predefined set versions.Released to
 // remove prerelease versions altogether.
 func MeetingConstraintsExact(spec constraints.Spec) Set {
+	return meetingConstraintsExact(spec, nil)
+}
+
+// MeetingConstraintsExactChecked is like MeetingConstraintsExact except that
+// it first checks the given spec as described for MeetingConstraintsChecked.
+func MeetingConstraintsExactChecked(spec constraints.Spec) (Set, error) {
+	if err := constraints.Validate(spec); err != nil {
+		return None, err
+	}
+	return MeetingConstraintsExact(spec), nil
+}
+
+func meetingConstraintsExact(spec constraints.Spec, sources constraints.Sources) Set {
 	if spec == nil {
 		return All
 	}
@@ -90,6 +148,13 @@ func MeetingConstraintsExact(spec constraints.Spec) Set {
 		}
 
 	case constraints.SelectionSpec:
+		if src := sources.Get(0, 0); !src.IsZero() {
+			// The set for a selection with a known source retains that
+			// selection, so that messages can refer back to it.
+			set := meetingConstraintsExact(ts, nil)
+			return withSelectionSource(set, ts, src)
+		}
+
 		lower := ts.Boundary.ConstrainToZero()
 		if ts.Operator != constraints.OpEqual && ts.Operator != constraints.OpNotEqual {
 			lower.Metadata = "" // metadata is only considered for exact matches
@@ -120,25 +185,17 @@ func MeetingConstraintsExact(spec constraints.Spec) Set {
 			return OlderThan(versionFromExactVersionSpec(lower))
 		case constraints.OpLessThanOrEqual:
 			return AtMost(versionFromExactVersionSpec(lower))
-		case constraints.OpGreaterThanOrEqualMinorOnly:
-			upper := lower
-			upper.Major.Num++
-			upper.Minor.Num = 0
-			upper.Patch.Num = 0
-			upper.Prerelease = ""
-			return AtLeast(
-				versionFromExactVersionSpec(lower),
-			).Intersection(
-				OlderThan(versionFromExactVersionSpec(upper)))
-		case constraints.OpGreaterThanOrEqualPatchOnly:
-			upper := lower
-			upper.Minor.Num++
-			upper.Patch.Num = 0
-			upper.Prerelease = ""
-			return AtLeast(
-				versionFromExactVersionSpec(lower),
-			).Intersection(
-				OlderThan(versionFromExactVersionSpec(upper)))
+		case constraints.OpGreaterThanOrEqualMinorOnly,
+			constraints.OpGreaterThanOrEqualPatchOnly,
+			constraints.OpGreaterThanOrEqualPrereleaseOnly:
+			lowerV := versionFromExactVersionSpec(lower)
+			upperV, err := Semver.UpperBound(ts.Operator, lowerV)
+			if err != nil {
+				// Should never happen because Semver supports all of the
+				// operators in this case.
+				panic(err)
+			}
+			return AtLeast(lowerV).Intersection(OlderThan(upperV.(Version)))
 		default:
 			panic(fmt.Errorf("unsupported constraints.SelectionOp %s", ts.Operator))
 		}
@@ -148,11 +205,11 @@ func MeetingConstraintsExact(spec constraints.Spec) Set {
 			return All
 		}
 		if len(ts) == 1 {
-			return MeetingConstraintsExact(ts[0])
+			return meetingConstraintsExact(ts[0], sources)
 		}
 		union := make(setUnion, len(ts))
 		for i, subSpec := range ts {
-			union[i] = MeetingConstraintsExact(subSpec).setI
+			union[i] = meetingConstraintsExact(subSpec, alternativeSources(sources, i)).setI
 		}
 		return Set{setI: union}
 
@@ -161,11 +218,11 @@ func MeetingConstraintsExact(spec constraints.Spec) Set {
 			return All
 		}
 		if len(ts) == 1 {
-			return MeetingConstraintsExact(ts[0])
+			return meetingConstraintsExact(ts[0], sources)
 		}
 		intersection := make(setIntersection, len(ts))
 		for i, subSpec := range ts {
-			intersection[i] = MeetingConstraintsExact(subSpec).setI
+			intersection[i] = meetingConstraintsExact(subSpec, constraints.Sources{{sources.Get(0, i)}}).setI
 		}
 		return Set{setI: intersection}
 
@@ -176,6 +233,141 @@ func MeetingConstraintsExact(spec constraints.Spec) Set {
 	}
 }
 
+// MeetingConstraintsWithScheme is like MeetingConstraints except that it
+// returns a set of versions of the given scheme, using the scheme to compare
+// versions, to decide which versions are pre-releases, and to decide the
+// upper bounds of the "~" and "^" operators and their equivalents.
+//
+// The boundary versions in the spec are converted by passing their string
+// representation to the ParseVersion method of the scheme. An error is
+// returned if the scheme can't parse a boundary version, or if it doesn't
+// support an operator that the spec uses.
+func MeetingConstraintsWithScheme(spec constraints.Spec, scheme Scheme) (Set, error) {
+	exact, err := MeetingConstraintsExactWithScheme(spec, scheme)
+	if err != nil {
+		return None, err
+	}
+	return exact.WithoutUnrequestedPrereleases(), nil
+}
+
+// MeetingConstraintsExactWithScheme is like MeetingConstraintsWithScheme
+// except that it doesn't apply the extra rules to exclude pre-release
+// versions that are not explicitly requested, as for MeetingConstraintsExact.
+func MeetingConstraintsExactWithScheme(spec constraints.Spec, scheme Scheme) (Set, error) {
+	if spec == nil {
+		return All, nil
+	}
+
+	switch ts := spec.(type) {
+
+	case constraints.VersionSpec:
+		lowerBound, upperBound := ts.ConstraintBounds()
+		switch lowerBound.Operator {
+		case constraints.OpUnconstrained:
+			return All, nil
+		case constraints.OpEqual:
+			v, err := schemeVersionFromSpec(lowerBound.Boundary, scheme)
+			if err != nil {
+				return None, err
+			}
+			return Only(v), nil
+		default:
+			lowerV, err := schemeVersionFromSpec(lowerBound.Boundary, scheme)
+			if err != nil {
+				return None, err
+			}
+			upperV, err := schemeVersionFromSpec(upperBound.Boundary, scheme)
+			if err != nil {
+				return None, err
+			}
+			return AtLeast(lowerV).Intersection(OlderThan(upperV)), nil
+		}
+
+	case constraints.SelectionSpec:
+		lower := ts.Boundary.ConstrainToZero()
+		if ts.Operator != constraints.OpEqual && ts.Operator != constraints.OpNotEqual {
+			lower.Metadata = "" // metadata is only considered for exact matches
+		}
+
+		switch ts.Operator {
+		case constraints.OpUnconstrained:
+			return All, nil
+		case constraints.OpMatch:
+			return MeetingConstraintsExactWithScheme(ts.Boundary, scheme)
+		}
+
+		lowerV, err := schemeVersionFromSpec(lower, scheme)
+		if err != nil {
+			return None, err
+		}
+		switch ts.Operator {
+		case constraints.OpEqual:
+			return Only(lowerV), nil
+		case constraints.OpNotEqual:
+			return All.Subtract(Only(lowerV)), nil
+		case constraints.OpGreaterThan:
+			return NewerThan(lowerV), nil
+		case constraints.OpGreaterThanOrEqual:
+			return AtLeast(lowerV), nil
+		case constraints.OpLessThan:
+			return OlderThan(lowerV), nil
+		case constraints.OpLessThanOrEqual:
+			return AtMost(lowerV), nil
+		case constraints.OpGreaterThanOrEqualMinorOnly,
+			constraints.OpGreaterThanOrEqualPatchOnly,
+			constraints.OpGreaterThanOrEqualPrereleaseOnly:
+			upperV, err := scheme.UpperBound(ts.Operator, lowerV)
+			if err != nil {
+				return None, err
+			}
+			return AtLeast(lowerV).Intersection(OlderThan(upperV)), nil
+		default:
+			panic(fmt.Errorf("unsupported constraints.SelectionOp %s", ts.Operator))
+		}
+
+	case constraints.UnionSpec:
+		sets := make([]Set, len(ts))
+		for i, subSpec := range ts {
+			set, err := MeetingConstraintsExactWithScheme(subSpec, scheme)
+			if err != nil {
+				return None, err
+			}
+			sets[i] = set
+		}
+		if len(sets) == 0 {
+			return All, nil
+		}
+		return Union(sets...), nil
+
+	case constraints.IntersectionSpec:
+		sets := make([]Set, len(ts))
+		for i, subSpec := range ts {
+			set, err := MeetingConstraintsExactWithScheme(subSpec, scheme)
+			if err != nil {
+				return None, err
+			}
+			sets[i] = set
+		}
+		return Intersection(sets...), nil
+
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+// schemeVersionFromSpec converts the given exact version spec to a version of
+// the given scheme by parsing its string representation.
+func schemeVersionFromSpec(spec constraints.VersionSpec, scheme Scheme) (SchemeVersion, error) {
+	str := versionFromExactVersionSpec(spec).String()
+	v, err := scheme.ParseVersion(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid boundary version %q: %s", str, err)
+	}
+	return v, nil
+}
+
 // MeetingConstraintsString attempts to parse the given spec as a constraints
 // string in our canonical format, which is most similar to the syntax used by
 // npm, Go's "dep" tool, Rust's "cargo", etc.
@@ -188,11 +380,11 @@ func MeetingConstraintsExact(spec constraints.Spec) Set {
 // Parser errors are suitable for showing to an end-user in situations where
 // the given spec came from user input.
 func MeetingConstraintsString(spec string) (Set, error) {
-	s, err := constraints.Parse(spec)
+	s, sources, err := constraints.ParseWithSources(spec)
 	if err != nil {
 		return None, err
 	}
-	return MeetingConstraints(s), nil
+	return MeetingConstraintsWithSources(s, sources), nil
 }
 
 // MeetingConstraintsStringRuby attempts to parse the given spec as a
@@ -213,11 +405,144 @@ func MeetingConstraintsString(spec string) (Set, error) {
 // control over the parsing process, use the constraints package API directly
 // and then call MeetingConstraints.
 func MeetingConstraintsStringRuby(spec string) (Set, error) {
-	s, err := constraints.ParseRubyStyleMulti(spec)
+	s, sources, err := constraints.ParseRubyStyleMultiWithSources(spec)
+	if err != nil {
+		return None, err
+	}
+	return MeetingConstraintsWithSources(s, sources), nil
+}
+
+// MeetingConstraintsNPM returns a version set that contains all of the
+// versions that meet the given constraints, using the rules of the npm
+// package manager's "node-semver" library rather than the rules used by
+// MeetingConstraints. The spec is expected to have been produced by
+// constraints.ParseNPM.
+//
+// Under these rules, a pre-release version is a member of the set only if
+// it meets the selections of one of the selection sets and that same
+// selection set also includes a selection whose boundary is a pre-release of
+// the same major, minor and patch version. For example, ">1.2.3-alpha.3"
+// contains 1.2.3-alpha.7 but not 3.4.5-alpha.9.
+//
+// Exact selections also match versions that differ only in build metadata,
+// since npm ignores metadata when comparing versions.
+func MeetingConstraintsNPM(spec constraints.Spec) Set {
+	return MeetingConstraintsNPMWithSources(spec, nil)
+}
+
+// MeetingConstraintsNPMWithSources is like MeetingConstraintsNPM except that
+// the resulting set also retains the given sources of the selections in the
+// spec, as described for MeetingConstraintsWithSources.
+func MeetingConstraintsNPMWithSources(spec constraints.Spec, sources constraints.Sources) Set {
+	switch ts := spec.(type) {
+	case constraints.UnionSpec:
+		if len(ts) == 0 {
+			return meetingConstraintsPrereleaseTuples(nil, nil)
+		}
+		sets := make([]Set, len(ts))
+		for i, subSpec := range ts {
+			sets[i] = meetingConstraintsPrereleaseTuples(subSpec, alternativeSources(sources, i))
+		}
+		return Union(sets...)
+	case constraints.IntersectionSpec:
+		return meetingConstraintsPrereleaseTuples(ts, sources)
+	case constraints.SelectionSpec:
+		return meetingConstraintsPrereleaseTuples(constraints.IntersectionSpec{ts}, sources)
+	case constraints.VersionSpec:
+		return meetingConstraintsPrereleaseTuples(constraints.IntersectionSpec{
+			{Operator: constraints.OpMatch, Boundary: ts},
+		}, sources)
+	case nil:
+		return meetingConstraintsPrereleaseTuples(nil, nil)
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+// meetingConstraintsPrereleaseTuples returns the set of versions meeting the
+// given selection set under the pre-release rules shared by npm and Cargo,
+// where a pre-release version is a member only if one of the selections has a
+// pre-release boundary with the same major, minor and patch version.
+//
+// Exact selections match regardless of build metadata, since neither of
+// these systems considers metadata when comparing versions.
+//
+// The given sources are arranged as described for constraints.Sources.
+func meetingConstraintsPrereleaseTuples(spec constraints.IntersectionSpec, sources constraints.Sources) Set {
+	sets := make([]Set, 0, len(spec)+1)
+	allowed := Released
+	for i, sel := range spec {
+		if sel.Operator == constraints.OpEqual {
+			v := versionFromExactVersionSpec(sel.Boundary).Comparable()
+			sets = append(sets, withSelectionSource(Intersection(AtLeast(v), AtMost(v)), sel, sources.Get(0, i)))
+		} else {
+			sets = append(sets, withSelectionSource(MeetingConstraintsExact(sel), sel, sources.Get(0, i)))
+		}
+
+		if sel.Boundary.Prerelease != "" {
+			// Pre-releases of the same major, minor and patch version as
+			// this boundary are acceptable to the whole selection set.
+			allowed = Union(allowed, prereleaseTuple(sel.Boundary))
+		}
+	}
+	sets = append(sets, allowed)
+	return Intersection(sets...)
+}
+
+// MeetingConstraintsStringNPM attempts to parse the given spec as an npm
+// version range string, using constraints.ParseNPM, and returns the set of
+// versions that npm would consider to satisfy it if successful.
+//
+// If unsuccessful, the error from the underlying parser is returned verbatim.
+// Parser errors are suitable for showing to an end-user in situations where
+// the given spec came from user input.
+//
+// See MeetingConstraintsNPM for details on how npm's rules for matching
+// versions differ from those of MeetingConstraints.
+func MeetingConstraintsStringNPM(spec string) (Set, error) {
+	s, sources, err := constraints.ParseNPMWithSources(spec)
+	if err != nil {
+		return None, err
+	}
+	return MeetingConstraintsNPMWithSources(s, sources), nil
+}
+
+// MeetingConstraintsCargo returns a version set that contains all of the
+// versions that meet the given constraints, using the rules of Rust's package
+// manager "cargo" rather than the rules used by MeetingConstraints. The spec
+// is expected to have been produced by constraints.ParseCargo.
+//
+// Under these rules, a pre-release version is a member of the set only if it
+// meets all of the selections and at least one selection has a boundary that
+// is a pre-release of the same major, minor and patch version. For example,
+// ">=1.2.3-alpha.3, <2.0.0" contains 1.2.3-alpha.7 but not 1.5.0-alpha.1.
+// Exact selections also match versions that differ only in build metadata.
+func MeetingConstraintsCargo(spec constraints.IntersectionSpec) Set {
+	return meetingConstraintsPrereleaseTuples(spec, nil)
+}
+
+// MeetingConstraintsCargoWithSources is like MeetingConstraintsCargo except
+// that the resulting set also retains the given sources of the selections in
+// the spec, as described for MeetingConstraintsWithSources.
+func MeetingConstraintsCargoWithSources(spec constraints.IntersectionSpec, sources constraints.Sources) Set {
+	return meetingConstraintsPrereleaseTuples(spec, sources)
+}
+
+// MeetingConstraintsStringCargo attempts to parse the given spec as a Cargo
+// version requirement string, using constraints.ParseCargo, and returns the
+// set of versions that Cargo would consider to satisfy it if successful.
+//
+// If unsuccessful, the error from the underlying parser is returned verbatim.
+// Parser errors are suitable for showing to an end-user in situations where
+// the given spec came from user input.
+func MeetingConstraintsStringCargo(spec string) (Set, error) {
+	s, sources, err := constraints.ParseCargoWithSources(spec)
 	if err != nil {
 		return None, err
 	}
-	return MeetingConstraints(s), nil
+	return MeetingConstraintsCargoWithSources(s, sources), nil
 }
 
 // MustMakeSet can be used to wrap any function that returns a set and an error
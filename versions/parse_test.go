@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/apparentlymart/go-versions/versions/constraints"
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -251,6 +252,7 @@ func TestMeetingConstraintsCanon(t *testing.T) {
 				t.Fatal(err)
 			}
 
+			got = withoutSources(got) // provenance is covered by TestSetSources
 			if !reflect.DeepEqual(got, test.Want) {
 				gotStr := got.GoString()
 				wantStr := test.Want.GoString()
@@ -399,6 +401,7 @@ func TestMeetingConstraintsRuby(t *testing.T) {
 				t.Fatal(err)
 			}
 
+			got = withoutSources(got) // provenance is covered by TestSetSources
 			if !reflect.DeepEqual(got, test.Want) {
 				gotStr := got.GoString()
 				wantStr := test.Want.GoString()
@@ -413,3 +416,455 @@ func TestMeetingConstraintsRuby(t *testing.T) {
 		})
 	}
 }
+
+func TestMeetingConstraintsNPM(t *testing.T) {
+	// These cases are ported from the "range-include" and "range-exclude"
+	// fixtures in node-semver, omitting those that rely on its "loose" and
+	// "includePrerelease" options and those that test version 0.0.0, which
+	// in this package is indistinguishable from Unspecified.
+	tests := []struct {
+		Input   string
+		Version string
+		Want    bool
+	}{
+		{"1.0.0 - 2.0.0", "1.2.3", true},
+		{"^1.2.3+build", "1.2.3", true},
+		{"^1.2.3+build", "1.3.0", true},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "1.2.3", true},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "1.2.3-pre.2", true},
+		{"1.2.3-pre+asdf - 2.4.3-pre+asdf", "2.4.3-alpha", true},
+		{"1.2.3+asdf - 2.4.3+asdf", "1.2.3", true},
+		{"1.0.0", "1.0.0", true},
+		{"1.0.0", "1.0.0+abc", true},
+		{">=*", "0.2.4", true},
+		{"", "1.0.0", true},
+		{"*", "1.2.3", true},
+		{">=1.0.0", "1.0.0", true},
+		{">=1.0.0", "1.0.1", true},
+		{">=1.0.0", "1.1.0", true},
+		{">1.0.0", "1.0.1", true},
+		{">1.0.0", "1.1.0", true},
+		{"<=2.0.0", "2.0.0", true},
+		{"<=2.0.0", "1.9999.9999", true},
+		{"<=2.0.0", "0.2.9", true},
+		{"<2.0.0", "1.9999.9999", true},
+		{"<2.0.0", "0.2.9", true},
+		{">= 1.0.0", "1.0.0", true},
+		{">=  1.0.0", "1.0.1", true},
+		{">=   1.0.0", "1.1.0", true},
+		{"> 1.0.0", "1.0.1", true},
+		{">  1.0.0", "1.1.0", true},
+		{"<=   2.0.0", "2.0.0", true},
+		{"<= 2.0.0", "1.9999.9999", true},
+		{"<=  2.0.0", "0.2.9", true},
+		{"<    2.0.0", "1.9999.9999", true},
+		{"<\t2.0.0", "0.2.9", true},
+		{">=0.1.97", "0.1.97", true},
+		{"0.1.20 || 1.2.4", "1.2.4", true},
+		{">=0.2.3 || <0.0.1", "0.2.3", true},
+		{">=0.2.3 || <0.0.1", "0.2.4", true},
+		{"||", "1.3.4", true},
+		{"2.x.x", "2.1.3", true},
+		{"1.2.x", "1.2.3", true},
+		{"1.2.x || 2.x", "2.1.3", true},
+		{"1.2.x || 2.x", "1.2.3", true},
+		{"x", "1.2.3", true},
+		{"2.*.*", "2.1.3", true},
+		{"1.2.*", "1.2.3", true},
+		{"1.2.* || 2.*", "2.1.3", true},
+		{"1.2.* || 2.*", "1.2.3", true},
+		{"2", "2.1.2", true},
+		{"2.3", "2.3.1", true},
+		{"~0.0.1", "0.0.1", true},
+		{"~0.0.1", "0.0.2", true},
+		{"~x", "0.0.9", true},
+		{"~2", "2.0.9", true},
+		{"~2.4", "2.4.0", true},
+		{"~2.4", "2.4.5", true},
+		{"~>3.2.1", "3.2.2", true},
+		{"~1", "1.2.3", true},
+		{"~>1", "1.2.3", true},
+		{"~> 1", "1.2.3", true},
+		{"~1.0", "1.0.2", true},
+		{"~ 1.0", "1.0.2", true},
+		{"~ 1.0.3", "1.0.12", true},
+		{">=1", "1.0.0", true},
+		{">= 1", "1.0.0", true},
+		{"<1.2", "1.1.1", true},
+		{"< 1.2", "1.1.1", true},
+		{"~v0.5.4-pre", "0.5.5", true},
+		{"~v0.5.4-pre", "0.5.4", true},
+		{"=0.7.x", "0.7.2", true},
+		{"<=0.7.x", "0.7.2", true},
+		{">=0.7.x", "0.7.2", true},
+		{"<=0.7.x", "0.6.2", true},
+		{"~1.2.1 >=1.2.3", "1.2.3", true},
+		{"~1.2.1 =1.2.3", "1.2.3", true},
+		{"~1.2.1 1.2.3", "1.2.3", true},
+		{"~1.2.1 >=1.2.3 1.2.3", "1.2.3", true},
+		{"~1.2.1 1.2.3 >=1.2.3", "1.2.3", true},
+		{">=1.2.1 1.2.3", "1.2.3", true},
+		{"1.2.3 >=1.2.1", "1.2.3", true},
+		{">=1.2.3 >=1.2.1", "1.2.3", true},
+		{">=1.2.1 >=1.2.3", "1.2.3", true},
+		{">=1.2", "1.2.8", true},
+		{"^1.2.3", "1.8.1", true},
+		{"^0.1.2", "0.1.2", true},
+		{"^0.1", "0.1.2", true},
+		{"^0.0.1", "0.0.1", true},
+		{"^1.2", "1.4.2", true},
+		{"^1.2 ^1", "1.4.2", true},
+		{"^1.2.3-alpha", "1.2.3-pre", true},
+		{"^1.2.0-alpha", "1.2.0-pre", true},
+		{"^0.0.1-alpha", "0.0.1-beta", true},
+		{"^0.0.1-alpha", "0.0.1", true},
+		{"^0.1.1-alpha", "0.1.1-beta", true},
+		{"^x", "1.2.3", true},
+		{"x - 1.0.0", "0.9.7", true},
+		{"x - 1.x", "0.9.7", true},
+		{"1.0.0 - x", "1.9.7", true},
+		{"1.x - x", "1.9.7", true},
+		{"<=7.x", "7.9.9", true},
+
+		{"1.0.0 - 2.0.0", "2.2.3", false},
+		{"1.2.3+asdf - 2.4.3+asdf", "1.2.3-pre.2", false},
+		{"1.2.3+asdf - 2.4.3+asdf", "2.4.3-alpha", false},
+		{"^1.2.3+build", "2.0.0", false},
+		{"^1.2.3+build", "1.2.0", false},
+		{"^1.2.3", "1.2.3-pre", false},
+		{"^1.2", "1.2.0-pre", false},
+		{">1.2", "1.3.0-beta", false},
+		{"<=1.2.3", "1.2.3-beta", false},
+		{"^1.2.3", "1.2.3-beta", false},
+		{"=0.7.x", "0.7.0-asdf", false},
+		{">=0.7.x", "0.7.0-asdf", false},
+		{"<=0.7.x", "0.7.0-asdf", false},
+		{"1.0.0", "1.0.1", false},
+		{">=1.0.0", "0.0.0", false},
+		{">=1.0.0", "0.0.1", false},
+		{">=1.0.0", "0.1.0", false},
+		{">1.0.0", "0.0.1", false},
+		{">1.0.0", "0.1.0", false},
+		{"<=2.0.0", "3.0.0", false},
+		{"<=2.0.0", "2.9999.9999", false},
+		{"<=2.0.0", "2.2.9", false},
+		{"<2.0.0", "2.9999.9999", false},
+		{"<2.0.0", "2.2.9", false},
+		{">=0.1.97", "0.1.93", false},
+		{"0.1.20 || 1.2.4", "1.2.3", false},
+		{">=0.2.3 || <0.0.1", "0.0.3", false},
+		{">=0.2.3 || <0.0.1", "0.2.2", false},
+		{"2.x.x", "1.1.3", false},
+		{"2.x.x", "3.1.3", false},
+		{"1.2.x", "1.3.3", false},
+		{"1.2.x || 2.x", "3.1.3", false},
+		{"1.2.x || 2.x", "1.1.3", false},
+		{"2.*.*", "1.1.3", false},
+		{"2.*.*", "3.1.3", false},
+		{"1.2.*", "1.3.3", false},
+		{"1.2.* || 2.*", "3.1.3", false},
+		{"1.2.* || 2.*", "1.1.3", false},
+		{"2", "1.1.2", false},
+		{"2.3", "2.4.1", false},
+		{"~0.0.1", "0.1.0-alpha", false},
+		{"~0.0.1", "0.1.0", false},
+		{"~2.4", "2.5.0", false},
+		{"~2.4", "2.3.9", false},
+		{"~>3.2.1", "3.3.2", false},
+		{"~>3.2.1", "3.2.0", false},
+		{"~1", "0.2.3", false},
+		{"~>1", "2.2.3", false},
+		{"~1.0", "1.1.0", false},
+		{"<1", "1.0.0", false},
+		{">=1.2", "1.1.1", false},
+		{"~v0.5.4-beta", "0.5.4-alpha", false},
+		{"=0.7.x", "0.8.2", false},
+		{">=0.7.x", "0.6.2", false},
+		{"<0.7.x", "0.7.2", false},
+		{"<1.2.3", "1.2.3-beta", false},
+		{"=1.2.3", "1.2.3-beta", false},
+		{">1.2", "1.2.8", false},
+		{"^0.0.1", "0.0.2-alpha", false},
+		{"^0.0.1", "0.0.2", false},
+		{"^1.2.3", "2.0.0-alpha", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^1.2", "1.1.9", false},
+		{"^1.0.0", "2.0.0-rc1", false},
+		{"1 - 2", "2.0.0-pre", false},
+		{"1 - 2", "1.0.0-pre", false},
+		{"1.0 - 2", "1.0.0-pre", false},
+		{"1.1.x", "1.0.0-a", false},
+		{"1.1.x", "1.1.0-a", false},
+		{"1.1.x", "1.2.0-a", false},
+		{"1.x", "1.0.0-a", false},
+		{"1.x", "1.1.0-a", false},
+		{"1.x", "2.0.0-a", false},
+		{">=1.0.0 <1.1.0", "1.1.0", false},
+		{">=1.0.0 <1.1.0", "1.1.0-pre", false},
+		{">=1.0.0 <1.1.0-pre", "1.1.0-pre", false},
+		{">1.2.3-alpha.3", "3.4.5-alpha.9", false},
+		{">1.2.3-alpha.3", "1.2.3-alpha.7", true},
+		{">1.2.3-alpha.3 || >1.0.0", "3.4.5-alpha.9", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input+" has "+test.Version, func(t *testing.T) {
+			set, err := MeetingConstraintsStringNPM(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := MustParseVersion(test.Version)
+			if got, want := set.Has(v), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestMeetingConstraintsCargo(t *testing.T) {
+	// Many of these cases are based on the tests for the "semver" crate that
+	// Cargo uses to match versions against requirements.
+	tests := []struct {
+		Input   string
+		Version string
+		Want    bool
+	}{
+		{"1.0.0", "1.0.0", true},
+		{"1.0.0", "1.0.1", true},
+		{"1.0.0", "1.5.0", true},
+		{"1.0.0", "2.0.0", false},
+		{"1.0.0", "0.9.9", false},
+		{"1.0.0", "1.0.0+build", true},
+		{"1.0.0", "1.1.0-beta", false},
+		{"=0.9.0", "0.9.0", true},
+		{"=0.9.0", "0.9.0+build", true},
+		{"=0.9.0", "0.9.1", false},
+		{"=0.9.0", "0.9.0-beta", false},
+		{"=0.1.0-beta2.a", "0.1.0-beta2.a", true},
+		{"=0.1.0-beta2.a", "0.1.0", false},
+		{"=1", "1.9.9", true},
+		{"=1", "2.0.0", false},
+		{"=1.2", "1.2.9", true},
+		{"=1.2", "1.3.0", false},
+		{">= 1.0.0", "1.0.0", true},
+		{">= 1.0.0", "2.0.0", true},
+		{">= 1.0.0", "0.1.0", false},
+		{">= 2.1.0-alpha2", "2.1.0-alpha2", true},
+		{">= 2.1.0-alpha2", "2.1.0-alpha3", true},
+		{">= 2.1.0-alpha2", "2.1.0", true},
+		{">= 2.1.0-alpha2", "3.0.0", true},
+		{">= 2.1.0-alpha2", "2.1.0-alpha1", false},
+		{">= 2.1.0-alpha2", "2.2.0-alpha3", false},
+		{">= 2.1.0-alpha2", "2.0.0", false},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<= 2.1.0-alpha2", "2.1.0-alpha2", true},
+		{"<= 2.1.0-alpha2", "2.1.0-alpha1", true},
+		{"<= 2.1.0-alpha2", "2.0.0", true},
+		{"<= 2.1.0-alpha2", "2.1.0-alpha3", false},
+		{"<= 2.1.0-alpha2", "2.1.0", false},
+		{"<= 2.1.0-alpha2", "2.0.0-alpha1", false},
+		{"<1.2", "1.1.9", true},
+		{"<1.2", "1.2.0", false},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{">1.0.0-alpha, <1.0.0", "1.0.0-beta", true},
+		{">=0.5.1-alpha3, <0.6", "0.5.1-alpha3", true},
+		{">=0.5.1-alpha3, <0.6", "0.5.1-alpha4", true},
+		{">=0.5.1-alpha3, <0.6", "0.5.1-beta", true},
+		{">=0.5.1-alpha3, <0.6", "0.5.1", true},
+		{">=0.5.1-alpha3, <0.6", "0.5.5", true},
+		{">=0.5.1-alpha3, <0.6", "0.5.1-alpha1", false},
+		{">=0.5.1-alpha3, <0.6", "0.5.2-alpha3", false},
+		{">=0.5.1-alpha3, <0.6", "0.5.5-pre", false},
+		{">=0.5.1-alpha3, <0.6", "0.5.0-pre", false},
+		{"~1", "1.0.0", true},
+		{"~1", "1.9.9", true},
+		{"~1", "2.0.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~1.2", "1.2.9", true},
+		{"~1.2", "1.3.0", false},
+		{"~1.2.2", "1.2.2", true},
+		{"~1.2.2", "1.2.4", true},
+		{"~1.2.2", "1.2.1", false},
+		{"~1.2.2", "1.3.0", false},
+		{"~1.2.3-beta.2", "1.2.3", true},
+		{"~1.2.3-beta.2", "1.2.4", true},
+		{"~1.2.3-beta.2", "1.2.3-beta.2", true},
+		{"~1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"~1.2.3-beta.2", "1.3.0", false},
+		{"~1.2.3-beta.2", "1.2.4-beta.2", false},
+		{"^1", "1.1.2", true},
+		{"^1", "1.1.0", true},
+		{"^1", "1.2.1", true},
+		{"^1", "1.0.1", true},
+		{"^1", "0.9.1", false},
+		{"^1", "2.9.0", false},
+		{"^1", "0.1.4", false},
+		{"^1.1", "1.1.2", true},
+		{"^1.1", "1.1.0", true},
+		{"^1.1", "1.2.1", true},
+		{"^1.1", "0.9.1", false},
+		{"^1.1", "2.9.0", false},
+		{"^1.1", "1.0.1", false},
+		{"^1.1.2", "1.1.2", true},
+		{"^1.1.2", "1.1.4", true},
+		{"^1.1.2", "1.2.1", true},
+		{"^1.1.2", "0.9.1", false},
+		{"^1.1.2", "2.9.0", false},
+		{"^1.1.2", "1.1.1", false},
+		{"^1.1.2", "0.0.1", false},
+		{"^1.1.2", "1.1.2-alpha1", false},
+		{"^1.1.2", "1.1.3-alpha1", false},
+		{"^1.1.2", "2.9.0-alpha1", false},
+		{"^0.1.2", "0.1.2", true},
+		{"^0.1.2", "0.1.4", true},
+		{"^0.1.2", "0.9.1", false},
+		{"^0.1.2", "2.9.0", false},
+		{"^0.1.2", "1.1.1", false},
+		{"^0.1.2", "0.1.0", false},
+		{"^0.1.2", "0.1.2-alpha1", false},
+		{"^0.1.2", "0.1.3-alpha1", false},
+		{"^0.1.2", "0.2.0-pre", false},
+		{"^0.5.1-alpha3", "0.5.1-alpha3", true},
+		{"^0.5.1-alpha3", "0.5.1-alpha4", true},
+		{"^0.5.1-alpha3", "0.5.1-beta", true},
+		{"^0.5.1-alpha3", "0.5.1", true},
+		{"^0.5.1-alpha3", "0.5.5", true},
+		{"^0.5.1-alpha3", "0.5.1-alpha1", false},
+		{"^0.5.1-alpha3", "0.5.2-alpha3", false},
+		{"^0.5.1-alpha3", "0.5.5-pre", false},
+		{"^0.5.1-alpha3", "0.5.0-pre", false},
+		{"^0.5.1-alpha3", "0.6.0", false},
+		{"^0.0.2", "0.0.2", true},
+		{"^0.0.2", "0.0.2+build", true},
+		{"^0.0.2", "0.9.1", false},
+		{"^0.0.2", "2.9.0", false},
+		{"^0.0.2", "1.1.1", false},
+		{"^0.0.2", "0.0.1", false},
+		{"^0.0.2", "0.1.4", false},
+		{"^0.0.2", "0.0.3", false},
+		{"^0.0.2-alpha", "0.0.2-beta", true},
+		{"^0.0.2-alpha", "0.0.2", true},
+		{"^0.0.2-alpha", "0.0.3-alpha", false},
+		{"^0.0", "0.0.2", true},
+		{"^0.0", "0.0.0+build", true},
+		{"^0.0", "0.1.4", false},
+		{"^0", "0.9.1", true},
+		{"^0", "0.0.2", true},
+		{"^0", "1.1.1", false},
+		{"^0", "0.0.1-alpha", false},
+		{"*", "0.1.0", true},
+		{"*", "1.0.0", true},
+		{"*", "1.0.0-pre", false},
+		{"1.*", "1.2.0", true},
+		{"1.*", "1.2.1", true},
+		{"1.*", "1.1.1", true},
+		{"1.*", "1.3.0", true},
+		{"1.*", "0.1.0", false},
+		{"1.*", "2.1.1", false},
+		{"1.*", "1.2.0-pre", false},
+		{"1.2.*", "1.2.0", true},
+		{"1.2.*", "1.2.2", true},
+		{"1.2.*", "1.2.4", true},
+		{"1.2.*", "0.1.0", false},
+		{"1.2.*", "1.1.1", false},
+		{"1.2.*", "2.1.1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input+" has "+test.Version, func(t *testing.T) {
+			set, err := MeetingConstraintsStringCargo(test.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := MustParseVersion(test.Version)
+			if got, want := set.Has(v), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestMeetingConstraintsChecked(t *testing.T) {
+	v1x := constraints.VersionSpec{
+		Major: constraints.NumConstraint{Num: 1},
+		Minor: constraints.NumConstraint{Unconstrained: true},
+		Patch: constraints.NumConstraint{Unconstrained: true},
+	}
+
+	got, err := MeetingConstraintsChecked(constraints.SelectionSpec{
+		Operator: constraints.OpMatch,
+		Boundary: v1x,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Has(MustParseVersion("1.5.0")) || got.Has(MustParseVersion("2.0.0")) {
+		t.Errorf("wrong result %#v", got)
+	}
+
+	got, err = MeetingConstraintsExactChecked(constraints.SelectionSpec{
+		Operator: constraints.SelectionOp('?'),
+		Boundary: v1x,
+	})
+	if err == nil {
+		t.Fatalf("succeeded; want error")
+	}
+	if got, want := err.Error(), "invalid selection SelectionOp(63) 1.*.*: unsupported operator"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+	if got != None {
+		t.Errorf("wrong result %#v; want None", got)
+	}
+}
+
+func TestParseVersionStrict(t *testing.T) {
+	got, err := ParseVersionStrict("1.2.3-rc.1+build.001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := MustParseVersion("1.2.3-rc.1+build.001"); got != want {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	// These are all accepted by ParseVersion, but aren't valid semver.
+	for _, input := range []string{"1.2", "01.2.3", "1.2.3-rc.01", "1.2.3-a..b", "1.2.3+"} {
+		if _, err := ParseVersion(input); err != nil {
+			t.Errorf("ParseVersion(%q) failed: %s", input, err)
+		}
+		if got, err := ParseVersionStrict(input); err == nil {
+			t.Errorf("ParseVersionStrict(%q) succeeded with %s; want error", input, got)
+		}
+	}
+}
+
+// withoutSources returns a set with the same structure as the given set but
+// with the wrappers that retain selection sources removed, and the sets they
+// wrapped flattened as the set constructors would have, so that tests can
+// compare sets produced from parsed constraints with sets built directly.
+func withoutSources(s Set) Set {
+	switch ss := s.setI.(type) {
+	case setSource:
+		return withoutSources(Set{setI: ss.setI})
+	case setUnion:
+		sets := make([]Set, len(ss))
+		for i, sss := range ss {
+			sets[i] = withoutSources(Set{setI: sss})
+		}
+		return Union(sets...)
+	case setIntersection:
+		sets := make([]Set, len(ss))
+		for i, sss := range ss {
+			sets[i] = withoutSources(Set{setI: sss})
+		}
+		return Intersection(sets...)
+	case setSubtract:
+		return Set{setI: setSubtract{
+			from: withoutSources(Set{setI: ss.from}).setI,
+			sub:  withoutSources(Set{setI: ss.sub}).setI,
+		}}
+	default:
+		return s
+	}
+}
@@ -0,0 +1,12 @@
+// Package pep440 is a companion to package versions that deals with the
+// version numbers and version specifiers used by Python packaging tools, as
+// defined in PEP 440:
+// https://www.python.org/dev/peps/pep-0440/
+//
+// PEP 440 versions do not fit the semantic versioning model used by package
+// versions, since they can have any number of release segments along with
+// epochs, post-releases, development releases and local version labels. This
+// package therefore has its own Version type, whose Scheme lets PEP 440
+// versions be used with versions.Set and versions.List, and with the
+// functions of package versions that create sets, such as versions.AtLeast.
+package pep440
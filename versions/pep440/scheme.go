@@ -0,0 +1,72 @@
+package pep440
+
+import (
+	"fmt"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// Scheme is the versions.Scheme of PEP 440 versions, which orders them using
+// Version.Compare and treats pre-releases and development releases as
+// pre-releases.
+//
+// For the constraint operators that allow compatible newer versions, "^"
+// allows versions with the same first release segment and "~" allows
+// versions with the same first two release segments, in both cases excluding
+// the development releases of the next such version, as "~=" does.
+var Scheme versions.Scheme = scheme{}
+
+// Scheme returns the scheme of PEP 440 versions, which is the package-level
+// variable of the same name.
+func (v Version) Scheme() versions.Scheme {
+	return Scheme
+}
+
+type scheme struct{}
+
+func (s scheme) ParseVersion(str string) (versions.SchemeVersion, error) {
+	return Parse(str)
+}
+
+func (s scheme) Compare(a, b versions.SchemeVersion) int {
+	return a.(Version).Compare(b.(Version))
+}
+
+func (s scheme) IsPrerelease(v versions.SchemeVersion) bool {
+	return v.(Version).IsPrerelease()
+}
+
+func (s scheme) UpperBound(op constraints.SelectionOp, lower versions.SchemeVersion) (versions.SchemeVersion, error) {
+	v := lower.(Version)
+	switch op {
+	case constraints.OpGreaterThanOrEqualMinorOnly:
+		return releaseStart(v.Epoch, nextRelease(v.Release, 1)), nil
+	case constraints.OpGreaterThanOrEqualPatchOnly:
+		return releaseStart(v.Epoch, nextRelease(v.Release, 2)), nil
+	case constraints.OpGreaterThanOrEqualPrereleaseOnly:
+		return releaseStart(v.Epoch, nextRelease(v.Release, 3)), nil
+	default:
+		return nil, fmt.Errorf("PEP 440 versions have no equivalent of the %s operator", op)
+	}
+}
+
+func (s scheme) GoString() string {
+	return "pep440.Scheme"
+}
+
+// releaseStart returns the lowest version with the given epoch and release
+// segments, which is the first development release.
+func releaseStart(epoch uint64, release []uint64) Version {
+	return Version{Epoch: epoch, Release: release, HasDev: true}
+}
+
+// nextRelease returns the lowest release segments that are higher than any
+// that start with the first n of the given segments, padding them with zeros
+// if necessary.
+func nextRelease(release []uint64, n int) []uint64 {
+	ret := make([]uint64, n)
+	copy(ret, release)
+	ret[n-1]++
+	return ret
+}
@@ -0,0 +1,373 @@
+package pep440
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// Operator is the comparison operator of a Specifier.
+type Operator string
+
+const (
+	OpCompatible         Operator = "~="
+	OpEqual              Operator = "=="
+	OpNotEqual           Operator = "!="
+	OpLessThanOrEqual    Operator = "<="
+	OpGreaterThanOrEqual Operator = ">="
+	OpLessThan           Operator = "<"
+	OpGreaterThan        Operator = ">"
+	OpArbitrary          Operator = "==="
+)
+
+// Specifier is a single version clause from a PEP 440 version specifier,
+// such as ">=1.0" or "==1.2.*".
+type Specifier struct {
+	Operator Operator
+
+	// Version is the version the clause compares with. It is not set for
+	// OpArbitrary, which compares with Text instead.
+	Version Version
+
+	// Wildcard is true for OpEqual and OpNotEqual clauses that end with ".*",
+	// which match any version whose release segments start with those
+	// of Version.
+	Wildcard bool
+
+	// Text is the string that an OpArbitrary clause compares with, which
+	// need not be a valid version.
+	Text string
+}
+
+// Specifiers is a sequence of clauses that must all match a version for the
+// version to match the specifier as a whole.
+type Specifiers []Specifier
+
+var specifierPattern = regexp.MustCompile(`^\s*(~=|===|==|!=|<=|>=|<|>)\s*(.*?)\s*$`)
+
+// ParseSpecifiers parses a PEP 440 version specifier, which is a
+// comma-separated sequence of clauses such as ">=1.0, !=1.3.4.*, <2.0".
+//
+// If the given string is not a valid specifier then an error is returned
+// that is suitable for display directly to a hypothetical end-user that
+// provided the string, as long as they can read English.
+func ParseSpecifiers(str string) (Specifiers, error) {
+	if strings.TrimSpace(str) == "" {
+		return nil, fmt.Errorf("empty version specifier")
+	}
+
+	parts := strings.Split(str, ",")
+	ret := make(Specifiers, 0, len(parts))
+	for _, part := range parts {
+		spec, err := ParseSpecifier(part)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, spec)
+	}
+	return ret, nil
+}
+
+// ParseSpecifier parses a single version clause, such as "~=1.4.2".
+//
+// Use ParseSpecifiers to parse a full specifier that may contain more
+// than one clause.
+func ParseSpecifier(str string) (Specifier, error) {
+	var spec Specifier
+
+	m := specifierPattern.FindStringSubmatch(str)
+	if m == nil {
+		if strings.TrimSpace(str) == "" {
+			return spec, fmt.Errorf("empty version clause")
+		}
+		return spec, fmt.Errorf("invalid version clause %q; must start with one of the operators ~=, ==, !=, <=, >=, <, >, ===", strings.TrimSpace(str))
+	}
+	spec.Operator = Operator(m[1])
+	vStr := m[2]
+	if vStr == "" {
+		return spec, fmt.Errorf("operator %q must be followed by a version", m[1])
+	}
+
+	if spec.Operator == OpArbitrary {
+		if strings.ContainsAny(vStr, " \t\n;") {
+			return spec, fmt.Errorf("invalid arbitrary version %q; must not contain whitespace or semicolons", vStr)
+		}
+		spec.Text = vStr
+		return spec, nil
+	}
+
+	if strings.HasSuffix(vStr, ".*") {
+		if spec.Operator != OpEqual && spec.Operator != OpNotEqual {
+			return spec, fmt.Errorf("can't use a wildcard with operator %q; only == and != allow wildcards", m[1])
+		}
+		spec.Wildcard = true
+		vStr = vStr[:len(vStr)-2]
+	}
+
+	v, err := Parse(vStr)
+	if err != nil {
+		return spec, err
+	}
+	spec.Version = v
+
+	switch {
+	case spec.Wildcard && (v.Pre != "" || v.HasPost || v.HasDev || v.Local != ""):
+		return spec, fmt.Errorf("invalid wildcard version %q; only release segments may precede \".*\"", m[2])
+	case v.Local != "" && spec.Operator != OpEqual && spec.Operator != OpNotEqual:
+		return spec, fmt.Errorf("can't use a local version label with operator %q", m[1])
+	case spec.Operator == OpCompatible && len(v.Release) < 2:
+		return spec, fmt.Errorf("operator \"~=\" requires a version with at least two release segments")
+	}
+
+	return spec, nil
+}
+
+// Has returns true if the given version matches the receiving clause, using
+// the rules given in PEP 440 for each operator.
+//
+// This method does not apply the rule that excludes pre-releases from the
+// result unless requested. MeetingSpecifiers applies that rule.
+func (s Specifier) Has(v Version) bool {
+	switch s.Operator {
+	case OpCompatible:
+		// ~=V.N is equivalent to >=V.N, ==V.*
+		prefix := Version{Epoch: s.Version.Epoch, Release: s.Version.Release[:len(s.Version.Release)-1]}
+		return !v.Public().LessThan(s.Version) && prefixMatch(prefix, v)
+	case OpEqual:
+		return s.equal(v)
+	case OpNotEqual:
+		return !s.equal(v)
+	case OpLessThanOrEqual:
+		return !v.Public().GreaterThan(s.Version)
+	case OpGreaterThanOrEqual:
+		return !v.Public().LessThan(s.Version)
+	case OpLessThan:
+		if !v.LessThan(s.Version) {
+			return false
+		}
+		// <V does not match pre-releases of V unless V is itself a
+		// pre-release.
+		if !s.Version.IsPrerelease() && v.IsPrerelease() && v.Base().Same(s.Version.Base()) {
+			return false
+		}
+		return true
+	case OpGreaterThan:
+		if !v.GreaterThan(s.Version) {
+			return false
+		}
+		// >V does not match post-releases of V unless V is itself a
+		// post-release, and never matches local versions of V.
+		if !s.Version.IsPostrelease() && v.IsPostrelease() && v.Base().Same(s.Version.Base()) {
+			return false
+		}
+		if v.Local != "" && v.Base().Same(s.Version.Base()) {
+			return false
+		}
+		return true
+	case OpArbitrary:
+		return strings.EqualFold(v.String(), s.Text)
+	default:
+		panic(fmt.Sprintf("unsupported specifier operator %q", s.Operator))
+	}
+}
+
+func (s Specifier) equal(v Version) bool {
+	switch {
+	case s.Wildcard:
+		return prefixMatch(s.Version, v)
+	case s.Version.Local == "":
+		// Without a local version label in the specifier, the candidate's
+		// local version label is ignored.
+		return v.Public().Same(s.Version)
+	default:
+		return v.Same(s.Version)
+	}
+}
+
+// prefixMatch returns true if the given version has the same epoch as the
+// given prefix and its release segments, padded with zeros if necessary,
+// start with the release segments of the prefix.
+func prefixMatch(prefix, v Version) bool {
+	if v.Epoch != prefix.Epoch {
+		return false
+	}
+	for i, n := range prefix.Release {
+		var vn uint64
+		if i < len(v.Release) {
+			vn = v.Release[i]
+		}
+		if vn != n {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrereleases returns true if the receiving clause refers to a
+// pre-release in a way that PEP 440 treats as a request for pre-releases.
+func (s Specifier) allowsPrereleases() bool {
+	switch s.Operator {
+	case OpCompatible, OpEqual, OpLessThanOrEqual, OpGreaterThanOrEqual:
+		return s.Version.IsPrerelease()
+	case OpArbitrary:
+		v, err := Parse(s.Text)
+		return err == nil && v.IsPrerelease()
+	default:
+		return false
+	}
+}
+
+// Set returns a set containing the versions that match the receiving clause,
+// as defined by Has.
+//
+// Most clauses select a range of versions, but the operators > and === have
+// special rules that can't be written in terms of precedence alone, and so
+// their sets are built using versions.Matching. Sets that include them can't
+// be normalized, and so can't be compared using methods such as IsEmpty; use
+// IsNormalizable to check first.
+func (s Specifier) Set() versions.Set {
+	v := s.Version
+	switch s.Operator {
+	case OpCompatible:
+		prefix := v.Release[:len(v.Release)-1]
+		return versions.AtLeast(v).Intersection(prefixSet(v.Epoch, prefix))
+	case OpEqual:
+		return s.equalSet()
+	case OpNotEqual:
+		return versions.All.Subtract(s.equalSet())
+	case OpLessThanOrEqual:
+		return versions.OlderThan(publicSuccessor(v))
+	case OpGreaterThanOrEqual:
+		return versions.AtLeast(v)
+	case OpLessThan:
+		if v.IsPrerelease() {
+			return versions.OlderThan(v)
+		}
+		// The pre-releases of V's release are excluded, including the
+		// development releases of its post-releases, but its other
+		// post-releases are not.
+		start := releaseStart(v.Epoch, v.Release)
+		return versions.Union(
+			versions.OlderThan(start),
+			versions.Intersection(versions.AtLeast(start), versions.OlderThan(v), versions.Released),
+		)
+	default:
+		return versions.Matching(func(sv versions.SchemeVersion) bool {
+			v, ok := sv.(Version)
+			return ok && s.Has(v)
+		})
+	}
+}
+
+// equalSet returns the set of versions matched by an OpEqual clause with the
+// same version as the receiver.
+func (s Specifier) equalSet() versions.Set {
+	v := s.Version
+	switch {
+	case s.Wildcard:
+		return prefixSet(v.Epoch, v.Release)
+	case v.Local == "":
+		return versions.AtLeast(v).Intersection(versions.OlderThan(publicSuccessor(v)))
+	default:
+		return versions.Only(v)
+	}
+}
+
+// prefixSet returns the set of versions that have the given epoch and whose
+// release segments start with the given prefix, as defined by prefixMatch.
+func prefixSet(epoch uint64, prefix []uint64) versions.Set {
+	return versions.AtLeast(releaseStart(epoch, prefix)).Intersection(
+		versions.OlderThan(releaseStart(epoch, nextRelease(prefix, len(prefix)))),
+	)
+}
+
+// publicSuccessor returns the lowest version that is higher than the given
+// version without a local version label and than all of its local versions.
+func publicSuccessor(v Version) Version {
+	v.Local = ""
+	switch {
+	case v.HasDev:
+		v.Dev++
+	case v.HasPost:
+		v.Post++
+		v.Dev, v.HasDev = 0, true
+	default:
+		v.Post, v.HasPost = 0, true
+		v.Dev, v.HasDev = 0, true
+	}
+	return v
+}
+
+// String returns the receiver in the syntax accepted by ParseSpecifier.
+func (s Specifier) String() string {
+	switch {
+	case s.Operator == OpArbitrary:
+		return string(s.Operator) + s.Text
+	case s.Wildcard:
+		return string(s.Operator) + s.Version.String() + ".*"
+	default:
+		return string(s.Operator) + s.Version.String()
+	}
+}
+
+// String returns the receiver in the syntax accepted by ParseSpecifiers.
+func (s Specifiers) String() string {
+	parts := make([]string, len(s))
+	for i, spec := range s {
+		parts[i] = spec.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// MustParseSpecifier is the same as ParseSpecifier except that it will panic
+// instead of returning an error.
+func MustParseSpecifier(str string) Specifier {
+	spec, err := ParseSpecifier(str)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+// MeetingSpecifiers returns a version set that contains all of the versions
+// that match all of the given clauses.
+//
+// As PEP 440 requires, pre-releases and development releases are excluded
+// unless at least one of the clauses refers to such a version with one of
+// the operators ~=, ==, <=, >= or ===, as in ">=1.0b1". Use
+// MeetingSpecifiersExact to include pre-releases regardless.
+//
+// Some installers also accept a pre-release if no final release matches, but
+// that depends on the available versions rather than just the specifier and
+// so it is not represented here.
+func MeetingSpecifiers(specs Specifiers) versions.Set {
+	ret := MeetingSpecifiersExact(specs)
+	for _, spec := range specs {
+		if spec.allowsPrereleases() {
+			return ret
+		}
+	}
+	return versions.Intersection(ret, versions.Released)
+}
+
+// MeetingSpecifiersExact is like MeetingSpecifiers except that it includes
+// any pre-releases that match the clauses.
+func MeetingSpecifiersExact(specs Specifiers) versions.Set {
+	sets := make([]versions.Set, len(specs))
+	for i, spec := range specs {
+		sets[i] = spec.Set()
+	}
+	return versions.Intersection(sets...)
+}
+
+// MeetingSpecifiersString attempts to parse the given specifier string and
+// then passes the result to MeetingSpecifiers, returning the resulting set.
+func MeetingSpecifiersString(str string) (versions.Set, error) {
+	specs, err := ParseSpecifiers(str)
+	if err != nil {
+		return versions.None, err
+	}
+	return MeetingSpecifiers(specs), nil
+}
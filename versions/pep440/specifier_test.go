@@ -0,0 +1,267 @@
+package pep440
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+func TestParseSpecifiers(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr bool
+	}{
+		{"~=2.2", "~=2.2", false},
+		{"~= 1.4.5a4", "~=1.4.5a4", false},
+		{">=1.0, <2.0", ">=1.0, <2.0", false},
+		{"== 1.1.*", "==1.1.*", false},
+		{"!=1.3.4.*,>=1.0", "!=1.3.4.*, >=1.0", false},
+		{"==1.0+local", "==1.0+local", false},
+		{"===foobar", "===foobar", false},
+		{">1!2.0.dev1", ">1!2.0.dev1", false},
+
+		{"", "", true},
+		{"1.0", "", true},
+		{"=>1.0", "", true},
+		{">=", "", true},
+		{"~=1", "", true},
+		{"~=1.0.*", "", true},
+		{">=1.0.*", "", true},
+		{"==1.0a1.*", "", true},
+		{"<=1.0+local", "", true},
+		{">=1.0,", "", true},
+		{"==foo", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			specs, err := ParseSpecifiers(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %s", specs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := specs.String(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMeetingSpecifiers(t *testing.T) {
+	// Most of these cases are ported from the specifier tests in the Python
+	// "packaging" library.
+	tests := []struct {
+		Spec    string
+		Version string
+		Want    bool
+	}{
+		{"==2.0", "2.0", true},
+		{"==2.0", "2.0.0", true},
+		{"==2.0", "2.0+deadbeef", true},
+		{"==2.0+deadbeef", "2.0+deadbeef", true},
+		{"==2.0+deadbeef", "2.0", false},
+		{"==2.0", "2.1", false},
+		{"==2.*", "2.0", true},
+		{"==2.*", "2.1.5", true},
+		{"==2.*", "3.0", false},
+		{"==2.0.*", "2", true},
+		{"==2.0.*", "2.0.0.5", true},
+		{"==2.0.*", "2.1", false},
+		{"==2.0.*", "1!2.0", false},
+		{"==1!2.*", "1!2.0", true},
+		{"==2.0.*", "2.0a1", false},
+		{"!=2.0", "2.0", false},
+		{"!=2.0", "2.1", true},
+		{"!=2.0.*", "2.0.1", false},
+		{"!=2.0.*", "2.1", true},
+		{"~=2.0", "2.0", true},
+		{"~=2.0", "2.9", true},
+		{"~=2.0", "3.0", false},
+		{"~=2.2.0", "2.2.5", true},
+		{"~=2.2.0", "2.3", false},
+		{"~=2.2.post3", "2.2.post3", true},
+		{"~=2.2.post3", "2.2", false},
+		{">=2.0", "2.0", true},
+		{">=2.0", "2.0+local", true},
+		{">=2.0", "1.9", false},
+		{"<=2.0", "2.0+local", true},
+		{"<=2.0", "2.0.post1", false},
+		{"<2.0", "1.9", true},
+		{"<2.0", "2.0", false},
+		{"<2.0", "2.0.dev1", false},
+		{"<2.0", "2.0rc1", false},
+		{">=1.0a1, <2.0rc1", "2.0b1", true},
+		{">2.0", "2.1", true},
+		{">2.0", "2.0.post1", false},
+		{">2.0", "2.0+local", false},
+		{">2.0.post1", "2.0.post2", true},
+		{">2.0", "2.0.1", true},
+		{"===2.0", "2.0", true},
+		{"===2.0", "2.0.0", false},
+		{">=1.0, <2.0", "1.5", true},
+		{">=1.0, <2.0", "2.5", false},
+
+		// Pre-releases are excluded unless requested
+		{">=1.0", "2.0a1", false},
+		{">=1.0", "2.0.dev1", false},
+		{">=1.0a1", "2.0a1", true},
+		{">=1.0a1, <3.0", "2.0.dev1", true},
+		{"==2.0a1", "2.0a1", true},
+		{"<2.0a1", "1.0a1", false}, // < and > don't request pre-releases
+		{"<2.0", "1.0a1", false},
+		{">1.0a1", "2.0a1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s has %s", test.Spec, test.Version), func(t *testing.T) {
+			set, err := MeetingSpecifiersString(test.Spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := set.Has(MustParse(test.Version)), test.Want; got != want {
+				t.Errorf("wrong result\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
+
+func TestSpecifierSet(t *testing.T) {
+	specs := []string{
+		"~=2.2", "~=2.2.0", "~=1!2.2.post3",
+		"==2.0", "==2.0+local", "==2.0rc1", "==2.0.post1", "==2.0.dev1", "==2.*", "==2.0.*",
+		"!=2.0", "!=2.0.*",
+		"<=2.0", "<=2.0rc1.post2", "<=2.0.dev3",
+		">=2.0", ">=2.0b1",
+		"<2.0", "<2.0.post1", "<2.0rc1", "<2.0.dev1",
+		">2.0", ">2.0.post1",
+	}
+	vs := []string{
+		"1.9", "1.9.99", "1!2.2", "1!2.3.post4",
+		"2", "2.0", "2.0.0", "2.0.0.0.1", "2.0+local", "2.0+other",
+		"2.0.dev0", "2.0.dev1", "2.0.dev2", "2.0a1", "2.0rc1", "2.0rc1.dev1",
+		"2.0rc1+local", "2.0rc1.post2", "2.0rc1.post3.dev0",
+		"2.0.post0.dev0", "2.0.post0", "2.0.post1", "2.0.post1+local", "2.0.post2.dev0",
+		"2.0.1", "2.1.dev0", "2.1", "2.2", "2.2.post3", "2.2.5", "2.3", "2.9", "3.0.dev0", "3.0",
+	}
+
+	for _, specStr := range specs {
+		spec := MustParseSpecifier(specStr)
+		set := spec.Set()
+		for _, vStr := range vs {
+			v := MustParse(vStr)
+			if got, want := set.Has(v), spec.Has(v); got != want {
+				t.Errorf("wrong result for %s has %s\nset:  %#v\ngot:  %t\nwant: %t", specStr, vStr, set, got, want)
+			}
+		}
+	}
+}
+
+func TestSpecifierSetCompare(t *testing.T) {
+	tests := []struct {
+		A, B     string
+		Subset   bool // A is a subset of B
+		Overlaps bool
+	}{
+		{"~=1.4.2", ">=1.4, <1.5", true, true},
+		{"~=1.4", "==1.*", true, true},
+		{">=1.4, <1.5", "~=1.4.2", false, true},
+		{"==1.4.*", "!=1.4.*", false, false},
+		{"<=1.0", "<1.0", false, true},
+		{"<1.0.post1", "<=1.0", false, true},
+		{"==1.0", "==1.0.0.*", true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s and %s", test.A, test.B), func(t *testing.T) {
+			a, err := MeetingSpecifiersString(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := MeetingSpecifiersString(test.B)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := a.IsSubsetOf(b), test.Subset; got != want {
+				t.Errorf("wrong IsSubsetOf result\ngot:  %t\nwant: %t", got, want)
+			}
+			if got, want := a.Overlaps(b), test.Overlaps; got != want {
+				t.Errorf("wrong Overlaps result\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+
+	set, err := MeetingSpecifiersString(">1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.IsNormalizable() {
+		t.Errorf("set for >1.0 is normalizable, so it can be compared; want it to select versions using a function")
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	l := versions.List{
+		MustParse("0.9"),
+		MustParse("1.0"),
+		MustParse("1.1b1"),
+		MustParse("1.1"),
+		MustParse("2.0"),
+	}
+	set := versions.Intersection(
+		MeetingSpecifiers(Specifiers{MustParseSpecifier(">=1.0")}),
+		MeetingSpecifiers(Specifiers{MustParseSpecifier("<2")}),
+	)
+	if got, want := l.NewestInSet(set).String(), "1.1"; got != want {
+		t.Errorf("wrong newest version\ngot:  %s\nwant: %s", got, want)
+	}
+
+	got := l.Filter(set)
+	want := []string{"1.0", "1.1"}
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of results\ngot:  %s\nwant: %s", got, want)
+	}
+	for i, v := range got {
+		if v.String() != want[i] {
+			t.Errorf("wrong version at index %d\ngot:  %s\nwant: %s", i, v, want[i])
+		}
+	}
+}
+
+func TestMeetingConstraintsWithScheme(t *testing.T) {
+	tests := []struct {
+		Constraints string
+		Version     string
+		Want        bool
+	}{
+		{"^1.4.2", "1.9", true},
+		{"^1.4.2", "2.0.dev0", false},
+		{"~1.4.2", "1.4.9.post1", true},
+		{"~1.4.2", "1.5", false},
+		{"~1.4.2", "1.4.3rc1", false},
+		{"~1.4.2", "1.4.1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s has %s", test.Constraints, test.Version), func(t *testing.T) {
+			spec, err := constraints.Parse(test.Constraints)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set, err := versions.MeetingConstraintsWithScheme(spec, Scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := set.Has(MustParse(test.Version)), test.Want; got != want {
+				t.Errorf("wrong result\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
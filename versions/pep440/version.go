@@ -0,0 +1,446 @@
+package pep440
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version represents a single PEP 440 version.
+//
+// The zero value of Version is Unspecified. Versions created by Parse are
+// always normalized, so that for example the version written as "1.0-ALPHA"
+// has Pre set to Alpha and PreNum set to zero.
+type Version struct {
+	// Epoch is the number before a "!", which is zero if not specified.
+	Epoch uint64
+
+	// Release is the dot-separated sequence of release numbers, which has
+	// at least one element for any version returned by Parse.
+	Release []uint64
+
+	// Pre is the pre-release phase, or the empty string if this is not a
+	// pre-release. PreNum is the number following the phase.
+	Pre    Phase
+	PreNum uint64
+
+	// HasPost is true if this is a post-release, with Post then being the
+	// post-release number.
+	Post    uint64
+	HasPost bool
+
+	// HasDev is true if this is a development release, with Dev then being
+	// the development release number.
+	Dev    uint64
+	HasDev bool
+
+	// Local is the normalized local version label, which follows a "+" in
+	// the string representation, or the empty string if there is none.
+	Local string
+}
+
+// Phase is the phase of a pre-release version.
+type Phase string
+
+const (
+	Alpha            Phase = "a"
+	Beta             Phase = "b"
+	ReleaseCandidate Phase = "rc"
+)
+
+// Unspecified is the zero value of Version and represents the absense of a
+// version number.
+var Unspecified Version
+
+// versionPattern is the regular expression that PEP 440 itself recommends for
+// recognizing versions that can be normalized, including all of the
+// alternative spellings that normalization allows.
+var versionPattern = regexp.MustCompile(`(?i)^\s*v?` +
+	`(?:([0-9]+)!)?` + // epoch
+	`([0-9]+(?:\.[0-9]+)*)` + // release
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?([0-9]+)?)?` + // pre-release
+	`(?:-([0-9]+)|[-_.]?(post|rev|r)[-_.]?([0-9]+)?)?` + // post-release
+	`(?:[-_.]?(dev)[-_.]?([0-9]+)?)?` + // development release
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?` + // local version label
+	`\s*$`)
+
+// Parse attempts to parse the given string as a PEP 440 version, and returns
+// the normalized version if successful.
+//
+// All of the alternative spellings that PEP 440 permits are accepted, such as
+// a "v" prefix, "alpha" in place of "a" and "-" in place of ".post".
+//
+// If the given string is not parseable then an error is returned that is
+// suitable for display directly to a hypothetical end-user that provided this
+// version string, as long as they can read English.
+func Parse(s string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(s)
+	if m == nil {
+		if strings.TrimSpace(s) == "" {
+			return Unspecified, fmt.Errorf("empty version string")
+		}
+		return Unspecified, fmt.Errorf("invalid version %q; must be a version number as defined in PEP 440", s)
+	}
+
+	var v Version
+	var err error
+	if m[1] != "" {
+		v.Epoch, err = parseNum(m[1], "epoch")
+		if err != nil {
+			return Unspecified, err
+		}
+	}
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := parseNum(part, "release")
+		if err != nil {
+			return Unspecified, err
+		}
+		v.Release = append(v.Release, n)
+	}
+	if m[3] != "" {
+		switch strings.ToLower(m[3]) {
+		case "a", "alpha":
+			v.Pre = Alpha
+		case "b", "beta":
+			v.Pre = Beta
+		default:
+			v.Pre = ReleaseCandidate
+		}
+		if m[4] != "" {
+			v.PreNum, err = parseNum(m[4], "pre-release")
+			if err != nil {
+				return Unspecified, err
+			}
+		}
+	}
+	switch {
+	case m[5] != "":
+		v.HasPost = true
+		v.Post, err = parseNum(m[5], "post-release")
+	case m[6] != "":
+		v.HasPost = true
+		if m[7] != "" {
+			v.Post, err = parseNum(m[7], "post-release")
+		}
+	}
+	if err != nil {
+		return Unspecified, err
+	}
+	if m[8] != "" {
+		v.HasDev = true
+		if m[9] != "" {
+			v.Dev, err = parseNum(m[9], "development release")
+			if err != nil {
+				return Unspecified, err
+			}
+		}
+	}
+	if m[10] != "" {
+		v.Local = normalizeLocal(m[10])
+	}
+	return v, nil
+}
+
+// MustParse is the same as Parse except that it will panic instead of
+// returning an error.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func parseNum(s string, what string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s number %s is too large", what, s)
+	}
+	return n, nil
+}
+
+// normalizeLocal converts a local version label into its normalized form,
+// which is lowercase with "." as the only separator and with no leading
+// zeros on numeric segments.
+func normalizeLocal(s string) string {
+	parts := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for i, part := range parts {
+		if isNumeric(part) {
+			part = strings.TrimLeft(part, "0")
+			if part == "" {
+				part = "0"
+			}
+			parts[i] = part
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// String is an implementation of fmt.Stringer that returns the receiver
+// in the normalized form defined by PEP 440.
+func (v Version) String() string {
+	var buf bytes.Buffer
+	if v.Epoch != 0 {
+		fmt.Fprintf(&buf, "%d!", v.Epoch)
+	}
+	buf.WriteString(v.releaseString())
+	if v.Pre != "" {
+		fmt.Fprintf(&buf, "%s%d", v.Pre, v.PreNum)
+	}
+	if v.HasPost {
+		fmt.Fprintf(&buf, ".post%d", v.Post)
+	}
+	if v.HasDev {
+		fmt.Fprintf(&buf, ".dev%d", v.Dev)
+	}
+	if v.Local != "" {
+		fmt.Fprintf(&buf, "+%s", v.Local)
+	}
+	return buf.String()
+}
+
+func (v Version) releaseString() string {
+	if len(v.Release) == 0 {
+		return "0"
+	}
+	parts := make([]string, len(v.Release))
+	for i, n := range v.Release {
+		parts[i] = strconv.FormatUint(n, 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+func (v Version) GoString() string {
+	return fmt.Sprintf("pep440.MustParse(%q)", v.String())
+}
+
+// IsPrerelease returns true if the receiver is a pre-release or a
+// development release, which PEP 440 both treats as pre-releases.
+func (v Version) IsPrerelease() bool {
+	return v.Pre != "" || v.HasDev
+}
+
+// IsPostrelease returns true if the receiver is a post-release.
+func (v Version) IsPostrelease() bool {
+	return v.HasPost
+}
+
+// Public returns the receiver with its local version label removed.
+func (v Version) Public() Version {
+	v.Local = ""
+	return v
+}
+
+// Base returns a version with only the epoch and release segments of the
+// receiver, without any pre-release, post-release, development release or
+// local version label.
+func (v Version) Base() Version {
+	return Version{
+		Epoch:   v.Epoch,
+		Release: v.Release,
+	}
+}
+
+// Compare returns -1, 0 or 1 depending on whether the receiver has lower,
+// the same or higher precedence than the other given version, using the
+// ordering defined by PEP 440.
+//
+// Release segments are compared as if the shorter of the two were padded
+// with zeros, so 1.0 and 1.0.0 have the same precedence.
+func (v Version) Compare(other Version) int {
+	if c := compareNums(v.Epoch, other.Epoch); c != 0 {
+		return c
+	}
+	if c := compareRelease(v.Release, other.Release); c != 0 {
+		return c
+	}
+	if c := comparePre(v, other); c != 0 {
+		return c
+	}
+
+	// A version without a post-release sorts before any with one.
+	switch {
+	case v.HasPost != other.HasPost:
+		if v.HasPost {
+			return 1
+		}
+		return -1
+	case v.HasPost:
+		if c := compareNums(v.Post, other.Post); c != 0 {
+			return c
+		}
+	}
+
+	// A version without a development release sorts after any with one.
+	switch {
+	case v.HasDev != other.HasDev:
+		if v.HasDev {
+			return -1
+		}
+		return 1
+	case v.HasDev:
+		if c := compareNums(v.Dev, other.Dev); c != 0 {
+			return c
+		}
+	}
+
+	return compareLocal(v.Local, other.Local)
+}
+
+// LessThan returns true if the receiver has a lower precedence than the
+// other given version, as defined by PEP 440.
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// GreaterThan returns true if the receiver has a higher precedence than the
+// other given version, as defined by PEP 440.
+func (v Version) GreaterThan(other Version) bool {
+	return v.Compare(other) > 0
+}
+
+// Same returns true if the receiver has the same precedence as the other
+// given version. Unlike with semantic versions, the local version label
+// does participate in precedence, so versions are the same only if they have
+// the same local version label.
+func (v Version) Same(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// MarshalText is an implementation of encoding.TextMarshaler, allowing versions
+// to be automatically marshalled for text-based serialization formats,
+// including encoding/json.
+//
+// The format used is that returned by String, which can be parsed using
+// Parse.
+func (v Version) MarshalText() (text []byte, err error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText is an implementation of encoding.TextUnmarshaler, allowing
+// versions to be automatically unmarshalled from strings in text-based
+// serialization formats, including encoding/json.
+//
+// The format expected is what is accepted by Parse. Any parser errors
+// are passed on verbatim to the caller.
+func (v *Version) UnmarshalText(text []byte) error {
+	new, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = new
+	return nil
+}
+
+func compareNums(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareRelease(a, b []uint64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var an, bn uint64
+		if i < len(a) {
+			an = a[i]
+		}
+		if i < len(b) {
+			bn = b[i]
+		}
+		if c := compareNums(an, bn); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// comparePre compares the pre-release portions of the given versions. A
+// development release of a version with no pre-release or post-release sorts
+// before all of that version's pre-releases, and a version with no
+// pre-release sorts after all of them.
+func comparePre(a, b Version) int {
+	if c := compareNums(preRank(a), preRank(b)); c != 0 {
+		return c
+	}
+	if a.Pre == "" {
+		return 0
+	}
+	if c := compareNums(phaseRank[a.Pre], phaseRank[b.Pre]); c != 0 {
+		return c
+	}
+	return compareNums(a.PreNum, b.PreNum)
+}
+
+func preRank(v Version) uint64 {
+	switch {
+	case v.Pre == "" && !v.HasPost && v.HasDev:
+		return 0
+	case v.Pre != "":
+		return 1
+	default:
+		return 2
+	}
+}
+
+var phaseRank = map[Phase]uint64{
+	Alpha:            0,
+	Beta:             1,
+	ReleaseCandidate: 2,
+}
+
+// compareLocal compares two normalized local version labels. No label sorts
+// before any label, numeric segments sort after alphanumeric segments, and a
+// label that is a prefix of another sorts before it.
+func compareLocal(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareLocalPart(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareNums(uint64(len(as)), uint64(len(bs)))
+}
+
+func compareLocalPart(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		if c := compareNums(uint64(len(a)), uint64(len(b))); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNum:
+		return 1
+	case bNum:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
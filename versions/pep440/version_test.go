@@ -0,0 +1,132 @@
+package pep440
+
+import (
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    string
+		WantErr bool
+	}{
+		{"1.0", "1.0", false},
+		{"v1.0", "1.0", false},
+		{" 1.0 ", "1.0", false},
+		{"1!2.0", "1!2.0", false},
+		{"0!1.0", "1.0", false},
+		{"1.0a1", "1.0a1", false},
+		{"1.0-ALPHA.1", "1.0a1", false},
+		{"1.0beta", "1.0b0", false},
+		{"1.0c3", "1.0rc3", false},
+		{"1.0pre2", "1.0rc2", false},
+		{"1.0preview_2", "1.0rc2", false},
+		{"1.0.post1", "1.0.post1", false},
+		{"1.0-1", "1.0.post1", false},
+		{"1.0rev", "1.0.post0", false},
+		{"1.0r2", "1.0.post2", false},
+		{"1.0.dev", "1.0.dev0", false},
+		{"1.0-dev3", "1.0.dev3", false},
+		{"1.0a1.post2.dev3", "1.0a1.post2.dev3", false},
+		{"1.0+Ubuntu-1", "1.0+ubuntu.1", false},
+		{"1.0+abc_007", "1.0+abc.7", false},
+		{"01.002", "1.2", false},
+
+		{"", "", true},
+		{"foo", "", true},
+		{"1.0+", "", true},
+		{"1.0.", "", true},
+		{"1.0a1a2", "", true},
+		{"1.0+abc+def", "", true},
+		{"1.99999999999999999999", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			v, err := Parse(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %s", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got, want := v.String(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Each version in this list has lower precedence than the one after it,
+	// based on the ordering examples in PEP 440 and the test suite of the
+	// Python "packaging" library.
+	ordered := []string{
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0+abc.7",
+		"1.0+5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.0.15",
+		"1.1.dev1",
+		"2.0",
+		"1!0.1",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, b := MustParse(ordered[i]), MustParse(ordered[j])
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("wrong result for %s compared with %s\ngot:  %d\nwant: %d", a, b, got, want)
+			}
+		}
+	}
+
+	if !MustParse("1.0").Same(MustParse("1.0.0")) {
+		t.Errorf("1.0 and 1.0.0 should have the same precedence")
+	}
+}
+
+func TestListSort(t *testing.T) {
+	l := versions.List{
+		MustParse("2.0"),
+		MustParse("1.0.post1"),
+		MustParse("1.0rc1"),
+		MustParse("1.0"),
+	}
+	l.Sort()
+
+	want := []string{"1.0rc1", "1.0", "1.0.post1", "2.0"}
+	for i, v := range l {
+		if got := v.String(); got != want[i] {
+			t.Errorf("wrong version at index %d\ngot:  %s\nwant: %s", i, got, want[i])
+		}
+	}
+	if got, want := l.Newest().String(), "2.0"; got != want {
+		t.Errorf("wrong newest version\ngot:  %s\nwant: %s", got, want)
+	}
+}
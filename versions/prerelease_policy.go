@@ -0,0 +1,156 @@
+package versions
+
+import (
+	"fmt"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// PrereleasePolicy decides which pre-release versions are acceptable to a
+// constraint spec, for use with MeetingConstraintsWithPolicy.
+//
+// A policy is a function that is given the spec and the set of versions that
+// meet it exactly, as returned by MeetingConstraintsExact, and returns a set
+// containing the pre-release versions that may be members of the result.
+// Any released versions in the returned set are ignored, so a policy can be
+// built from whatever set operations are most convenient.
+//
+// The policies declared in this package can be combined using the Union and
+// Intersection methods, and applications can also define their own.
+type PrereleasePolicy func(spec constraints.Spec, exact Set) Set
+
+// Union returns a policy that accepts any pre-release version that is
+// accepted by the receiver or by any of the other given policies.
+func (p PrereleasePolicy) Union(others ...PrereleasePolicy) PrereleasePolicy {
+	return func(spec constraints.Spec, exact Set) Set {
+		sets := make([]Set, 0, len(others)+1)
+		sets = append(sets, p(spec, exact))
+		for _, other := range others {
+			sets = append(sets, other(spec, exact))
+		}
+		return Union(sets...)
+	}
+}
+
+// Intersection returns a policy that accepts only pre-release versions that
+// are accepted by the receiver and by all of the other given policies.
+func (p PrereleasePolicy) Intersection(others ...PrereleasePolicy) PrereleasePolicy {
+	return func(spec constraints.Spec, exact Set) Set {
+		sets := make([]Set, 0, len(others)+1)
+		sets = append(sets, p(spec, exact))
+		for _, other := range others {
+			sets = append(sets, other(spec, exact))
+		}
+		return Intersection(sets...)
+	}
+}
+
+// PrereleasesRequested is a policy that accepts only the pre-release versions
+// that are explicitly requested by exact selections in the spec, such as
+// 2.0.0-beta1 in "2.0.0-beta1 || >2". This is the policy that
+// MeetingConstraints uses.
+var PrereleasesRequested PrereleasePolicy = func(spec constraints.Spec, exact Set) Set {
+	return Selection(exact.AllRequested().List().Filter(Prerelease)...)
+}
+
+// PrereleasesSameTuple is a policy that accepts pre-release versions of the
+// same major, minor and patch version as a pre-release boundary in the spec,
+// similar to the rules of npm and Cargo. For example, ">1.2.3-alpha.3"
+// accepts 1.2.3-alpha.7 but not 3.4.5-alpha.9.
+//
+// Unlike MeetingConstraintsNPM, this policy considers the boundaries of all
+// of the selections in the spec together, rather than only those in the same
+// selection set as each other.
+var PrereleasesSameTuple PrereleasePolicy = func(spec constraints.Spec, exact Set) Set {
+	var sets []Set
+	forEachBoundary(spec, func(boundary constraints.VersionSpec) {
+		if boundary.Prerelease != "" {
+			sets = append(sets, prereleaseTuple(boundary))
+		}
+	})
+	return Union(sets...)
+}
+
+// PrereleasesAll is a policy that accepts all pre-release versions, giving
+// the same members as MeetingConstraintsExact.
+var PrereleasesAll PrereleasePolicy = func(spec constraints.Spec, exact Set) Set {
+	return Prerelease
+}
+
+// PrereleasesAfterNewestRelease returns a policy that accepts only
+// pre-release versions that are newer than the newest released version in
+// the given list that meets the spec, so that a pre-release is chosen only if
+// it is the most recent thing available. If none of the released versions in
+// the list meet the spec then all pre-release versions are accepted.
+func PrereleasesAfterNewestRelease(available List) PrereleasePolicy {
+	return func(spec constraints.Spec, exact Set) Set {
+		newest := available.NewestInSet(Intersection(Released, exact))
+		if newest == Unspecified {
+			return Prerelease
+		}
+		return NewerThan(newest)
+	}
+}
+
+// PrereleasesInChannel returns a policy that accepts only pre-release
+// versions in the given channel, as described for PrereleaseChannel.
+//
+// This is usually combined with another policy using Intersection. On its
+// own it accepts all versions in the channel that meet the spec.
+func PrereleasesInChannel(channel string) PrereleasePolicy {
+	return func(spec constraints.Spec, exact Set) Set {
+		return PrereleaseChannel(channel)
+	}
+}
+
+// MeetingConstraintsWithPolicy is like MeetingConstraints except that the
+// given policy decides which pre-release versions are members of the result.
+// Released versions are members if they meet the constraints, regardless of
+// the policy.
+//
+// For example, the following selects the pre-releases that npm would, but
+// only in the "rc" channel:
+//
+//	versions.MeetingConstraintsWithPolicy(spec, versions.PrereleasesSameTuple.Intersection(
+//	    versions.PrereleasesInChannel("rc"),
+//	))
+func MeetingConstraintsWithPolicy(spec constraints.Spec, policy PrereleasePolicy) Set {
+	exact := MeetingConstraintsExact(spec)
+	return Intersection(exact, Union(Released, policy(spec, exact)))
+}
+
+// forEachBoundary calls the given function with the boundary of each of the
+// selections in the given spec.
+func forEachBoundary(spec constraints.Spec, fn func(constraints.VersionSpec)) {
+	switch ts := spec.(type) {
+	case nil:
+		// No selections at all, then.
+	case constraints.UnionSpec:
+		for _, subSpec := range ts {
+			forEachBoundary(subSpec, fn)
+		}
+	case constraints.IntersectionSpec:
+		for _, sel := range ts {
+			fn(sel.Boundary)
+		}
+	case constraints.SelectionSpec:
+		fn(ts.Boundary)
+	case constraints.VersionSpec:
+		fn(ts)
+	default:
+		// should never happen because the above cases are exhaustive for
+		// all valid constraint implementations.
+		panic(fmt.Errorf("unsupported constraints.Spec implementation %T", spec))
+	}
+}
+
+// prereleaseTuple returns the set of all pre-release versions of the same
+// major, minor and patch version as the given boundary.
+func prereleaseTuple(boundary constraints.VersionSpec) Set {
+	final := versionFromExactVersionSpec(boundary.ConstrainToZero())
+	final.Prerelease = ""
+	final.Metadata = ""
+	first := final
+	first.Prerelease = "0" // the lowest possible pre-release
+	return Intersection(AtLeast(first), OlderThan(final))
+}
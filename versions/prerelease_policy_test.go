@@ -0,0 +1,139 @@
+package versions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+func TestMeetingConstraintsWithPolicy(t *testing.T) {
+	available := List{
+		MustParseVersion("1.0.0"),
+		MustParseVersion("1.1.0-beta.1"),
+		MustParseVersion("1.1.0-rc.1"),
+		MustParseVersion("1.1.0"),
+		MustParseVersion("1.2.0-nightly.5"),
+		MustParseVersion("1.2.0-beta.1"),
+		MustParseVersion("1.2.0-rc.1"),
+		MustParseVersion("2.0.0-rc.1"),
+	}
+
+	tests := []struct {
+		Spec   string
+		Policy PrereleasePolicy
+		Want   []string
+	}{
+		{
+			">=1.0.0 <2.0.0",
+			PrereleasesRequested,
+			[]string{"1.0.0", "1.1.0"},
+		},
+		{
+			">=1.0.0 <2.0.0 || 1.2.0-beta.1",
+			PrereleasesRequested,
+			[]string{"1.0.0", "1.1.0", "1.2.0-beta.1"},
+		},
+		{
+			">=1.1.0-beta.1 <2.0.0",
+			PrereleasesSameTuple,
+			[]string{"1.1.0-beta.1", "1.1.0-rc.1", "1.1.0"},
+		},
+		{
+			// Pre-releases of 2.0.0 precede it, and so meet "<2.0.0".
+			">=1.0.0 <2.0.0",
+			PrereleasesAll,
+			[]string{"1.0.0", "1.1.0-beta.1", "1.1.0-rc.1", "1.1.0", "1.2.0-nightly.5", "1.2.0-beta.1", "1.2.0-rc.1", "2.0.0-rc.1"},
+		},
+		{
+			">=1.0.0",
+			PrereleasesAfterNewestRelease(available),
+			[]string{"1.0.0", "1.1.0", "1.2.0-nightly.5", "1.2.0-beta.1", "1.2.0-rc.1", "2.0.0-rc.1"},
+		},
+		{
+			// 1.1.0 doesn't meet the spec, so its pre-releases are newer
+			// than the newest release that does.
+			">=1.0.0 <1.1.0",
+			PrereleasesAfterNewestRelease(available),
+			[]string{"1.0.0", "1.1.0-beta.1", "1.1.0-rc.1"},
+		},
+		{
+			">=1.0.0 <=1.1.0",
+			PrereleasesAfterNewestRelease(available),
+			[]string{"1.0.0", "1.1.0"},
+		},
+		{
+			">=2.0.0-a",
+			PrereleasesAfterNewestRelease(available),
+			[]string{"2.0.0-rc.1"},
+		},
+		{
+			">=1.0.0",
+			PrereleasesInChannel("rc"),
+			[]string{"1.0.0", "1.1.0-rc.1", "1.1.0", "1.2.0-rc.1", "2.0.0-rc.1"},
+		},
+		{
+			">=1.0.0",
+			PrereleasesAfterNewestRelease(available).Intersection(PrereleasesInChannel("rc")),
+			[]string{"1.0.0", "1.1.0", "1.2.0-rc.1", "2.0.0-rc.1"},
+		},
+		{
+			">=1.0.0 || 1.1.0-beta.1",
+			PrereleasesRequested.Union(PrereleasesInChannel("nightly")),
+			[]string{"1.0.0", "1.1.0-beta.1", "1.1.0", "1.2.0-nightly.5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Spec, func(t *testing.T) {
+			spec, err := constraints.Parse(test.Spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set := MeetingConstraintsWithPolicy(spec, test.Policy)
+			// Filter reuses the receiver's backing array, so we must filter
+			// a copy to keep the available versions intact.
+			got := append(List(nil), available...).Filter(set)
+
+			want := make(List, len(test.Want))
+			for i, s := range test.Want {
+				want[i] = MustParseVersion(s)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestPrereleasesRequestedMatchesMeetingConstraints(t *testing.T) {
+	specs := []string{
+		"^1.0.0",
+		">=1.0.0 <2.0.0 || 1.2.0-beta.1",
+		"1.1.0-rc.1",
+		"!1.1.0",
+	}
+	versions := List{
+		MustParseVersion("1.0.0"),
+		MustParseVersion("1.1.0-rc.1"),
+		MustParseVersion("1.1.0"),
+		MustParseVersion("1.2.0-beta.1"),
+		MustParseVersion("1.2.0-beta.2"),
+	}
+
+	for _, str := range specs {
+		t.Run(str, func(t *testing.T) {
+			spec, err := constraints.Parse(str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := MeetingConstraints(spec)
+			got := MeetingConstraintsWithPolicy(spec, PrereleasesRequested)
+			for _, v := range versions {
+				if got.Has(v) != want.Has(v) {
+					t.Errorf("wrong membership for %s: got %t, want %t", v, got.Has(v), want.Has(v))
+				}
+			}
+		})
+	}
+}
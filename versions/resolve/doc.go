@@ -0,0 +1,11 @@
+// Package resolve contains a dependency resolver that selects a consistent
+// set of package versions given some root requirements and a source of
+// information about the available packages.
+//
+// The resolver uses the "PubGrub" algorithm, as described at
+// https://github.com/dart-lang/pub/blob/master/doc/solver.md , with the
+// version sets from package "versions" serving as the set algebra for the
+// algorithm's terms. When no solution exists, the resolver returns an error
+// that describes the chain of incompatibilities that led to the failure in a
+// form suitable for display to an end-user.
+package resolve
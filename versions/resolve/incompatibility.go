@@ -0,0 +1,206 @@
+package resolve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// Incompatibility is a set of terms that must not all be true at the same
+// time for a selection of package versions to be valid.
+//
+// Incompatibilities are the building blocks of the resolver's explanation of
+// why no solution exists. The Cause of each incompatibility describes why it
+// must hold, which may be due to other incompatibilities.
+type Incompatibility struct {
+	Terms []Term
+	Cause Cause
+}
+
+// Cause is an interface implemented by the possible reasons for an
+// incompatibility. It is a closed interface whose implementations are
+// RootCause, DependencyCause, NoVersionsCause and ConflictCause.
+type Cause interface {
+	isCause()
+}
+
+// RootCause is the cause of the incompatibility that requires the root
+// requirements to be selected.
+type RootCause struct{}
+
+// DependencyCause is the cause of an incompatibility that represents a
+// dependency from one package version to a range of versions of another
+// package, or from the root requirements to a range of versions of a package.
+type DependencyCause struct {
+	// Sources are the sources of the constraint selections that the
+	// dependency was declared with, as returned by versions.Set.Sources.
+	// Only root requirements have sources, and only if they were given to
+	// SolveWithSources. Messages about the dependency mention the files the
+	// constraints came from, if the caller recorded them using
+	// constraints.Sources.WithFilename.
+	Sources []constraints.Source
+}
+
+// NoVersionsCause is the cause of an incompatibility that represents that
+// the source has no versions of a package matching a particular set.
+type NoVersionsCause struct{}
+
+// ConflictCause is the cause of an incompatibility that was derived from two
+// other incompatibilities during conflict resolution.
+type ConflictCause struct {
+	Conflict *Incompatibility
+	Other    *Incompatibility
+}
+
+func (RootCause) isCause()       {}
+func (DependencyCause) isCause() {}
+func (NoVersionsCause) isCause() {}
+func (ConflictCause) isCause()   {}
+
+// newIncompatibility constructs an incompatibility with the given terms,
+// combining any terms that refer to the same package.
+func newIncompatibility(terms []Term, cause Cause) *Incompatibility {
+	if _, conflict := cause.(ConflictCause); conflict && len(terms) != 1 {
+		// A derived incompatibility that mentions the root requirements
+		// positively is true whenever its other terms are, because the
+		// root requirements are always selected.
+		filtered := make([]Term, 0, len(terms))
+		for _, t := range terms {
+			if !(t.Positive && t.Package == rootPackage) {
+				filtered = append(filtered, t)
+			}
+		}
+		terms = filtered
+	}
+
+	if len(terms) > 2 || (len(terms) == 2 && terms[0].Package == terms[1].Package) {
+		var order []string
+		byPackage := make(map[string]Term, len(terms))
+		for _, t := range terms {
+			if existing, exists := byPackage[t.Package]; exists {
+				byPackage[t.Package] = existing.intersect(t)
+				continue
+			}
+			order = append(order, t.Package)
+			byPackage[t.Package] = t
+		}
+		terms = make([]Term, len(order))
+		for i, pkg := range order {
+			terms[i] = byPackage[pkg]
+		}
+	}
+
+	return &Incompatibility{
+		Terms: terms,
+		Cause: cause,
+	}
+}
+
+// isFailure returns true if the receiver means that no solution can exist.
+func (i *Incompatibility) isFailure() bool {
+	return len(i.Terms) == 0 || (len(i.Terms) == 1 && i.Terms[0].Positive && i.Terms[0].Package == rootPackage)
+}
+
+func (i *Incompatibility) String() string {
+	switch cause := i.Cause.(type) {
+	case DependencyCause:
+		if len(i.Terms) == 2 {
+			depender, dependee := i.Terms[0], i.Terms[1]
+			if depender.Package == rootPackage {
+				return fmt.Sprintf("the root requirements include %s%s", dependee.terse(), formatSources(cause.Sources))
+			}
+			return fmt.Sprintf("%s depends on %s%s", depender.terse(), dependee.terse(), formatSources(cause.Sources))
+		}
+	case NoVersionsCause:
+		if len(i.Terms) == 1 {
+			t := i.Terms[0]
+			return fmt.Sprintf("no versions of %s match %s", t.Package, formatSet(t.Versions))
+		}
+	}
+
+	if i.isFailure() {
+		return "version solving failed"
+	}
+
+	if len(i.Terms) == 1 {
+		t := i.Terms[0]
+		if t.Positive {
+			return fmt.Sprintf("%s is forbidden", t.terse())
+		}
+		return fmt.Sprintf("%s is required", t.terse())
+	}
+
+	if len(i.Terms) == 2 && i.Terms[0].Positive == i.Terms[1].Positive {
+		t1, t2 := i.Terms[0], i.Terms[1]
+		if t1.Positive {
+			return fmt.Sprintf("%s is incompatible with %s", t1.terse(), t2.terse())
+		}
+		return fmt.Sprintf("either %s or %s", t1.terse(), t2.terse())
+	}
+
+	var positive, negative []string
+	for _, t := range i.Terms {
+		if t.Positive {
+			positive = append(positive, t.terse())
+		} else {
+			negative = append(negative, t.terse())
+		}
+	}
+	switch {
+	case len(positive) == 1 && len(negative) != 0:
+		return fmt.Sprintf("%s requires %s", positive[0], strings.Join(negative, " or "))
+	case len(positive) != 0 && len(negative) != 0:
+		return fmt.Sprintf("if %s then %s", strings.Join(positive, " and "), strings.Join(negative, " or "))
+	case len(positive) != 0:
+		return fmt.Sprintf("one of %s must be false", strings.Join(positive, " or "))
+	default:
+		return fmt.Sprintf("one of %s must be true", strings.Join(negative, " or "))
+	}
+}
+
+// dependencyIncompatibilities returns the incompatibilities that represent
+// the given requirements of the given version of a package, whose sources
+// are given by the map sources if it is non-nil.
+func dependencyIncompatibilities(pkg string, depender versions.Set, reqs Requirements, sources RequirementSources) []*Incompatibility {
+	names := make([]string, 0, len(reqs))
+	for name := range reqs {
+		if name == pkg {
+			// A package depending on itself is meaningless, since only
+			// one version of a package can be selected.
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ret := make([]*Incompatibility, len(names))
+	for i, name := range names {
+		// newTerm normalizes the set, which discards its sources, so we
+		// must collect them first.
+		set := versions.MeetingConstraintsWithSources(reqs[name], sources[name])
+		ret[i] = newIncompatibility([]Term{
+			newTerm(pkg, depender, true),
+			newTerm(name, set, false),
+		}, DependencyCause{Sources: set.Sources()})
+	}
+	return ret
+}
+
+// formatSources returns a suffix for a message about a dependency that names
+// the files that the dependency's constraints came from, or an empty string
+// if none of the given sources have a filename.
+func formatSources(sources []constraints.Source) string {
+	var parts []string
+	for _, src := range sources {
+		if src.Filename != "" {
+			parts = append(parts, src.String())
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (from %s)", strings.Join(parts, ", "))
+}
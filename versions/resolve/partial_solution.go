@@ -0,0 +1,175 @@
+package resolve
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// assignment is a single entry in a partial solution, which is either a
+// decision to select a particular package version or a term derived from
+// an incompatibility.
+type assignment struct {
+	Term
+	decisionLevel int
+	index         int
+
+	// cause is the incompatibility that the term was derived from, or nil
+	// if the assignment is a decision.
+	cause *Incompatibility
+}
+
+func (a *assignment) isDecision() bool {
+	return a.cause == nil
+}
+
+// partialSolution is the resolver's current set of assignments, which may or
+// may not eventually become a complete solution.
+type partialSolution struct {
+	assignments []*assignment
+	decisions   map[string]versions.Version
+
+	// positive and negative are the intersections of all of the assignments
+	// for each package. A package appears in at most one of these maps,
+	// preferring positive.
+	positive map[string]Term
+	negative map[string]Term
+}
+
+func newPartialSolution() *partialSolution {
+	return &partialSolution{
+		decisions: make(map[string]versions.Version),
+		positive:  make(map[string]Term),
+		negative:  make(map[string]Term),
+	}
+}
+
+// decisionLevel returns the number of decisions made so far.
+func (s *partialSolution) decisionLevel() int {
+	return len(s.decisions)
+}
+
+// decide adds a decision to select the given version of the given package.
+func (s *partialSolution) decide(pkg string, version versions.Version, set versions.Set) {
+	s.decisions[pkg] = version
+	s.assign(&assignment{
+		Term:          newTerm(pkg, set, true),
+		decisionLevel: s.decisionLevel(),
+		index:         len(s.assignments),
+	})
+}
+
+// derive adds an assignment of the given term, derived from the given
+// incompatibility.
+func (s *partialSolution) derive(term Term, cause *Incompatibility) {
+	s.assign(&assignment{
+		Term:          term,
+		decisionLevel: s.decisionLevel(),
+		index:         len(s.assignments),
+		cause:         cause,
+	})
+}
+
+func (s *partialSolution) assign(a *assignment) {
+	s.assignments = append(s.assignments, a)
+	s.register(a.Term)
+}
+
+func (s *partialSolution) register(t Term) {
+	if old, ok := s.positive[t.Package]; ok {
+		s.positive[t.Package] = old.intersect(t)
+		return
+	}
+	if old, ok := s.negative[t.Package]; ok {
+		t = t.intersect(old)
+	}
+	if t.Positive {
+		delete(s.negative, t.Package)
+		s.positive[t.Package] = t
+	} else {
+		s.negative[t.Package] = t
+	}
+}
+
+// backtrack removes all of the assignments made after the given decision
+// level, along with their effects.
+func (s *partialSolution) backtrack(decisionLevel int) {
+	keep := len(s.assignments)
+	for keep > 0 && s.assignments[keep-1].decisionLevel > decisionLevel {
+		keep--
+	}
+	removed := s.assignments[keep:]
+	s.assignments = s.assignments[:keep]
+
+	affected := make(map[string]struct{})
+	for _, a := range removed {
+		affected[a.Package] = struct{}{}
+		if a.isDecision() {
+			delete(s.decisions, a.Package)
+		}
+	}
+	for pkg := range affected {
+		delete(s.positive, pkg)
+		delete(s.negative, pkg)
+	}
+	for _, a := range s.assignments {
+		if _, ok := affected[a.Package]; ok {
+			s.register(a.Term)
+		}
+	}
+}
+
+// unsatisfied returns the names of the packages that have positive
+// assignments but no decisions yet, in lexical order.
+func (s *partialSolution) unsatisfied() []string {
+	var ret []string
+	for pkg := range s.positive {
+		if _, decided := s.decisions[pkg]; !decided {
+			ret = append(ret, pkg)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// relation returns the relationship between the receiver's assignments and
+// the given term.
+func (s *partialSolution) relation(t Term) setRelation {
+	if positive, ok := s.positive[t.Package]; ok {
+		return positive.relation(t)
+	}
+	if negative, ok := s.negative[t.Package]; ok {
+		return negative.relation(t)
+	}
+	return relationOverlapping
+}
+
+// satisfies returns true if the receiver's assignments imply the given term.
+func (s *partialSolution) satisfies(t Term) bool {
+	return s.relation(t) == relationSubset
+}
+
+// satisfier returns the earliest assignment such that it and all of the
+// assignments before it together satisfy the given term.
+func (s *partialSolution) satisfier(t Term) *assignment {
+	var assigned *Term
+	for _, a := range s.assignments {
+		if a.Package != t.Package {
+			continue
+		}
+		if assigned == nil {
+			term := a.Term
+			assigned = &term
+		} else {
+			term := assigned.intersect(a.Term)
+			assigned = &term
+		}
+		if assigned.satisfies(t) {
+			return a
+		}
+	}
+	// Should never happen, because we only look for satisfiers of terms
+	// that the partial solution already satisfies.
+	panic(fmt.Sprintf("no satisfier for %s", t))
+}
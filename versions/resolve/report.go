@@ -0,0 +1,230 @@
+package resolve
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// NoSolutionError is the error type returned by Solve when there is no
+// selection of package versions that meets all of the requirements.
+//
+// Incompatibility is the final incompatibility derived by the resolver, whose
+// chain of causes describes why no solution exists. The Error method
+// produces an English-language explanation of that chain that is suitable
+// for display to end-users.
+type NoSolutionError struct {
+	Incompatibility *Incompatibility
+}
+
+func (e *NoSolutionError) Error() string {
+	if _, derived := e.Incompatibility.Cause.(ConflictCause); !derived {
+		return e.Incompatibility.String()
+	}
+
+	r := &reporter{
+		derivations: make(map[*Incompatibility]int),
+		lineNumbers: make(map[*Incompatibility]int),
+		referenced:  make(map[int]bool),
+	}
+	r.countDerivations(e.Incompatibility)
+	r.visit(e.Incompatibility, true)
+
+	// Only the lines that are referred to by later lines are labelled, and
+	// their labels are numbered in the order they appear.
+	var labels []string
+	labelCount := 0
+	for _, line := range r.lines {
+		if r.referenced[line.number] {
+			labelCount++
+			labels = append(labels, lineRef(line.number), fmt.Sprintf("(%d)", labelCount))
+		}
+	}
+	labeler := strings.NewReplacer(labels...)
+
+	var buf bytes.Buffer
+	for i, line := range r.lines {
+		if i != 0 {
+			buf.WriteByte('\n')
+		}
+		if r.referenced[line.number] {
+			fmt.Fprintf(&buf, "%s ", labeler.Replace(lineRef(line.number)))
+		}
+		buf.WriteString(labeler.Replace(line.message))
+	}
+	return buf.String()
+}
+
+// reporter produces an explanation of a failed resolution, using the
+// approach described in the PubGrub documentation: derived incompatibilities
+// that are referred to more than once are numbered so that later lines can
+// refer back to them.
+//
+// The messages of the lines refer to other lines using placeholders returned
+// by ref, because the numbers shown to the user are decided only once all of
+// the lines are written.
+type reporter struct {
+	derivations map[*Incompatibility]int
+	lineNumbers map[*Incompatibility]int
+	referenced  map[int]bool
+	lines       []reportLine
+}
+
+type reportLine struct {
+	message string
+	number  int
+}
+
+func (r *reporter) countDerivations(incompat *Incompatibility) {
+	if _, seen := r.derivations[incompat]; seen {
+		r.derivations[incompat]++
+		return
+	}
+	r.derivations[incompat] = 1
+	if cause, ok := incompat.Cause.(ConflictCause); ok {
+		r.countDerivations(cause.Conflict)
+		r.countDerivations(cause.Other)
+	}
+}
+
+func (r *reporter) visit(incompat *Incompatibility, conclusion bool) {
+	numbered := conclusion || r.derivations[incompat] > 1
+	conjunction := "And"
+	if numbered {
+		conjunction = "So,"
+	}
+
+	cause := incompat.Cause.(ConflictCause)
+	_, conflictDerived := cause.Conflict.Cause.(ConflictCause)
+	_, otherDerived := cause.Other.Cause.(ConflictCause)
+
+	switch {
+	case conflictDerived && otherDerived:
+		conflictLine, conflictNumbered := r.lineNumbers[cause.Conflict]
+		otherLine, otherNumbered := r.lineNumbers[cause.Other]
+		switch {
+		case conflictNumbered && otherNumbered:
+			r.write(incompat, fmt.Sprintf(
+				"Because %s %s and %s %s, %s.",
+				cause.Conflict, r.ref(conflictLine), cause.Other, r.ref(otherLine), incompat,
+			), numbered)
+		case conflictNumbered || otherNumbered:
+			withLine, withoutLine, line := cause.Conflict, cause.Other, conflictLine
+			if otherNumbered {
+				withLine, withoutLine, line = cause.Other, cause.Conflict, otherLine
+			}
+			r.visit(withoutLine, false)
+			r.write(incompat, fmt.Sprintf(
+				"%s because %s %s, %s.",
+				conjunction, withLine, r.ref(line), incompat,
+			), numbered)
+		default:
+			singleLineConflict := isSingleLine(cause.Conflict)
+			singleLineOther := isSingleLine(cause.Other)
+			if singleLineConflict || singleLineOther {
+				first, second := cause.Other, cause.Conflict
+				if singleLineOther {
+					first, second = cause.Conflict, cause.Other
+				}
+				r.visit(first, false)
+				r.visit(second, false)
+				r.write(incompat, fmt.Sprintf("Thus, %s.", incompat), numbered)
+			} else {
+				r.visit(cause.Conflict, true)
+				r.lines = append(r.lines, reportLine{})
+				r.visit(cause.Other, false)
+				r.write(incompat, fmt.Sprintf(
+					"%s because %s %s, %s.",
+					conjunction, cause.Conflict, r.ref(r.lineNumbers[cause.Conflict]), incompat,
+				), numbered)
+			}
+		}
+
+	case conflictDerived || otherDerived:
+		derived, external := cause.Conflict, cause.Other
+		if otherDerived {
+			derived, external = cause.Other, cause.Conflict
+		}
+		if line, ok := r.lineNumbers[derived]; ok {
+			r.write(incompat, fmt.Sprintf(
+				"Because %s and %s %s, %s.",
+				external, derived, r.ref(line), incompat,
+			), numbered)
+		} else if r.isCollapsible(derived) {
+			derivedCause := derived.Cause.(ConflictCause)
+			collapsedDerived, collapsedExternal := derivedCause.Other, derivedCause.Conflict
+			if _, ok := derivedCause.Conflict.Cause.(ConflictCause); ok {
+				collapsedDerived, collapsedExternal = derivedCause.Conflict, derivedCause.Other
+			}
+			r.visit(collapsedDerived, false)
+			r.write(incompat, fmt.Sprintf(
+				"%s because %s and %s, %s.",
+				conjunction, collapsedExternal, external, incompat,
+			), numbered)
+		} else {
+			r.visit(derived, false)
+			r.write(incompat, fmt.Sprintf(
+				"%s because %s, %s.",
+				conjunction, external, incompat,
+			), numbered)
+		}
+
+	default:
+		r.write(incompat, fmt.Sprintf(
+			"Because %s and %s, %s.",
+			cause.Conflict, cause.Other, incompat,
+		), numbered)
+	}
+}
+
+func (r *reporter) write(incompat *Incompatibility, message string, numbered bool) {
+	if numbered {
+		number := len(r.lineNumbers) + 1
+		r.lineNumbers[incompat] = number
+		r.lines = append(r.lines, reportLine{message: message, number: number})
+	} else {
+		r.lines = append(r.lines, reportLine{message: message})
+	}
+}
+
+// ref returns a placeholder for a reference to the line with the given
+// number, which the final message replaces with the number shown to the user.
+func (r *reporter) ref(number int) string {
+	r.referenced[number] = true
+	return lineRef(number)
+}
+
+func lineRef(number int) string {
+	return fmt.Sprintf("(\x00%d\x00)", number)
+}
+
+// isCollapsible returns true if the given derived incompatibility can be
+// described together with the incompatibility that refers to it, rather
+// than on a line of its own.
+func (r *reporter) isCollapsible(incompat *Incompatibility) bool {
+	if r.derivations[incompat] > 1 {
+		return false
+	}
+	cause := incompat.Cause.(ConflictCause)
+	_, conflictDerived := cause.Conflict.Cause.(ConflictCause)
+	_, otherDerived := cause.Other.Cause.(ConflictCause)
+	if conflictDerived == otherDerived {
+		return false
+	}
+	complex := cause.Conflict
+	if otherDerived {
+		complex = cause.Other
+	}
+	_, numbered := r.lineNumbers[complex]
+	return !numbered
+}
+
+// isSingleLine returns true if the given derived incompatibility was derived
+// only from external incompatibilities, and so can be explained in a single
+// line.
+func isSingleLine(incompat *Incompatibility) bool {
+	cause := incompat.Cause.(ConflictCause)
+	_, conflictDerived := cause.Conflict.Cause.(ConflictCause)
+	_, otherDerived := cause.Other.Cause.(ConflictCause)
+	return !conflictDerived && !otherDerived
+}
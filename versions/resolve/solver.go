@@ -0,0 +1,353 @@
+package resolve
+
+import (
+	"fmt"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// rootPackage is the name used internally for the root requirements, which
+// are treated as a special package that must always be selected. The empty
+// string is not a valid package name, so it cannot collide.
+const rootPackage = ""
+
+// Solve finds a selection of package versions that meets the given root
+// requirements and all of the requirements of each selected package version,
+// using the given source to find the available versions and their
+// requirements.
+//
+// Where more than one solution exists, the resolver prefers newer versions of
+// the packages it considers first. Packages with fewer candidate versions are
+// considered first, so that conflicts are detected as early as possible.
+//
+// If no solution exists, the returned error is a *NoSolutionError that
+// explains why. If the source returns an error then that error is returned,
+// wrapped with some additional context.
+func Solve(source Source, requirements Requirements) (Solution, error) {
+	return SolveWithSources(source, requirements, nil)
+}
+
+// SolveWithSources is like Solve except that it also takes the sources of
+// the constraints in the root requirements, so that the explanation in a
+// *NoSolutionError can mention where those constraints came from.
+func SolveWithSources(source Source, requirements Requirements, sources RequirementSources) (Solution, error) {
+	s := &solver{
+		source:            newCachingSource(source),
+		solution:          newPartialSolution(),
+		incompatibilities: make(map[string][]*Incompatibility),
+		rootSources:       sources,
+	}
+	return s.solve(requirements)
+}
+
+type solver struct {
+	source            *cachingSource
+	solution          *partialSolution
+	incompatibilities map[string][]*Incompatibility
+	rootSources       RequirementSources
+}
+
+func (s *solver) solve(requirements Requirements) (Solution, error) {
+	s.addIncompatibility(newIncompatibility([]Term{
+		newTerm(rootPackage, versions.All, false),
+	}, RootCause{}))
+
+	next := rootPackage
+	for {
+		if err := s.propagate(next); err != nil {
+			return nil, err
+		}
+
+		var done bool
+		var err error
+		next, done, err = s.choosePackageVersion(requirements)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+	}
+
+	ret := make(Solution, len(s.solution.decisions)-1)
+	for pkg, v := range s.solution.decisions {
+		if pkg != rootPackage {
+			ret[pkg] = v
+		}
+	}
+	return ret, nil
+}
+
+func (s *solver) addIncompatibility(incompat *Incompatibility) {
+	for _, t := range incompat.Terms {
+		s.incompatibilities[t.Package] = append(s.incompatibilities[t.Package], incompat)
+	}
+}
+
+// propagate performs unit propagation starting from the given package,
+// deriving new assignments from any incompatibilities that are almost
+// satisfied and resolving any conflicts that arise.
+func (s *solver) propagate(pkg string) error {
+	changed := []string{pkg}
+	for len(changed) != 0 {
+		pkg := changed[len(changed)-1]
+		changed = changed[:len(changed)-1]
+
+		// We visit the newest incompatibilities first, because they are
+		// most likely to be relevant to the latest changes.
+		incompats := s.incompatibilities[pkg]
+		for i := len(incompats) - 1; i >= 0; i-- {
+			derived, result := s.propagateIncompatibility(incompats[i])
+			if result == propagateConflict {
+				rootCause, err := s.resolveConflict(incompats[i])
+				if err != nil {
+					return err
+				}
+				// The root cause is now almost satisfied, so it will
+				// always derive a new assignment.
+				derived, _ = s.propagateIncompatibility(rootCause)
+				changed = append(changed[:0], derived)
+				break
+			}
+			if result == propagateDerived {
+				changed = append(changed, derived)
+			}
+		}
+	}
+	return nil
+}
+
+// propagateResult is the outcome of propagating a single incompatibility.
+type propagateResult int
+
+const (
+	propagateNone propagateResult = iota
+	propagateDerived
+	propagateConflict
+)
+
+// propagateIncompatibility derives a new assignment from the given
+// incompatibility if all but one of its terms are satisfied, returning the
+// name of the package that was assigned. If all of its terms are satisfied
+// then it instead reports a conflict.
+func (s *solver) propagateIncompatibility(incompat *Incompatibility) (string, propagateResult) {
+	var unsatisfied *Term
+	for i := range incompat.Terms {
+		t := &incompat.Terms[i]
+		switch s.solution.relation(*t) {
+		case relationDisjoint:
+			// The incompatibility can never be satisfied, so there's
+			// nothing to derive from it.
+			return "", propagateNone
+		case relationOverlapping:
+			if unsatisfied != nil {
+				// More than one term is inconclusive, so we can't derive
+				// anything yet.
+				return "", propagateNone
+			}
+			unsatisfied = t
+		}
+	}
+	if unsatisfied == nil {
+		return "", propagateConflict
+	}
+	s.solution.derive(unsatisfied.Inverse(), incompat)
+	return unsatisfied.Package, propagateDerived
+}
+
+// resolveConflict finds the root cause of a conflict in the given satisfied
+// incompatibility, backtracks the partial solution to a point where the root
+// cause is no longer satisfied, and returns the root cause.
+func (s *solver) resolveConflict(incompat *Incompatibility) (*Incompatibility, error) {
+	isNew := false
+	for !incompat.isFailure() {
+		var mostRecentTerm *Term
+		var mostRecentSatisfier *assignment
+		var difference *Term
+		previousSatisfierLevel := 1
+
+		for i := range incompat.Terms {
+			t := &incompat.Terms[i]
+			satisfier := s.solution.satisfier(*t)
+			switch {
+			case mostRecentSatisfier == nil:
+				mostRecentTerm = t
+				mostRecentSatisfier = satisfier
+			case mostRecentSatisfier.index < satisfier.index:
+				previousSatisfierLevel = maxInt(previousSatisfierLevel, mostRecentSatisfier.decisionLevel)
+				mostRecentTerm = t
+				mostRecentSatisfier = satisfier
+				difference = nil
+			default:
+				previousSatisfierLevel = maxInt(previousSatisfierLevel, satisfier.decisionLevel)
+			}
+
+			if mostRecentTerm == t {
+				// If the satisfier alone doesn't satisfy the term then
+				// an earlier assignment must be involved too.
+				diff := mostRecentSatisfier.difference(*mostRecentTerm)
+				if diff.isEmpty() {
+					difference = nil
+				} else {
+					difference = &diff
+					previousSatisfierLevel = maxInt(previousSatisfierLevel, s.solution.satisfier(diff.Inverse()).decisionLevel)
+				}
+			}
+		}
+
+		if previousSatisfierLevel < mostRecentSatisfier.decisionLevel || mostRecentSatisfier.isDecision() {
+			s.solution.backtrack(previousSatisfierLevel)
+			if isNew {
+				s.addIncompatibility(incompat)
+			}
+			return incompat, nil
+		}
+
+		// Otherwise we'll derive a new incompatibility that combines the
+		// current one with the cause of its most recent satisfier, and then
+		// try again with that.
+		var terms []Term
+		for i := range incompat.Terms {
+			if &incompat.Terms[i] != mostRecentTerm {
+				terms = append(terms, incompat.Terms[i])
+			}
+		}
+		for _, t := range mostRecentSatisfier.cause.Terms {
+			if t.Package != mostRecentSatisfier.Package {
+				terms = append(terms, t)
+			}
+		}
+		if difference != nil {
+			terms = append(terms, difference.Inverse())
+		}
+		incompat = newIncompatibility(terms, ConflictCause{
+			Conflict: incompat,
+			Other:    mostRecentSatisfier.cause,
+		})
+		isNew = true
+	}
+
+	return nil, &NoSolutionError{Incompatibility: incompat}
+}
+
+// choosePackageVersion decides on a version for one of the packages that
+// have positive assignments but no decision yet, returning the name of the
+// package whose assignments changed. If all such packages have been decided
+// then it returns true to signal that the solution is complete.
+func (s *solver) choosePackageVersion(rootReqs Requirements) (string, bool, error) {
+	unsatisfied := s.solution.unsatisfied()
+	if len(unsatisfied) == 0 {
+		return "", true, nil
+	}
+
+	if unsatisfied[0] == rootPackage {
+		// The root requirements are always decided first, and only once.
+		for _, incompat := range dependencyIncompatibilities(rootPackage, versions.All, rootReqs, s.rootSources) {
+			s.addIncompatibility(incompat)
+		}
+		s.solution.decide(rootPackage, versions.Unspecified, versions.All)
+		return rootPackage, false, nil
+	}
+
+	// We prefer to decide packages with fewer candidate versions first,
+	// since that's where we're most likely to find conflicts quickly.
+	var pkg string
+	var candidates versions.List
+	for _, name := range unsatisfied {
+		all, err := s.source.Versions(name)
+		if err != nil {
+			return "", false, err
+		}
+		allowed := make(versions.List, len(all))
+		copy(allowed, all)
+		allowed = allowed.Filter(s.solution.positive[name].Versions)
+		if pkg == "" || len(allowed) < len(candidates) {
+			pkg = name
+			candidates = allowed
+		}
+	}
+
+	term := s.solution.positive[pkg]
+	if len(candidates) == 0 {
+		s.addIncompatibility(newIncompatibility([]Term{term}, NoVersionsCause{}))
+		return pkg, false, nil
+	}
+
+	version := candidates.Newest().(versions.Version)
+	reqs, err := s.source.Dependencies(pkg, version)
+	if err != nil {
+		return "", false, err
+	}
+
+	conflict := false
+	for _, incompat := range dependencyIncompatibilities(pkg, versions.Only(version), reqs, nil) {
+		s.addIncompatibility(incompat)
+
+		// If all of the other terms are already satisfied then selecting
+		// this version would immediately conflict, so we'll let propagation
+		// deal with it instead of deciding.
+		if !conflict {
+			conflict = true
+			for _, t := range incompat.Terms {
+				if t.Package != pkg && !s.solution.satisfies(t) {
+					conflict = false
+					break
+				}
+			}
+		}
+	}
+	if !conflict {
+		s.solution.decide(pkg, version, versions.Only(version))
+	}
+	return pkg, false, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// cachingSource wraps a Source to ensure that each distinct request is made
+// only once, and to annotate any errors with the package they relate to.
+type cachingSource struct {
+	source       Source
+	versions     map[string]versions.List
+	dependencies map[string]map[versions.Version]Requirements
+}
+
+func newCachingSource(source Source) *cachingSource {
+	return &cachingSource{
+		source:       source,
+		versions:     make(map[string]versions.List),
+		dependencies: make(map[string]map[versions.Version]Requirements),
+	}
+}
+
+func (s *cachingSource) Versions(pkg string) (versions.List, error) {
+	if ret, ok := s.versions[pkg]; ok {
+		return ret, nil
+	}
+	ret, err := s.source.Versions(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %s", pkg, err)
+	}
+	s.versions[pkg] = ret
+	return ret, nil
+}
+
+func (s *cachingSource) Dependencies(pkg string, version versions.Version) (Requirements, error) {
+	if ret, ok := s.dependencies[pkg][version]; ok {
+		return ret, nil
+	}
+	ret, err := s.source.Dependencies(pkg, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies of %s %s: %s", pkg, version, err)
+	}
+	if s.dependencies[pkg] == nil {
+		s.dependencies[pkg] = make(map[versions.Version]Requirements)
+	}
+	s.dependencies[pkg][version] = ret
+	return ret, nil
+}
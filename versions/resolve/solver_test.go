@@ -0,0 +1,307 @@
+package resolve
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+	"github.com/go-test/deep"
+)
+
+// testSource is a Source implementation for testing, mapping package names
+// to version strings to their requirements.
+type testSource map[string]map[string]map[string]string
+
+func (s testSource) Versions(pkg string) (versions.List, error) {
+	var ret versions.List
+	for vs := range s[pkg] {
+		ret = append(ret, versions.MustParseVersion(vs))
+	}
+	return ret, nil
+}
+
+func (s testSource) Dependencies(pkg string, version versions.Version) (Requirements, error) {
+	deps, ok := s[pkg][version.String()]
+	if !ok {
+		return nil, fmt.Errorf("no version %s", version)
+	}
+	return testRequirements(deps), nil
+}
+
+func testRequirements(raw map[string]string) Requirements {
+	ret := make(Requirements, len(raw))
+	for pkg, str := range raw {
+		spec, err := constraints.Parse(str)
+		if err != nil {
+			panic(err)
+		}
+		ret[pkg] = spec
+	}
+	return ret
+}
+
+func TestSolve(t *testing.T) {
+	tests := map[string]struct {
+		Source  testSource
+		Root    map[string]string
+		Want    map[string]string
+		WantErr string
+	}{
+		"no dependencies": {
+			testSource{},
+			map[string]string{},
+			map[string]string{},
+			"",
+		},
+		"no conflicts": {
+			testSource{
+				"foo": {
+					"1.0.0": {"bar": "^1.0.0"},
+				},
+				"bar": {
+					"1.0.0": {},
+					"1.1.0": {},
+					"2.0.0": {},
+				},
+			},
+			map[string]string{"foo": "^1.0.0"},
+			map[string]string{"foo": "1.0.0", "bar": "1.1.0"},
+			"",
+		},
+		"ignores unrequested prereleases": {
+			testSource{
+				"foo": {
+					"1.0.0":        {},
+					"1.1.0-beta.1": {},
+				},
+			},
+			map[string]string{"foo": "^1.0.0"},
+			map[string]string{"foo": "1.0.0"},
+			"",
+		},
+		"avoiding conflict during decision making": {
+			testSource{
+				"foo": {
+					"1.0.0": {},
+					"1.1.0": {"bar": "^2.0.0"},
+				},
+				"bar": {
+					"1.0.0": {},
+					"1.1.0": {},
+					"2.0.0": {},
+				},
+			},
+			map[string]string{"foo": "^1.0.0", "bar": "^1.0.0"},
+			map[string]string{"foo": "1.0.0", "bar": "1.1.0"},
+			"",
+		},
+		"performing conflict resolution": {
+			testSource{
+				"foo": {
+					"1.0.0": {},
+					"2.0.0": {"bar": "^1.0.0"},
+				},
+				"bar": {
+					"1.0.0": {"foo": "^1.0.0"},
+				},
+			},
+			map[string]string{"foo": ">=1.0.0"},
+			map[string]string{"foo": "1.0.0"},
+			"",
+		},
+		"conflict resolution with a partial satisfier": {
+			testSource{
+				"foo": {
+					"1.0.0": {},
+					"1.1.0": {"left": "^1.0.0", "right": "^1.0.0"},
+				},
+				"left": {
+					"1.0.0": {"shared": ">=1.0.0"},
+				},
+				"right": {
+					"1.0.0": {"shared": "<2.0.0"},
+				},
+				"shared": {
+					"1.0.0": {},
+					"2.0.0": {},
+				},
+				"target": {
+					"1.0.0": {},
+					"2.0.0": {},
+				},
+			},
+			map[string]string{"foo": "^1.0.0", "target": "^2.0.0"},
+			map[string]string{"foo": "1.1.0", "left": "1.0.0", "right": "1.0.0", "shared": "1.0.0", "target": "2.0.0"},
+			"",
+		},
+		"missing package": {
+			testSource{},
+			map[string]string{"foo": "^1.0.0"},
+			nil,
+			`Because no versions of foo match ^1.0.0 and the root requirements include foo ^1.0.0, version solving failed.`,
+		},
+		"linear error reporting": {
+			testSource{
+				"foo": {
+					"1.0.0": {"bar": "^2.0.0"},
+				},
+				"bar": {
+					"2.0.0": {"baz": "^3.0.0"},
+				},
+				"baz": {
+					"1.0.0": {},
+					"3.0.0": {},
+				},
+			},
+			map[string]string{"foo": "^1.0.0", "baz": "^1.0.0"},
+			nil,
+			`Because no versions of foo match ^1.0.0 other than 1.0.0 and foo 1.0.0 depends on bar ^2.0.0, foo ^1.0.0 requires bar ^2.0.0.
+Because no versions of bar match ^2.0.0 other than 2.0.0 and bar 2.0.0 depends on baz ^3.0.0, bar ^2.0.0 requires baz ^3.0.0.
+Thus, foo ^1.0.0 requires baz ^3.0.0.
+So, because the root requirements include baz ^1.0.0 and the root requirements include foo ^1.0.0, version solving failed.`,
+		},
+		"branching error reporting": {
+			testSource{
+				"foo": {
+					"1.0.0": {"a": "^1.0.0", "b": "^1.0.0"},
+					"1.1.0": {"x": "^1.0.0", "y": "^1.0.0"},
+				},
+				"a": {
+					"1.0.0": {"b": "^2.0.0"},
+				},
+				"b": {
+					"1.0.0": {},
+					"2.0.0": {},
+				},
+				"x": {
+					"1.0.0": {"y": "^2.0.0"},
+				},
+				"y": {
+					"1.0.0": {},
+					"2.0.0": {},
+				},
+			},
+			map[string]string{"foo": "^1.0.0"},
+			nil,
+			`Because no versions of a match ^1.0.0 other than 1.0.0 and a 1.0.0 depends on b ^2.0.0, a ^1.0.0 requires b ^2.0.0.
+And because foo 1.0.0 depends on a ^1.0.0, foo 1.0.0 requires b ^2.0.0.
+(1) So, because foo 1.0.0 depends on b ^1.0.0 and no versions of foo match ^1.0.0 other than 1.0.0 or 1.1.0, foo ^1.0.0 other than 1.1.0 is forbidden.
+
+Because no versions of x match ^1.0.0 other than 1.0.0 and x 1.0.0 depends on y ^2.0.0, x ^1.0.0 requires y ^2.0.0.
+And because foo 1.1.0 depends on x ^1.0.0 and foo 1.1.0 depends on y ^1.0.0, foo 1.1.0 is forbidden.
+And because foo ^1.0.0 other than 1.1.0 is forbidden (1), foo ^1.0.0 is forbidden.
+So, because the root requirements include foo ^1.0.0, version solving failed.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Solve(test.Source, testRequirements(test.Root))
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != test.WantErr {
+				t.Fatalf("wrong error\ngot:\n%s\n\nwant:\n%s", gotErr, test.WantErr)
+			}
+			if err != nil {
+				if _, ok := err.(*NoSolutionError); !ok {
+					t.Errorf("wrong error type %T", err)
+				}
+				return
+			}
+
+			want := make(Solution, len(test.Want))
+			for pkg, vs := range test.Want {
+				want[pkg] = versions.MustParseVersion(vs)
+			}
+			for _, problem := range deep.Equal(got, want) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func TestSolveSourceFilenames(t *testing.T) {
+	source := testSource{
+		"foo": {
+			"1.0.0": {"bar": "^2.0.0"},
+		},
+		"bar": {
+			"1.0.0": {},
+			"2.0.0": {},
+		},
+	}
+	root := make(Requirements)
+	sources := make(RequirementSources)
+	for pkg, str := range map[string]string{"foo": "^1.0.0", "bar": ">=1.0 <2"} {
+		spec, specSources, err := constraints.ParseWithSources(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root[pkg] = spec
+		sources[pkg] = specSources.WithFilename("manifest.json")
+	}
+
+	_, err := SolveWithSources(source, root, sources)
+	want := `Because foo 1.0.0 depends on bar ^2.0.0 and no versions of foo match ^1.0.0 other than 1.0.0, foo ^1.0.0 requires bar ^2.0.0.
+So, because the root requirements include bar ^1.0.0 (from ">=1.0" in manifest.json, "<2" in manifest.json) and the root requirements include foo ^1.0.0 (from "^1.0.0" in manifest.json), version solving failed.`
+	if got := fmt.Sprint(err); got != want {
+		t.Errorf("wrong error\ngot:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSet(t *testing.T) {
+	v := versions.MustParseVersion
+	tests := []struct {
+		Set  versions.Set
+		Want string
+	}{
+		{
+			versions.MustMakeSet(versions.MeetingConstraintsString("^1.0.0")),
+			"^1.0.0",
+		},
+		{
+			versions.MustMakeSet(versions.MeetingConstraintsString("^1.0.0")).Subtract(versions.Only(v("1.2.0"))),
+			"^1.0.0 other than 1.2.0",
+		},
+		{
+			versions.MustMakeSet(versions.MeetingConstraintsString("^1.0.0")).Subtract(versions.Selection(v("1.0.0"), v("1.1.0"), v("1.2.0"))),
+			"^1.0.0 other than 1.0.0, 1.1.0 or 1.2.0",
+		},
+		{
+			versions.MustMakeSet(versions.MeetingConstraintsString("^1.0.0 || ^3.0.0")).Subtract(versions.Selection(v("1.0.0"), v("3.0.0"))),
+			"^1.0.0 other than 1.0.0 || ^3.0.0 other than 3.0.0",
+		},
+		{
+			versions.Released.Subtract(versions.Only(v("1.0.0"))),
+			"any version other than 1.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Want, func(t *testing.T) {
+			if got := formatSet(test.Set); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSolveSourceError(t *testing.T) {
+	_, err := Solve(errorSource{}, testRequirements(map[string]string{"foo": "1.0.0"}))
+	if got, want := fmt.Sprint(err), "failed to list versions of foo: registry unavailable"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+type errorSource struct{}
+
+func (errorSource) Versions(pkg string) (versions.List, error) {
+	return nil, fmt.Errorf("registry unavailable")
+}
+
+func (errorSource) Dependencies(pkg string, version versions.Version) (Requirements, error) {
+	return nil, fmt.Errorf("registry unavailable")
+}
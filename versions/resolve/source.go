@@ -0,0 +1,40 @@
+package resolve
+
+import (
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// Source is the interface implemented by callers to provide the resolver with
+// information about the available packages.
+//
+// The resolver calls each method at most once for each distinct set of
+// arguments during a single call to Solve, so implementations need not do
+// their own caching.
+type Source interface {
+	// Versions returns all of the available versions of the package with
+	// the given name, in any order. If the package does not exist at all
+	// then the result should be an empty list rather than an error.
+	Versions(pkg string) (versions.List, error)
+
+	// Dependencies returns the dependencies of the given version of the
+	// package with the given name, which is always a version that was
+	// previously returned from Versions.
+	Dependencies(pkg string, version versions.Version) (Requirements, error)
+}
+
+// Requirements is a map from package names to constraints that a selected
+// version of each package must meet.
+//
+// Constraints are interpreted using versions.MeetingConstraints, and so
+// pre-release versions are selected only if requested exactly.
+type Requirements map[string]constraints.Spec
+
+// RequirementSources is a map from package names to the sources of the
+// constraints for those packages in a Requirements, as returned by the
+// parsers in package constraints whose names end in "WithSources".
+type RequirementSources map[string]constraints.Sources
+
+// Solution is the result of a successful call to Solve, giving the selected
+// version of each package that is needed to meet the root requirements.
+type Solution map[string]versions.Version
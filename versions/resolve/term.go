@@ -0,0 +1,194 @@
+package resolve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// Term is a statement about a package that is either true or false for a
+// given selection of package versions.
+//
+// A positive term is true if a version of the package is selected and that
+// version is in the given set. A negative term is true if either no version
+// of the package is selected or the selected version is not in the set.
+type Term struct {
+	Package  string
+	Versions versions.Set
+	Positive bool
+}
+
+// setRelation describes how the set of selections that satisfy one term
+// relate to the selections that satisfy another.
+type setRelation int
+
+const (
+	// relationSubset means that the first term satisfies the second.
+	relationSubset setRelation = iota
+
+	// relationDisjoint means that the first term contradicts the second.
+	relationDisjoint
+
+	// relationOverlapping means that the first term neither satisfies nor
+	// contradicts the second.
+	relationOverlapping
+)
+
+func newTerm(pkg string, set versions.Set, positive bool) Term {
+	// The relationships between terms are found by comparing their sets,
+	// which is possible only for normalizable sets. The sets of requirements
+	// are always built by versions.MeetingConstraints, which never uses the
+	// function-based sets that are not.
+	if !set.IsNormalizable() {
+		panic(fmt.Sprintf("term for %s has set %#v, which can't be normalized", pkg, set))
+	}
+	return Term{
+		Package:  pkg,
+		Versions: set.Normalize(),
+		Positive: positive,
+	}
+}
+
+// Inverse returns a term that is true exactly when the receiver is false.
+func (t Term) Inverse() Term {
+	t.Positive = !t.Positive
+	return t
+}
+
+// relation returns the relationship between the receiver and the other
+// given term, which must be for the same package.
+func (t Term) relation(other Term) setRelation {
+	switch {
+	case t.Positive && other.Positive:
+		switch {
+		case t.Versions.IsSubsetOf(other.Versions):
+			return relationSubset
+		case !t.Versions.Overlaps(other.Versions):
+			return relationDisjoint
+		default:
+			return relationOverlapping
+		}
+	case !t.Positive && other.Positive:
+		if other.Versions.IsSubsetOf(t.Versions) {
+			return relationDisjoint
+		}
+		return relationOverlapping
+	case t.Positive && !other.Positive:
+		switch {
+		case !other.Versions.Overlaps(t.Versions):
+			return relationSubset
+		case t.Versions.IsSubsetOf(other.Versions):
+			return relationDisjoint
+		default:
+			return relationOverlapping
+		}
+	default:
+		if other.Versions.IsSubsetOf(t.Versions) {
+			return relationSubset
+		}
+		return relationOverlapping
+	}
+}
+
+// satisfies returns true if the receiver being true implies that the other
+// given term is also true.
+func (t Term) satisfies(other Term) bool {
+	return t.Package == other.Package && t.relation(other) == relationSubset
+}
+
+// intersect returns a term that is true only when both the receiver and the
+// other given term are true. The two terms must be for the same package.
+func (t Term) intersect(other Term) Term {
+	switch {
+	case t.Positive && other.Positive:
+		return newTerm(t.Package, t.Versions.Intersection(other.Versions), true)
+	case t.Positive:
+		return newTerm(t.Package, t.Versions.Subtract(other.Versions), true)
+	case other.Positive:
+		return newTerm(t.Package, other.Versions.Subtract(t.Versions), true)
+	default:
+		return newTerm(t.Package, t.Versions.Union(other.Versions), false)
+	}
+}
+
+// difference returns a term that is true only when the receiver is true and
+// the other given term is false. The two terms must be for the same package.
+func (t Term) difference(other Term) Term {
+	return t.intersect(other.Inverse())
+}
+
+// isEmpty returns true if the receiver can never be true.
+func (t Term) isEmpty() bool {
+	return t.Positive && t.Versions.IsEmpty()
+}
+
+func (t Term) String() string {
+	if t.Positive {
+		return t.terse()
+	}
+	return "not " + t.terse()
+}
+
+// terse returns a description of the package and versions in the receiver,
+// disregarding whether the term is positive.
+func (t Term) terse() string {
+	if t.Package == rootPackage {
+		return "the root requirements"
+	}
+	return fmt.Sprintf("%s %s", t.Package, formatSet(t.Versions))
+}
+
+// formatSet returns a string representation of the given set for use in
+// messages, preferring the canonical constraint syntax where possible.
+//
+// The resolver often subtracts individual versions from a set once it has
+// ruled them out, and the canonical syntax writes those as selections like
+// "!1.0.0" that are easy to misread in a sentence, so this function instead
+// describes them as "other than 1.0.0".
+func formatSet(set versions.Set) string {
+	text, err := set.MarshalText()
+	if err != nil {
+		// We only create sets from constraints and exact versions, so this
+		// should be rare, but we'll still produce something in this case.
+		return fmt.Sprintf("%#v", set)
+	}
+	union, err := constraints.Parse(string(text))
+	if err != nil {
+		// Should never happen, because MarshalText produces valid syntax.
+		return string(text)
+	}
+	parts := make([]string, len(union))
+	for i, sels := range union {
+		parts[i] = formatSelections(sels)
+	}
+	return strings.Join(parts, " || ")
+}
+
+// formatSelections is the part of formatSet that deals with each selection
+// set in a constraint string.
+func formatSelections(sels constraints.IntersectionSpec) string {
+	var kept constraints.IntersectionSpec
+	var excluded []string
+	for _, sel := range sels {
+		if sel.Operator == constraints.OpNotEqual {
+			excluded = append(excluded, sel.Boundary.String())
+			continue
+		}
+		kept = append(kept, sel)
+	}
+	if len(excluded) == 0 {
+		return sels.String()
+	}
+
+	rest := kept.String()
+	if rest == "*" {
+		rest = "any version"
+	}
+	others := excluded[len(excluded)-1]
+	if len(excluded) > 1 {
+		others = strings.Join(excluded[:len(excluded)-1], ", ") + " or " + others
+	}
+	return fmt.Sprintf("%s other than %s", rest, others)
+}
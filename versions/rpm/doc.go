@@ -0,0 +1,13 @@
+// Package rpm is a companion to package versions that deals with the
+// version numbers of RPM packages, as compared by rpm's rpmvercmp function.
+//
+// RPM versions have the form "[epoch:]version[-release]", such as
+// "3.4.5-1.el8", and are ordered by splitting each part into alternating
+// numeric and alphabetic segments. This package therefore has its own
+// Version type, whose Scheme lets RPM versions be used with versions.Set
+// and versions.List, and with the functions of package versions that create
+// sets, such as versions.AtLeast.
+//
+// Sets can be created from the version relations used in package
+// dependencies, such as ">= 1.2" or "< 2.0". See ParseRelation for details.
+package rpm
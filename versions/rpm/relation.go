@@ -0,0 +1,196 @@
+package rpm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+)
+
+// Operator is the comparison operator of a Relation.
+type Operator string
+
+const (
+	OpLessThan           Operator = "<"
+	OpLessThanOrEqual    Operator = "<="
+	OpEqual              Operator = "="
+	OpGreaterThanOrEqual Operator = ">="
+	OpGreaterThan        Operator = ">"
+)
+
+// Relation is a version relation as used in the dependencies of an RPM
+// package, such as ">= 1.2" in "Requires: libfoo >= 1.2".
+type Relation struct {
+	Operator Operator
+	Version  Version
+}
+
+// Relations is a sequence of relations that must all match a version for
+// the version to match the sequence as a whole.
+type Relations []Relation
+
+// ParseRelation parses a single version relation, such as ">= 1.2" or
+// "< 2.0-3".
+//
+// If the given string is not a valid relation then an error is returned that
+// is suitable for display directly to a hypothetical end-user that provided
+// the string, as long as they can read English.
+func ParseRelation(s string) (Relation, error) {
+	var rel Relation
+
+	remain := strings.TrimSpace(s)
+	if remain == "" {
+		return rel, fmt.Errorf("empty version relation")
+	}
+
+	opLen := 0
+	for opLen < len(remain) && strings.IndexByte("<=>", remain[opLen]) >= 0 {
+		opLen++
+	}
+	op := remain[:opLen]
+	switch Operator(op) {
+	case OpLessThan, OpLessThanOrEqual, OpEqual, OpGreaterThanOrEqual, OpGreaterThan:
+		rel.Operator = Operator(op)
+	case "":
+		return rel, fmt.Errorf("invalid relation %q; must start with one of the operators <, <=, =, >=, >", s)
+	default:
+		return rel, fmt.Errorf("invalid operator %q in relation %q", op, s)
+	}
+
+	v, err := Parse(remain[opLen:])
+	if err != nil {
+		return rel, err
+	}
+	rel.Version = v
+	return rel, nil
+}
+
+// MustParseRelation is the same as ParseRelation except that it will panic
+// instead of returning an error.
+func MustParseRelation(s string) Relation {
+	rel, err := ParseRelation(s)
+	if err != nil {
+		panic(err)
+	}
+	return rel
+}
+
+// ParseRelations parses a comma-separated sequence of version relations,
+// such as ">= 1.2, < 2.0", each of which must be valid for ParseRelation.
+func ParseRelations(s string) (Relations, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("empty version relation")
+	}
+	parts := strings.Split(s, ",")
+	ret := make(Relations, 0, len(parts))
+	for _, part := range parts {
+		rel, err := ParseRelation(part)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, rel)
+	}
+	return ret, nil
+}
+
+// Has returns true if the given version satisfies the receiving relation.
+//
+// As in rpm itself, if the relation's version has no release then the
+// release of the given version is ignored, so that "= 1.2" matches "1.2-3".
+func (r Relation) Has(v Version) bool {
+	if r.Version.Release == "" {
+		v.Release = ""
+	}
+	c := v.Compare(r.Version)
+	switch r.Operator {
+	case OpLessThan:
+		return c < 0
+	case OpLessThanOrEqual:
+		return c <= 0
+	case OpEqual:
+		return c == 0
+	case OpGreaterThanOrEqual:
+		return c >= 0
+	case OpGreaterThan:
+		return c > 0
+	default:
+		panic(fmt.Sprintf("unsupported relation operator %q", r.Operator))
+	}
+}
+
+// String returns the receiver in the syntax accepted by ParseRelation.
+func (r Relation) String() string {
+	return fmt.Sprintf("%s %s", r.Operator, r.Version)
+}
+
+// String returns the receiver in the syntax accepted by ParseRelations.
+func (r Relations) String() string {
+	parts := make([]string, len(r))
+	for i, rel := range r {
+		parts[i] = rel.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Set returns a set containing the versions that satisfy the receiving
+// relation, as defined by Has.
+//
+// If the relation's version has no release then the operators =, <= and >
+// match versions with any release, which can't be written in terms of
+// precedence alone, and so their sets are built using
+// versions.Matching. Sets that include them can't be normalized, and so
+// can't be compared using methods such as IsEmpty; use IsNormalizable to
+// check first.
+func (r Relation) Set() versions.Set {
+	switch {
+	case r.Operator == OpLessThan:
+		return versions.OlderThan(r.Version)
+	case r.Operator == OpGreaterThanOrEqual:
+		return versions.AtLeast(r.Version)
+	case r.Version.Release == "":
+		return versions.Matching(func(sv versions.SchemeVersion) bool {
+			v, ok := sv.(Version)
+			return ok && r.Has(v)
+		})
+	case r.Operator == OpLessThanOrEqual:
+		return versions.AtMost(r.Version)
+	case r.Operator == OpEqual:
+		return versions.Only(r.Version)
+	case r.Operator == OpGreaterThan:
+		return versions.NewerThan(r.Version)
+	default:
+		panic(fmt.Sprintf("unsupported relation operator %q", r.Operator))
+	}
+}
+
+// MeetingRelations returns a version set that contains all of the versions
+// that satisfy all of the given relations.
+func MeetingRelations(rels Relations) versions.Set {
+	sets := make([]versions.Set, len(rels))
+	for i, rel := range rels {
+		sets[i] = rel.Set()
+	}
+	return versions.Intersection(sets...)
+}
+
+// MeetingRelationsString attempts to parse the given string using
+// ParseRelations and then passes the result to MeetingRelations, returning
+// the resulting set.
+func MeetingRelationsString(s string) (versions.Set, error) {
+	rels, err := ParseRelations(s)
+	if err != nil {
+		return versions.None, err
+	}
+	return MeetingRelations(rels), nil
+}
+
+// MustMakeSet can be used to wrap any function that returns a set and an error
+// to make it panic if an error occurs and return the set otherwise.
+//
+// This is intended for tests and initializing global variables.
+func MustMakeSet(set versions.Set, err error) versions.Set {
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
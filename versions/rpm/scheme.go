@@ -0,0 +1,45 @@
+package rpm
+
+import (
+	"fmt"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// Scheme is the versions.Scheme of RPM versions, which orders them using
+// Version.Compare and treats those for which Version.IsPrerelease returns
+// true as pre-releases.
+//
+// RPM versions have no equivalent of operators like "~" and "^" that allow
+// compatible newer versions, so constraint specs that use them can't be
+// evaluated with this scheme.
+var Scheme versions.Scheme = scheme{}
+
+// Scheme returns the scheme of RPM versions, which is the package-level
+// variable of the same name.
+func (v Version) Scheme() versions.Scheme {
+	return Scheme
+}
+
+type scheme struct{}
+
+func (s scheme) ParseVersion(str string) (versions.SchemeVersion, error) {
+	return Parse(str)
+}
+
+func (s scheme) Compare(a, b versions.SchemeVersion) int {
+	return a.(Version).Compare(b.(Version))
+}
+
+func (s scheme) IsPrerelease(v versions.SchemeVersion) bool {
+	return v.(Version).IsPrerelease()
+}
+
+func (s scheme) UpperBound(op constraints.SelectionOp, lower versions.SchemeVersion) (versions.SchemeVersion, error) {
+	return nil, fmt.Errorf("RPM versions have no equivalent of the %s operator", op)
+}
+
+func (s scheme) GoString() string {
+	return "rpm.Scheme"
+}
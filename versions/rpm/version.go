@@ -0,0 +1,288 @@
+package rpm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version represents a single RPM package version, often called an EVR for
+// its three parts.
+type Version struct {
+	// Epoch is the number before the colon, which is zero if not specified.
+	Epoch uint64
+
+	// Version is the upstream version of the packaged software, which is
+	// never empty for any version returned by Parse.
+	Version string
+
+	// Release is the package release, which follows the hyphen in the
+	// version string, or the empty string if there is none.
+	Release string
+}
+
+// Unspecified is the zero value of Version and represents the absense of a
+// version number.
+var Unspecified Version
+
+// Parse parses the given string as an RPM version.
+//
+// If the given string is not parseable then an error is returned that is
+// suitable for display directly to a hypothetical end-user that provided this
+// version string, as long as they can read English.
+func Parse(s string) (Version, error) {
+	var v Version
+	remain := strings.TrimSpace(s)
+	if remain == "" {
+		return Unspecified, fmt.Errorf("empty version string")
+	}
+
+	if colon := strings.IndexByte(remain, ':'); colon >= 0 {
+		n, err := strconv.ParseUint(remain[:colon], 10, 32)
+		if err != nil {
+			return Unspecified, fmt.Errorf("invalid version %q; epoch before colon must be a number", s)
+		}
+		v.Epoch = n
+		remain = remain[colon+1:]
+	}
+
+	switch strings.Count(remain, "-") {
+	case 0:
+		v.Version = remain
+	case 1:
+		hyphen := strings.IndexByte(remain, '-')
+		v.Version, v.Release = remain[:hyphen], remain[hyphen+1:]
+		if v.Release == "" {
+			return Unspecified, fmt.Errorf("invalid version %q; release after hyphen is empty", s)
+		}
+	default:
+		return Unspecified, fmt.Errorf("invalid version %q; only one hyphen is allowed, between the version and the release", s)
+	}
+	if v.Version == "" {
+		return Unspecified, fmt.Errorf("invalid version %q; version is empty", s)
+	}
+
+	for _, c := range []byte(v.Version + v.Release) {
+		if !isAlnum(c) && !strings.ContainsRune("._+~^", rune(c)) {
+			return Unspecified, fmt.Errorf("invalid version %q; contains invalid character %q", s, c)
+		}
+	}
+
+	return v, nil
+}
+
+// MustParse is the same as Parse except that it will panic instead of
+// returning an error.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the receiver in the usual string representation, omitting
+// the epoch if it is zero.
+func (v Version) String() string {
+	var buf strings.Builder
+	if v.Epoch != 0 {
+		fmt.Fprintf(&buf, "%d:", v.Epoch)
+	}
+	buf.WriteString(v.Version)
+	if v.Release != "" {
+		buf.WriteByte('-')
+		buf.WriteString(v.Release)
+	}
+	return buf.String()
+}
+
+func (v Version) GoString() string {
+	return fmt.Sprintf("rpm.MustParse(%q)", v.String())
+}
+
+// IsPrerelease returns true if the receiver's version part contains a "~",
+// which by convention marks a pre-release such as "1.0~rc1" because it sorts
+// before the final release. A "~" in the release does not make a version a
+// pre-release.
+func (v Version) IsPrerelease() bool {
+	return strings.Contains(v.Version, "~")
+}
+
+// Compare returns -1, 0 or 1 depending on whether the receiver has lower,
+// the same or higher precedence than the other given version, comparing the
+// epochs numerically and then the versions and releases using rpmvercmp.
+//
+// A version without a release has lower precedence than the same version
+// with any release. Relations treat a missing release differently; see
+// Relation.Has.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.Epoch < other.Epoch:
+		return -1
+	case v.Epoch > other.Epoch:
+		return 1
+	}
+	if c := vercmp(v.Version, other.Version); c != 0 {
+		return c
+	}
+	return vercmp(v.Release, other.Release)
+}
+
+// LessThan returns true if the receiver has a lower precedence than the
+// other given version.
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// GreaterThan returns true if the receiver has a higher precedence than the
+// other given version.
+func (v Version) GreaterThan(other Version) bool {
+	return v.Compare(other) > 0
+}
+
+// Same returns true if the receiver has the same precedence as the other
+// given version. Versions with different strings can have the same
+// precedence, such as "1.0" and "1.00" or "1.0" and "1_0".
+func (v Version) Same(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// MarshalText is an implementation of encoding.TextMarshaler, allowing versions
+// to be automatically marshalled for text-based serialization formats,
+// including encoding/json.
+//
+// The format used is that returned by String, which can be parsed using
+// Parse.
+func (v Version) MarshalText() (text []byte, err error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText is an implementation of encoding.TextUnmarshaler, allowing
+// versions to be automatically unmarshalled from strings in text-based
+// serialization formats, including encoding/json.
+//
+// The format expected is what is accepted by Parse. Any parser errors
+// are passed on verbatim to the caller.
+func (v *Version) UnmarshalText(text []byte) error {
+	new, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = new
+	return nil
+}
+
+// vercmp compares two version or release strings using the algorithm of
+// rpm's rpmvercmp function.
+//
+// The strings are split into segments of digits or letters, ignoring all
+// other characters except "~" and "^". Digit segments are compared
+// numerically and sort after letter segments. A "~" sorts before everything,
+// including the end of the string, while a "^" sorts after the end of the
+// string but before anything else.
+func vercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		aTilde, bTilde := i < len(a) && a[i] == '~', j < len(b) && b[j] == '~'
+		if aTilde || bTilde {
+			switch {
+			case !aTilde:
+				return 1
+			case !bTilde:
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		aCaret, bCaret := i < len(a) && a[i] == '^', j < len(b) && b[j] == '^'
+		if aCaret || bCaret {
+			switch {
+			case i >= len(a):
+				return -1
+			case j >= len(b):
+				return 1
+			case !aCaret:
+				return 1
+			case !bCaret:
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		// The type of the segment is decided by the first string, so the
+		// second string's segment is empty if it has a different type.
+		isNum := isDigit(a[i])
+		match := isAlpha
+		if isNum {
+			match = isDigit
+		}
+		si, sj := i, j
+		for i < len(a) && match(a[i]) {
+			i++
+		}
+		for j < len(b) && match(b[j]) {
+			j++
+		}
+		segA, segB := a[si:i], b[sj:j]
+		if segB == "" {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		if isNum {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			switch {
+			case len(segA) > len(segB):
+				return 1
+			case len(segA) < len(segB):
+				return -1
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	default:
+		return -1
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
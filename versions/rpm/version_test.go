@@ -0,0 +1,155 @@
+package rpm
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Want    Version
+		WantErr bool
+	}{
+		{"1.0", Version{0, "1.0", ""}, false},
+		{"3.4.5-1.el8", Version{0, "3.4.5", "1.el8"}, false},
+		{"2:1.0-1", Version{2, "1.0", "1"}, false},
+		{"1.0~rc1^git20200101-1", Version{0, "1.0~rc1^git20200101", "1"}, false},
+		{" 1.0_1 ", Version{0, "1.0_1", ""}, false},
+
+		{"", Version{}, true},
+		{"a:1.0", Version{}, true},
+		{"1:", Version{}, true},
+		{"1.0-", Version{}, true},
+		{"-1", Version{}, true},
+		{"1.0-1-2", Version{}, true},
+		{"1.0 1", Version{}, true},
+		{"1.0/1", Version{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got, err := Parse(test.Input)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot: %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestVercmp(t *testing.T) {
+	// These cases are from the rpmvercmp tests in rpm's own test suite.
+	tests := []struct {
+		A, B string
+		Want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "2.0", -1},
+		{"2.0.1", "2.0.1", 0},
+		{"2.0", "2.0.1", -1},
+		{"2.0.1a", "2.0.1a", 0},
+		{"2.0.1a", "2.0.1", 1},
+		{"5.5p1", "5.5p1", 0},
+		{"5.5p1", "5.5p2", -1},
+		{"5.5p10", "5.5p10", 0},
+		{"5.5p1", "5.5p10", -1},
+		{"10xyz", "10.1xyz", -1},
+		{"xyz10", "xyz10", 0},
+		{"xyz10", "xyz10.1", -1},
+		{"xyz.4", "xyz.4", 0},
+		{"xyz.4", "8", -1},
+		{"xyz.4", "2", -1},
+		{"5.5p2", "5.6p1", -1},
+		{"5.6p1", "6.5p1", -1},
+		{"6.0.rc1", "6.0", 1},
+		{"10b2", "10a1", 1},
+		{"1.0aa", "1.0aa", 0},
+		{"1.0a", "1.0aa", -1},
+		{"10.0001", "10.0001", 0},
+		{"10.0001", "10.1", 0},
+		{"10.0001", "10.0039", -1},
+		{"4.999.9", "5.0", -1},
+		{"20101121", "20101121", 0},
+		{"20101121", "20101122", -1},
+		{"2_0", "2_0", 0},
+		{"2.0", "2_0", 0},
+		{"a", "a", 0},
+		{"a+", "a+", 0},
+		{"a+", "a_", 0},
+		{"+a", "_a", 0},
+		{"+_", "_+", 0},
+		{"1.0~rc1", "1.0~rc1", 0},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~rc1~git123", "1.0~rc1", -1},
+		{"1.0^", "1.0^", 0},
+		{"1.0^", "1.0", 1},
+		{"1.0^git1", "1.0^git2", -1},
+		{"1.0^git1", "1.01", -1},
+		{"1.0^20160101", "1.0.1", -1},
+		{"1.0~rc1^git1", "1.0~rc1", 1},
+		{"1.0^git1~pre", "1.0^git1", -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A+" "+test.B, func(t *testing.T) {
+			if got := vercmp(test.A, test.B); got != test.Want {
+				t.Errorf("wrong result\ngot:  %d\nwant: %d", got, test.Want)
+			}
+			if got := vercmp(test.B, test.A); got != -test.Want {
+				t.Errorf("wrong result for reverse comparison\ngot:  %d\nwant: %d", got, -test.Want)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		A, B string
+		Want int
+	}{
+		{"1:1.0", "2.0", 1},
+		{"0:1.0", "1.0", 0},
+		{"3.4.5-1.el8", "3.4.5-1.el9", -1},
+		{"3.4.5", "3.4.5-1", -1},
+		{"3.4.5-10", "3.4.5-9", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A+" "+test.B, func(t *testing.T) {
+			a, b := MustParse(test.A), MustParse(test.B)
+			if got := a.Compare(b); got != test.Want {
+				t.Errorf("wrong result\ngot:  %d\nwant: %d", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestVersionIsPrerelease(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  bool
+	}{
+		{"1.0", false},
+		{"1.0~rc1", true},
+		{"1.0~rc1-1.el8", true},
+		{"1.0-1~bootstrap", false},
+		{"1.0^git1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			if got, want := MustParse(test.Input).IsPrerelease(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package versions
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// SchemeVersion is a version that belongs to a particular versioning scheme.
+//
+// Version is the SchemeVersion of the scheme Semver. The subpackages for
+// other ecosystems, such as package pep440, each provide their own version
+// type and scheme, so that their versions can be collected in a List and
+// selected using a Set.
+//
+// The zero value of a version type represents the absense of a version, as
+// Unspecified does for Version. A nil SchemeVersion means the same.
+type SchemeVersion interface {
+	// Scheme returns the scheme that the version belongs to, which decides
+	// how it is ordered relative to other versions of the same scheme.
+	Scheme() Scheme
+
+	// String returns the version using the syntax of its scheme, which the
+	// ParseVersion method of the scheme must accept.
+	String() string
+}
+
+// Scheme describes the rules of a particular versioning scheme: how versions
+// are written, how they are ordered, which of them are pre-releases, and what
+// the constraint operators that allow "compatible" newer versions, like "~"
+// and "^", mean.
+//
+// The predefined scheme Semver implements the semantic versioning rules used
+// throughout the rest of this package. The methods of a scheme are called
+// only with versions that belong to it, as reported by SchemeVersion.Scheme.
+//
+// Schemes are compared using ==, and so an implementation must be of a
+// comparable type, usually an empty struct.
+type Scheme interface {
+	// ParseVersion parses the given string as a version using the syntax
+	// of the scheme, returning an error if it is not valid.
+	ParseVersion(s string) (SchemeVersion, error)
+
+	// Compare returns -1, 0 or 1 depending on whether a has lower, the same
+	// or higher precedence than b.
+	Compare(a, b SchemeVersion) int
+
+	// IsPrerelease returns true if the given version is a pre-release, which
+	// Released excludes and Prerelease includes.
+	IsPrerelease(v SchemeVersion) bool
+
+	// UpperBound returns the lowest version that is excluded by a selection
+	// using the given operator with the given lower bound, or an error if
+	// the scheme has no equivalent of that operator.
+	//
+	// The operator is one of constraints.OpGreaterThanOrEqualMinorOnly
+	// (written as "^" or as "~>" with two segments),
+	// constraints.OpGreaterThanOrEqualPatchOnly (written as "~" or as "~>"
+	// with three segments) or constraints.OpGreaterThanOrEqualPrereleaseOnly.
+	UpperBound(op constraints.SelectionOp, lower SchemeVersion) (SchemeVersion, error)
+}
+
+// Semver is the scheme that implements the rules of semantic versioning, as
+// used by ParseVersion, Version.LessThan and MeetingConstraints.
+var Semver Scheme = semverScheme{}
+
+// Scheme returns Semver, which is the scheme of all values of Version.
+func (v Version) Scheme() Scheme {
+	return Semver
+}
+
+type semverScheme struct{}
+
+func (s semverScheme) ParseVersion(str string) (SchemeVersion, error) {
+	return ParseVersion(str)
+}
+
+func (s semverScheme) Compare(a, b SchemeVersion) int {
+	return compareSemver(a.(Version), b.(Version))
+}
+
+func (s semverScheme) IsPrerelease(v SchemeVersion) bool {
+	return v.(Version).Prerelease != ""
+}
+
+func (s semverScheme) UpperBound(op constraints.SelectionOp, lower SchemeVersion) (SchemeVersion, error) {
+	v := lower.(Version)
+	switch op {
+	case constraints.OpGreaterThanOrEqualMinorOnly:
+		return v.NextMajor(), nil
+	case constraints.OpGreaterThanOrEqualPatchOnly:
+		return v.NextMinor(), nil
+	case constraints.OpGreaterThanOrEqualPrereleaseOnly:
+		return v.NextPatch(), nil
+	default:
+		return nil, fmt.Errorf("unsupported constraints.SelectionOp %s", op)
+	}
+}
+
+func (s semverScheme) GoString() string {
+	return "versions.Semver"
+}
+
+func compareSemver(a, b Version) int {
+	switch {
+	case a.LessThan(b):
+		return -1
+	case a.GreaterThan(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareVersions compares two versions using the Compare method of their
+// scheme, panicking if they belong to different schemes.
+func compareVersions(a, b SchemeVersion) int {
+	if av, ok := a.(Version); ok {
+		if bv, ok := b.(Version); ok {
+			// Fast path for the common case, avoiding the conversions to
+			// and from SchemeVersion.
+			return compareSemver(av, bv)
+		}
+	}
+	scheme := a.Scheme()
+	if b.Scheme() != scheme {
+		panic(fmt.Sprintf("can't compare %#v with %#v, since they belong to different schemes", a, b))
+	}
+	return scheme.Compare(a, b)
+}
+
+// sameScheme returns true if the two given versions belong to the same
+// scheme, and so can be given to compareVersions.
+func sameScheme(a, b SchemeVersion) bool {
+	return a.Scheme() == b.Scheme()
+}
+
+// isPrerelease returns true if the scheme of the given version considers it
+// to be a pre-release.
+func isPrerelease(v SchemeVersion) bool {
+	if v, ok := v.(Version); ok {
+		return v.Prerelease != ""
+	}
+	return v.Scheme().IsPrerelease(v)
+}
+
+// isUnspecified returns true if the given version is nil or is the zero
+// value of its type, which represents the absense of a version.
+func isUnspecified(v SchemeVersion) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case Version:
+		return v == Unspecified
+	default:
+		return reflect.ValueOf(v).IsZero()
+	}
+}
+
+// schemeLess orders schemes by their Go syntax, with Semver first, so that
+// sets and lists containing versions of several schemes can be sorted
+// deterministically.
+func schemeLess(a, b Scheme) bool {
+	switch {
+	case a == b:
+		return false
+	case a == Semver:
+		return true
+	case b == Semver:
+		return false
+	default:
+		return fmt.Sprintf("%#v", a) < fmt.Sprintf("%#v", b)
+	}
+}
@@ -0,0 +1,399 @@
+package versions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// postScheme is a scheme for testing where the prerelease portion instead
+// marks a post-release, which sorts after the corresponding release, and
+// where "~" and "^" both allow only newer patch versions.
+type postScheme struct{}
+
+// postVersion is a version of postScheme.
+type postVersion struct {
+	Version
+}
+
+func (v postVersion) Scheme() Scheme {
+	return postScheme{}
+}
+
+func (v postVersion) GoString() string {
+	return fmt.Sprintf("mustParsePost(%q)", v.String())
+}
+
+func mustParsePost(str string) postVersion {
+	return postVersion{MustParseVersion(str)}
+}
+
+func (s postScheme) ParseVersion(str string) (SchemeVersion, error) {
+	v, err := ParseVersion(str)
+	if err != nil {
+		return nil, err
+	}
+	return postVersion{v}, nil
+}
+
+func (s postScheme) Compare(a, b SchemeVersion) int {
+	av, bv := a.(postVersion), b.(postVersion)
+	switch {
+	case av.Major != bv.Major:
+		return compareNums(av.Major, bv.Major)
+	case av.Minor != bv.Minor:
+		return compareNums(av.Minor, bv.Minor)
+	case av.Patch != bv.Patch:
+		return compareNums(av.Patch, bv.Patch)
+	case av.Prerelease == bv.Prerelease:
+		return 0
+	case av.Prerelease == "":
+		return -1
+	case bv.Prerelease == "":
+		return 1
+	case av.Prerelease.LessThan(bv.Prerelease):
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (s postScheme) IsPrerelease(v SchemeVersion) bool {
+	return false
+}
+
+func (s postScheme) UpperBound(op constraints.SelectionOp, lower SchemeVersion) (SchemeVersion, error) {
+	if op == constraints.OpGreaterThanOrEqualPrereleaseOnly {
+		return nil, fmt.Errorf("postScheme doesn't support %s", op)
+	}
+	return postVersion{lower.(postVersion).NextMinor()}, nil
+}
+
+func compareNums(a, b uint64) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+func TestMeetingConstraintsWithScheme(t *testing.T) {
+	tests := []struct {
+		Constraints string
+		Version     string
+		Semver      bool
+		Post        bool
+	}{
+		{">=1.0.0", "1.0.0-1", false, true},
+		{"<1.0.0", "1.0.0-1", false, false},
+		{"<1.0.0", "0.9.0-1", false, true},
+		{"^1.2.0", "1.2.5", true, true},
+		{"^1.2.0", "1.3.0", true, false},
+		{"~1.2.0", "1.2.9-2", false, true},
+		{"1.0.0", "1.0.0", true, true},
+		{"1.0.0-1", "1.0.0-1", true, true},
+		{"!1.0.0", "1.0.1", true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s has %s", test.Constraints, test.Version), func(t *testing.T) {
+			spec, err := constraints.Parse(test.Constraints)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := MustParseVersion(test.Version)
+
+			set, err := MeetingConstraintsWithScheme(spec, Semver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := set.Has(v), test.Semver; got != want {
+				t.Errorf("wrong result for Semver\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+			if got, want := set.Has(v), MeetingConstraints(spec).Has(v); got != want {
+				t.Errorf("Semver result doesn't match MeetingConstraints\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+
+			set, err = MeetingConstraintsWithScheme(spec, postScheme{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := set.Has(postVersion{v}), test.Post; got != want {
+				t.Errorf("wrong result for postScheme\nset:  %#v\ngot:  %t\nwant: %t", set, got, want)
+			}
+		})
+	}
+}
+
+func TestMeetingConstraintsWithSchemeErrors(t *testing.T) {
+	spec, err := constraints.ParseCargo("^0.0.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MeetingConstraintsExactWithScheme(spec, postScheme{}); err == nil {
+		t.Errorf("succeeded; want error for unsupported operator")
+	}
+	if _, err := MeetingConstraintsExactWithScheme(spec, Semver); err != nil {
+		t.Errorf("unexpected error for Semver: %s", err)
+	}
+}
+
+func TestSetHasScheme(t *testing.T) {
+	semver := AtLeast(MustParseVersion("1.0.0"))
+	post := AtLeast(mustParsePost("1.0.0"))
+
+	if !semver.Has(MustParseVersion("1.0.0")) {
+		t.Errorf("semver set doesn't have its own bound")
+	}
+	if semver.Has(mustParsePost("2.0.0")) {
+		t.Errorf("semver set has a postScheme version")
+	}
+	if post.Has(MustParseVersion("2.0.0")) {
+		t.Errorf("postScheme set has a semver version")
+	}
+	if !Only(mustParsePost("1.0.0")).Requests(mustParsePost("1.0.0")) {
+		t.Errorf("Only doesn't request its version")
+	}
+	if !Released.Has(mustParsePost("1.0.0-1")) {
+		t.Errorf("Released doesn't have a released postScheme version")
+	}
+
+	// The unspecified version is a member only of All.
+	if !All.Has(nil) {
+		t.Errorf("All doesn't have nil")
+	}
+	if post.Has(nil) || All.Subtract(post).Has(nil) {
+		t.Errorf("constrained set has nil")
+	}
+	if All.Subtract(post).Has(postVersion{}) {
+		t.Errorf("constrained set has the zero postVersion")
+	}
+
+	// Normalizing must preserve the members of each scheme.
+	set := Union(post.Intersection(OlderThan(mustParsePost("2.0.0"))), semver).Normalize()
+	for _, v := range []SchemeVersion{
+		MustParseVersion("1.0.0"),
+		mustParsePost("1.0.0"),
+		mustParsePost("1.5.0-1"),
+	} {
+		if !set.Has(v) {
+			t.Errorf("normalized set doesn't have %#v\nset: %#v", v, set)
+		}
+	}
+	for _, v := range []SchemeVersion{
+		MustParseVersion("0.9.0"),
+		mustParsePost("0.9.0"),
+		mustParsePost("2.0.0"),
+	} {
+		if set.Has(v) {
+			t.Errorf("normalized set has %#v\nset: %#v", v, set)
+		}
+	}
+}
+
+func TestSetCompareScheme(t *testing.T) {
+	p := mustParsePost
+	s := MustParseVersion
+	matching := Matching(func(v SchemeVersion) bool {
+		return true
+	})
+
+	tests := []struct {
+		Name     string
+		A, B     Set
+		Empty    bool // A is empty
+		Equal    bool
+		Subset   bool // A is a subset of B
+		Overlaps bool
+	}{
+		{
+			"contradictory bounds",
+			AtLeast(p("2.0.0")).Intersection(OlderThan(p("1.0.0"))),
+			None,
+			true, true, true, false,
+		},
+		{
+			"same bound written differently",
+			AtLeast(p("1.0.0")),
+			Union(Only(p("1.0.0")), NewerThan(p("1.0.0"))),
+			false, true, true, true,
+		},
+		{
+			"post-release newer than release",
+			Only(p("1.0.0-1")),
+			NewerThan(p("1.0.0")).Intersection(OlderThan(p("1.0.1"))),
+			false, false, true, true,
+		},
+		{
+			"exclusive bounds at the same version",
+			NewerThan(p("1.0.0")).Intersection(OlderThan(p("1.0.0"))),
+			None,
+			true, true, true, false,
+		},
+		{
+			"assumes versions between different versions",
+			NewerThan(p("1.0.0")).Intersection(OlderThan(p("1.0.1"))),
+			None,
+			false, false, false, false,
+		},
+		{
+			"semver has no released versions between patches",
+			NewerThan(s("1.0.0")).Intersection(OlderThan(s("1.0.1"))).Intersection(Released),
+			None,
+			true, true, true, false,
+		},
+		{
+			"different schemes",
+			AtLeast(s("1.0.0")),
+			AtLeast(p("1.0.0")),
+			false, false, false, false,
+		},
+		{
+			"released versions of other schemes",
+			Released.Subtract(AtLeast(p("1.0.0"))),
+			OlderThan(p("1.0.0")),
+			false, false, false, true,
+		},
+		{
+			"all versions",
+			Released.Union(Prerelease),
+			All.Subtract(None),
+			false, true, true, true,
+		},
+		{
+			"exact versions of several schemes",
+			Selection(p("1.0.0"), s("1.0.0")),
+			Union(AtLeast(p("1.0.0")), Only(s("1.0.0"))),
+			false, false, true, true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			check := func(method string, got, want bool) {
+				t.Helper()
+				if got != want {
+					t.Errorf("wrong %s result\na:    %#v\nb:    %#v\ngot:  %t\nwant: %t", method, test.A, test.B, got, want)
+				}
+			}
+
+			check("IsEmpty", test.A.IsEmpty(), test.Empty)
+			check("Equal", test.A.Equal(test.B), test.Equal)
+			check("IsSubsetOf", test.A.IsSubsetOf(test.B), test.Subset)
+			check("Overlaps", test.A.Overlaps(test.B), test.Overlaps)
+		})
+	}
+
+	if matching.IsNormalizable() {
+		t.Errorf("set built using Matching is normalizable")
+	}
+	if !matching.Has(p("1.0.0")) || !matching.Has(s("1.0.0")) {
+		t.Errorf("set built using Matching doesn't have the versions its function accepts")
+	}
+}
+
+func TestSetSchemeGoString(t *testing.T) {
+	// The Go syntax of a normalized set must produce a set with the same
+	// members, so we check that it describes each part of the set.
+	tests := []struct {
+		Set  Set
+		Want string
+	}{
+		{
+			AtLeast(mustParsePost("1.0.0")).Normalize(),
+			`versions.Union(versions.Intersection(versions.Released, versions.AtLeast(mustParsePost("1.0.0"))), versions.Intersection(versions.Prerelease, versions.NewerThan(mustParsePost("1.0.0")))).Normalize()`,
+		},
+		{
+			Released.Normalize(),
+			`versions.Released.Normalize()`,
+		},
+		{
+			Released.Subtract(AtLeast(mustParsePost("1.0.0"))).Normalize(),
+			`versions.Released.Subtract(versions.Union(versions.Intersection(versions.Released, versions.AtLeast(mustParsePost("1.0.0")))).Normalize()).Normalize()`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Want, func(t *testing.T) {
+			if got := fmt.Sprintf("%#v", test.Set); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSetSchemeEncoding(t *testing.T) {
+	if _, err := AtLeast(mustParsePost("1.0.0")).MarshalBinary(); err == nil {
+		t.Errorf("MarshalBinary succeeded for a postScheme range; want error")
+	}
+	if _, err := Only(mustParsePost("1.0.0")).MarshalText(); err == nil {
+		t.Errorf("MarshalText succeeded for a postScheme version; want error")
+	}
+	if _, err := (List{mustParsePost("1.0.0")}).MarshalBinary(); err == nil {
+		t.Errorf("List.MarshalBinary succeeded for a postScheme version; want error")
+	}
+
+	// Released contains all of the released versions of other schemes, and
+	// must keep doing so when encoded.
+	set := Released.Intersection(All.Subtract(Only(MustParseVersion("1.0.0"))))
+	buf, err := set.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Set
+	if err := got.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(set) {
+		t.Errorf("wrong set after binary round trip\ngot:  %#v\nwant: %#v", got, set)
+	}
+	if !got.Has(mustParsePost("1.0.0")) {
+		t.Errorf("set lost the released versions of postScheme in binary round trip")
+	}
+	text, err := set.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(text), "* !1.0.0"; got != want {
+		t.Errorf("wrong text\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestListScheme(t *testing.T) {
+	l := List{
+		mustParsePost("1.0.1"),
+		mustParsePost("1.0.0-2"),
+		mustParsePost("1.0.0"),
+		mustParsePost("1.0.0-1"),
+	}
+	l.Sort()
+
+	want := List{
+		mustParsePost("1.0.0"),
+		mustParsePost("1.0.0-1"),
+		mustParsePost("1.0.0-2"),
+		mustParsePost("1.0.1"),
+	}
+	for i := range want {
+		if l[i] != want[i] {
+			t.Errorf("wrong version at index %d\ngot:  %s\nwant: %s", i, l[i], want[i])
+		}
+	}
+	if !l.IsSorted() {
+		t.Errorf("list is not sorted")
+	}
+
+	set := OlderThan(mustParsePost("1.0.1"))
+	if got, want := l.NewestInSet(set), mustParsePost("1.0.0-2"); got != want {
+		t.Errorf("wrong newest version in set\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := l.Newest(), mustParsePost("1.0.1"); got != want {
+		t.Errorf("wrong newest version\ngot:  %s\nwant: %s", got, want)
+	}
+	if got := l.NewestInSet(None); got != Unspecified {
+		t.Errorf("wrong newest version in empty set\ngot:  %s\nwant: Unspecified", got)
+	}
+	if got, want := len(l.Filter(set)), 3; got != want {
+		t.Errorf("wrong number of versions after Filter\ngot:  %d\nwant: %d", got, want)
+	}
+}
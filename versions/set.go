@@ -1,6 +1,11 @@
 package versions
 
 // Set is a set of versions, usually created by parsing a constraint string.
+//
+// A set may contain versions of any scheme, as described for SchemeVersion.
+// Each version is compared only with versions of the same scheme, so a set
+// bounded by a version of one scheme, such as AtLeast(v), contains no
+// versions of any other scheme.
 type Set struct {
 	setI
 }
@@ -8,17 +13,18 @@ type Set struct {
 // setI is the private interface implemented by our various constraint
 // operators.
 type setI interface {
-	Has(v Version) bool
+	Has(v SchemeVersion) bool
 	AllRequested() Set
 	GoString() string
 }
 
 // Has returns true if the given version is a member of the receiving set.
-func (s Set) Has(v Version) bool {
-	// The special Unspecified version is excluded as soon as any sort of
-	// constraint is applied, and so the only set it is a member of is
+func (s Set) Has(v SchemeVersion) bool {
+	// The special Unspecified version, and the zero values of the version
+	// types of other schemes, are excluded as soon as any sort of
+	// constraint is applied, and so the only set they are members of is
 	// the special All set.
-	if v == Unspecified {
+	if isUnspecified(v) {
 		return s == All
 	}
 
@@ -41,7 +47,7 @@ func (s Set) Has(v Version) bool {
 // ">= 1.0.0" the user generally does not intend to match a pre-release version
 // like "2.0.0-beta1", but it is important to stil be able to use that
 // version if explicitly requested using the constraint string "2.0.0-beta1".
-func (s Set) Requests(v Version) bool {
+func (s Set) Requests(v SchemeVersion) bool {
 	return s.AllRequested().Has(v)
 }
 
@@ -70,6 +76,30 @@ func (s Set) WithoutUnrequestedPrereleases() Set {
 	return Union(s.AllRequested(), Released.Intersection(s))
 }
 
+// MarshalText is an implementation of encoding.TextMarshaler, allowing sets
+// to be automatically marshalled for text-based serialization formats,
+// including encoding/json.
+//
+// The result is a minimal constraint string in the canonical syntax accepted
+// by MeetingConstraintsString that selects the same versions as the receiver.
+// It is not necessarily the same string the set was originally created from,
+// and the requested versions of the set are not preserved except that any
+// exact version selections are written as such.
+//
+// Not all sets can be represented as constraint strings, because
+// MeetingConstraintsString excludes any pre-release versions that are not
+// selected exactly. For example, AtLeast(v) includes all of the pre-release
+// versions greater than v and so cannot be marshalled, whereas
+// AtLeast(v).Intersection(Released) can. An error is returned for a set that
+// cannot be represented.
+func (s Set) MarshalText() (text []byte, err error) {
+	spec, err := s.constraintSpec()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(spec.String()), nil
+}
+
 // UnmarshalText is an implementation of encoding.TextUnmarshaler, allowing
 // sets to be automatically unmarshalled from strings in text-based
 // serialization formats, including encoding/json.
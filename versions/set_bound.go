@@ -5,20 +5,24 @@ import (
 )
 
 type setBound struct {
-	v  Version
+	v  SchemeVersion
 	op setBoundOp
 }
 
-func (s setBound) Has(v Version) bool {
+func (s setBound) Has(v SchemeVersion) bool {
+	if !sameScheme(v, s.v) {
+		return false
+	}
+	c := compareVersions(v, s.v)
 	switch s.op {
 	case setBoundGT:
-		return v.GreaterThan(s.v)
+		return c > 0
 	case setBoundGTE:
-		return v.GreaterThan(s.v) || v.Same(s.v)
+		return c >= 0
 	case setBoundLT:
-		return v.LessThan(s.v)
+		return c < 0
 	case setBoundLTE:
-		return v.LessThan(s.v) || v.Same(s.v)
+		return c <= 0
 	default:
 		// Should never happen because the above is exhaustive
 		panic("invalid setBound operator")
@@ -48,7 +52,11 @@ func (s setBound) GoString() string {
 
 // NewerThan returns a set containing all versions greater than the given
 // version, non-inclusive.
-func NewerThan(v Version) Set {
+//
+// This and the other functions that create sets bounded by a version
+// compare versions using the scheme of the given version, and so the result
+// contains only versions of that scheme.
+func NewerThan(v SchemeVersion) Set {
 	return Set{
 		setI: setBound{
 			v:  v,
@@ -59,7 +67,7 @@ func NewerThan(v Version) Set {
 
 // OlderThan returns a set containing all versions lower than the given
 // version, non-inclusive.
-func OlderThan(v Version) Set {
+func OlderThan(v SchemeVersion) Set {
 	return Set{
 		setI: setBound{
 			v:  v,
@@ -70,7 +78,7 @@ func OlderThan(v Version) Set {
 
 // AtLeast returns a set containing all versions greater than or equal to
 // the given version.
-func AtLeast(v Version) Set {
+func AtLeast(v SchemeVersion) Set {
 	return Set{
 		setI: setBound{
 			v:  v,
@@ -81,7 +89,7 @@ func AtLeast(v Version) Set {
 
 // AtMost returns a set containing all versions less than or equal to the given
 // version, non-inclusive.
-func AtMost(v Version) Set {
+func AtMost(v SchemeVersion) Set {
 	return Set{
 		setI: setBound{
 			v:  v,
@@ -0,0 +1,422 @@
+package versions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// channelRanges is the range list for the pre-release versions of one
+// channel in a normalized set, as described for setNormal.
+type channelRanges struct {
+	channel string
+	ranges  rangeList
+}
+
+// channelClass returns the channel whose range list decides whether the
+// given pre-release version is a member of a normalized set that
+// distinguishes the given channels. This is the longest of the channels that
+// the version's pre-release string starts with, or the empty string if it
+// starts with none of them, in which case the list of other pre-release
+// versions applies.
+func channelClass(pre VersionExtra, channels []string) string {
+	ret := ""
+	for _, c := range channels {
+		if len(c) > len(ret) && pre.HasPrefixParts(c) {
+			ret = c
+		}
+	}
+	return ret
+}
+
+// channelEnd returns the lowest pre-release string that has higher precedence
+// than all of the pre-release strings in the given channel, so that the
+// versions of the channel for a particular release are those from the
+// channel itself up to but not including its end.
+func channelEnd(channel string) string {
+	i := strings.LastIndexByte(channel, '.')
+	last := channel[i+1:]
+	if !isNumericIdent(last) {
+		// A hyphen is the lowest character allowed in an identifier, and
+		// an alphanumeric identifier sorts after all of its prefixes.
+		return channel + "-"
+	}
+
+	// Numeric identifiers can be arbitrarily long, so we increment the
+	// decimal string directly rather than risk overflowing an integer.
+	digits := []byte(last)
+	for j := len(digits) - 1; j >= 0; j-- {
+		if digits[j] < '9' {
+			digits[j]++
+			return channel[:i+1] + string(digits)
+		}
+		digits[j] = '0'
+	}
+	return channel[:i+1] + "1" + string(digits)
+}
+
+// extraRange is a half-open interval of pre-release strings, with the same
+// meaning as versionRange except that an unbounded range extends to the end
+// of the pre-releases of a particular release.
+type extraRange struct {
+	lower     VersionExtra
+	upper     VersionExtra
+	unbounded bool
+}
+
+// classRanges returns the ranges of pre-release strings, in order, that
+// belong to the given class of a normalized set that distinguishes the given
+// channels. The result is the same for the pre-releases of every release.
+func classRanges(class string, channels []string) []extraRange {
+	// Membership of a class can only change where a channel starts or ends.
+	points := make([]VersionExtra, 0, 1+len(channels)*2)
+	points = append(points, minVersion.Prerelease)
+	for _, c := range channels {
+		points = append(points, VersionExtra(c), VersionExtra(channelEnd(c)))
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].LessThan(points[j])
+	})
+
+	var ret []extraRange
+	for i, point := range points {
+		if i > 0 && point == points[i-1] {
+			continue
+		}
+		if channelClass(point, channels) != class {
+			continue
+		}
+		r := extraRange{lower: point, unbounded: true}
+		for _, next := range points[i+1:] {
+			if next != point {
+				r.upper, r.unbounded = next, false
+				break
+			}
+		}
+		if n := len(ret); n > 0 && !ret[n-1].unbounded && ret[n-1].upper == point {
+			ret[n-1].upper, ret[n-1].unbounded = r.upper, r.unbounded
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// channelBound returns a function that returns the lowest pre-release
+// version in the given class that is greater than or equal to its argument,
+// which is the canonical form of a range bound in the range list for that
+// class of a normalized set that distinguishes the given channels.
+//
+// The class of pre-releases that are in none of the channels is written as
+// the empty string. If there are no channels at all then the result is
+// prereleaseBound.
+func channelBound(class string, channels []string) func(Version) Version {
+	if len(channels) == 0 {
+		return prereleaseBound
+	}
+	ranges := classRanges(class, channels)
+	return func(v Version) Version {
+		v.Metadata = ""
+		if v.Prerelease != "" {
+			for _, r := range ranges {
+				if r.unbounded || v.Prerelease.LessThan(r.upper) {
+					if v.Prerelease.LessThan(r.lower) {
+						v.Prerelease = r.lower
+					}
+					return v
+				}
+			}
+		}
+		// Every class has some pre-releases of every release, so the
+		// lowest one in the class is from the next patch version.
+		v.Patch++
+		v.Prerelease = ranges[0].lower
+		return v
+	}
+}
+
+// recanonRanges returns a range list containing the versions of a particular
+// class from the given range list, after canonicalizing its bounds using the
+// given function for that class.
+func recanonRanges(l rangeList, canon func(Version) Version) rangeList {
+	var ret rangeList
+	for _, r := range l {
+		r.lower = canon(r.lower)
+		if !r.unbounded {
+			r.upper = canon(r.upper)
+			if !r.lower.LessThan(r.upper) {
+				continue
+			}
+		}
+		if n := len(ret); n > 0 && !ret[n-1].unbounded && !ret[n-1].upper.LessThan(r.lower) {
+			ret[n-1].upper, ret[n-1].unbounded = r.upper, r.unbounded
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// channelNames returns the channels that the receiver distinguishes, in the
+// same order as its channels field.
+func (s setNormal) channelNames() []string {
+	if len(s.channels) == 0 {
+		return nil
+	}
+	ret := make([]string, len(s.channels))
+	for i, c := range s.channels {
+		ret[i] = c.channel
+	}
+	return ret
+}
+
+// classRanges returns the range list for the given class of the receiver,
+// where the empty string is the class of pre-releases in none of its
+// channels.
+func (s setNormal) classRanges(class string) rangeList {
+	for _, c := range s.channels {
+		if c.channel == class {
+			return c.ranges
+		}
+	}
+	return s.prerelease
+}
+
+// rangesForClass returns the range list for the given class of a normalized
+// set that distinguishes the given channels, which must include all of the
+// channels of the receiver, canonicalized using the given function for that
+// class.
+func (s setNormal) rangesForClass(class string, channels []string, canon func(Version) Version) rangeList {
+	own := s.channelNames()
+	l := s.classRanges(channelClass(VersionExtra(class), own))
+	if len(own) == len(channels) {
+		// The receiver already distinguishes all of the same classes.
+		return l
+	}
+	return recanonRanges(l, canon)
+}
+
+// combinePrereleaseRanges sets the pre-release range lists of the receiver,
+// which must have none, to those that contain each pre-release version that
+// passes the given boolean operator when applied to its membership of the
+// ranges of the two given sets.
+func (s *setNormal) combinePrereleaseRanges(a, b setNormal, op func(a, b bool) bool) {
+	channels := mergeChannelNames(a.channelNames(), b.channelNames())
+	combine := func(class string) rangeList {
+		canon := channelBound(class, channels)
+		return combineRanges(
+			a.rangesForClass(class, channels, canon),
+			b.rangesForClass(class, channels, canon),
+			canon(minVersion), op,
+		)
+	}
+	s.prerelease = combine("")
+	for _, c := range channels {
+		s.channels = append(s.channels, channelRanges{channel: c, ranges: combine(c)})
+	}
+	s.pruneChannels()
+}
+
+// pruneChannels removes any channels from the receiver whose pre-releases
+// would have the same membership if the receiver did not distinguish them,
+// so that there is exactly one normalized representation for each distinct
+// set of members.
+func (s *setNormal) pruneChannels() {
+	for i := 0; i < len(s.channels); {
+		channels := s.channelNames()
+		c := s.channels[i]
+		rest := append(append([]string(nil), channels[:i]...), channels[i+1:]...)
+		parent := channelClass(VersionExtra(c.channel), rest)
+
+		merged, ok := mergeClassRanges(
+			s.classRanges(parent), channelBound(parent, channels),
+			c.ranges, channelBound(c.channel, channels),
+			channelBound(parent, rest),
+		)
+		if !ok {
+			i++
+			continue
+		}
+
+		s.channels = append(s.channels[:i:i], s.channels[i+1:]...)
+		if parent == "" {
+			s.prerelease = merged
+		} else {
+			for j := range s.channels {
+				if s.channels[j].channel == parent {
+					s.channels[j].ranges = merged
+				}
+			}
+		}
+		if len(s.channels) == 0 {
+			s.channels = nil
+		}
+		// Removing a channel can allow merging one we already kept.
+		i = 0
+	}
+}
+
+// mergeClassRanges returns a single range list for the class that contains
+// the two classes whose range lists and bound functions are given, using the
+// given bound function for the merged class, if there is one that gives the
+// same results for the versions of each class. Otherwise it returns false.
+func mergeClassRanges(a rangeList, canonA func(Version) Version, b rangeList, canonB func(Version) Version, canon func(Version) Version) (rangeList, bool) {
+	// Neither list changes within the intervals between their bounds, so in
+	// each such interval the versions of each class are either all members
+	// or all not.
+	bounds := []Version{canon(minVersion)}
+	for _, l := range [...]rangeList{a, b} {
+		for _, r := range l {
+			bounds = append(bounds, canon(r.lower))
+			if !r.unbounded {
+				bounds = append(bounds, canon(r.upper))
+			}
+		}
+	}
+	sortBounds(bounds)
+
+	var ret rangeList
+	add := func(r versionRange) {
+		if n := len(ret); n > 0 && !ret[n-1].unbounded && ret[n-1].upper == r.lower {
+			ret[n-1].upper, ret[n-1].unbounded = r.upper, r.unbounded
+			return
+		}
+		ret = append(ret, r)
+	}
+	for i, bound := range bounds {
+		if i > 0 && bound.Same(bounds[i-1]) {
+			continue
+		}
+		r := versionRange{lower: bound, unbounded: true}
+		for _, v := range bounds[i+1:] {
+			if !v.Same(bound) {
+				r.upper, r.unbounded = v, false
+				break
+			}
+		}
+
+		lowerA, lowerB := canonA(bound), canonB(bound)
+		hasA := r.unbounded || lowerA.LessThan(r.upper)
+		hasB := r.unbounded || lowerB.LessThan(r.upper)
+		inA, inB := a.contains(lowerA), b.contains(lowerB)
+		switch {
+		case hasA && hasB && inA != inB:
+			// Membership changes wherever the versions of one class give
+			// way to the other, which is representable only if that
+			// happens a finite number of times.
+			ranges, ok := alternatingRanges(r, inA, canonA, canonB)
+			if !ok {
+				return nil, false
+			}
+			for _, r := range ranges {
+				add(r)
+			}
+		case (hasA && inA) || (hasB && inB):
+			add(r)
+		}
+	}
+	return ret, true
+}
+
+// alternatingRanges returns the ranges of versions within the given range
+// that belong to the first of the two classes whose bound functions are
+// given if inFirst is true, or to the second class otherwise, or false if
+// there are infinitely many such ranges.
+func alternatingRanges(r versionRange, inFirst bool, canonFirst, canonSecond func(Version) Version) ([]versionRange, bool) {
+	canonIn, canonOut := canonFirst, canonSecond
+	if !inFirst {
+		canonIn, canonOut = canonSecond, canonFirst
+	}
+	if r.unbounded {
+		return nil, false
+	}
+
+	// Each class has a finite number of ranges within the pre-releases of
+	// a single release, so there are finitely many only if the range does
+	// not span any whole release.
+	sameRelease := func(a, b Version) bool {
+		return a.Major == b.Major && a.Minor == b.Minor && a.Patch == b.Patch
+	}
+	var ret []versionRange
+	for v := r.lower; ; {
+		lower := canonIn(v)
+		if !lower.LessThan(r.upper) {
+			return ret, true
+		}
+		if !sameRelease(lower, r.lower) && !sameRelease(lower, r.upper) {
+			return nil, false
+		}
+		upper := canonOut(lower)
+		if !upper.LessThan(r.upper) {
+			upper = r.upper
+		}
+		ret = append(ret, versionRange{lower: lower, upper: upper})
+		v = upper
+	}
+}
+
+// mergeChannelNames returns the sorted union of the two given sorted lists
+// of channel names.
+func mergeChannelNames(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+	ret := make([]string, 0, len(a)+len(b))
+	ret = append(ret, a...)
+	for _, c := range b {
+		i := sort.SearchStrings(ret, c)
+		if i < len(ret) && ret[i] == c {
+			continue
+		}
+		ret = append(ret, "")
+		copy(ret[i+1:], ret[i:])
+		ret[i] = c
+	}
+	return ret
+}
+
+// normalizeChannel returns the normalized form of the set of pre-releases in
+// the given channel, as returned by PrereleaseChannel.
+func normalizeChannel(channel string) setNormal {
+	if !validPrerelease(channel) {
+		// No valid version is in a channel with an invalid name.
+		return normalNone
+	}
+	channels := []string{channel}
+	return setNormal{
+		channels: []channelRanges{
+			{
+				channel: channel,
+				ranges:  newRangeList(minVersion, Unspecified, true, channelBound(channel, channels)),
+			},
+		},
+	}
+}
+
+// classGoString returns a Go expression for the set of all pre-releases in
+// the given class of a normalized set that distinguishes the given channels.
+func classGoString(class string, channels []string) string {
+	kind := "versions.Prerelease"
+	if class != "" {
+		kind = fmt.Sprintf("versions.PrereleaseChannel(%q)", class)
+	}
+
+	// The class excludes the channels within it that have their own classes.
+	var excluded []string
+	for _, c := range channels {
+		if c != class && (class == "" || VersionExtra(c).HasPrefixParts(class)) {
+			excluded = append(excluded, fmt.Sprintf("versions.PrereleaseChannel(%q)", c))
+		}
+	}
+	switch len(excluded) {
+	case 0:
+		return kind
+	case 1:
+		return fmt.Sprintf("%s.Subtract(%s)", kind, excluded[0])
+	default:
+		return fmt.Sprintf("%s.Subtract(versions.Union(%s))", kind, strings.Join(excluded, ", "))
+	}
+}
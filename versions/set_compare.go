@@ -0,0 +1,59 @@
+package versions
+
+import (
+	"fmt"
+)
+
+// IsEmpty returns true if the receiving set contains no versions at all.
+//
+// Unlike comparing with None, this method recognizes sets that are empty
+// due to contradictory constraints, such as the set produced by the
+// constraint string ">=2.0.0 <1.0.0". Because of this, it panics if the
+// receiver can't be normalized, as reported by IsNormalizable.
+func (s Set) IsEmpty() bool {
+	return normalizeCompared(s).isEmpty()
+}
+
+// Equal returns true if the receiver and the other given set have exactly
+// the same members.
+//
+// Only membership is compared, so two sets may be equal even though they
+// request different versions. The special version Unspecified is also not
+// considered, since it is a member only of the set All.
+//
+// Equal panics if either set can't be normalized, as reported by
+// IsNormalizable.
+func (s Set) Equal(other Set) bool {
+	a := normalizeCompared(s)
+	b := normalizeCompared(other)
+	return normalSubtract(a, b).isEmpty() && normalSubtract(b, a).isEmpty()
+}
+
+// IsSubsetOf returns true if all of the members of the receiver are also
+// members of the other given set.
+//
+// An empty set is a subset of every set, and every set is a subset of itself.
+// IsSubsetOf panics if either set can't be normalized, as reported by
+// IsNormalizable.
+func (s Set) IsSubsetOf(other Set) bool {
+	return normalSubtract(normalizeCompared(s), normalizeCompared(other)).isEmpty()
+}
+
+// Overlaps returns true if the receiver and the other given set have at least
+// one member in common, panicking if either set can't be normalized, as
+// reported by IsNormalizable.
+//
+// This is equivalent to checking whether the intersection of the two sets
+// is not empty.
+func (s Set) Overlaps(other Set) bool {
+	return !normalIntersection(normalizeCompared(s), normalizeCompared(other)).isEmpty()
+}
+
+// normalizeCompared returns the normalized form of a set given to one of the
+// methods that compare sets, panicking with an explanation if it has none.
+func normalizeCompared(s Set) setNormal {
+	if problem := normalizeProblem(s.setI); problem != "" {
+		panic(fmt.Errorf("can't compare a set that %s; use Set.IsNormalizable to check first", problem))
+	}
+	return normalize(s.setI)
+}
@@ -0,0 +1,223 @@
+package versions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetIsEmpty(t *testing.T) {
+	tests := []struct {
+		Set  Set
+		Want bool
+	}{
+		{None, true},
+		{All, false},
+		{Released, false},
+		{Prerelease, false},
+		{Released.Intersection(Prerelease), true},
+		{Selection(), true},
+		{Only(MustParseVersion("1.0.0")), false},
+		{Only(MustParseVersion("1.0.0")).Subtract(Released), true},
+		{Only(MustParseVersion("1.0.0-beta.1")).Subtract(Released), false},
+		{Only(MustParseVersion("1.0.0+abc")).Subtract(Only(MustParseVersion("1.0.0"))), false},
+		{MustMakeSet(MeetingConstraintsString(">=2.0.0 <1.0.0")), true},
+		{MustMakeSet(MeetingConstraintsString(">1.0.0 <1.0.1")), true},
+		{MustMakeSet(MeetingConstraintsString(">=1.0.0 <=1.0.0")), false},
+		{MustMakeSet(MeetingConstraintsString("1.0.0 !1.0.0")), true},
+		{MustMakeSet(MeetingConstraintsString(">=2.0.0 <1.0.0 || 1.5.0")), false},
+		{MustMakeSet(MeetingConstraintsStringRuby("!= 2.0.0-beta1, 2.0.0-beta1")), true},
+		{Intersection(Prerelease, NewerThan(MustParseVersion("1.0.0")), OlderThan(MustParseVersion("1.0.1-0"))), true},
+		{Intersection(Prerelease, NewerThan(MustParseVersion("1.0.0")), AtMost(MustParseVersion("1.0.1-0"))), false},
+		{Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0"))), false},
+		{Intersection(PrereleaseChannel("rc"), Released), true},
+		{Intersection(PrereleaseChannel("rc"), PrereleaseChannel("beta")), true},
+		{Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0-rc-")), OlderThan(MustParseVersion("1.0.1-0"))), true},
+		{Intersection(PrereleaseChannel("rc"), NewerThan(MustParseVersion("1.0.0-rc.5")), OlderThan(MustParseVersion("1.0.0"))), false},
+		{Intersection(PrereleaseChannel("1"), AtLeast(MustParseVersion("1.0.0-1.5")), OlderThan(MustParseVersion("1.0.0-2"))), false},
+		{Intersection(PrereleaseChannel("1"), AtLeast(MustParseVersion("1.0.0-0")), OlderThan(MustParseVersion("1.0.0-1"))), true},
+		{PrereleaseChannel("rc").Subtract(Prerelease), true},
+		{PrereleaseChannel("rc").Subtract(PrereleaseChannel("rc.1")), false},
+		{PrereleaseChannel("rc.1").Subtract(PrereleaseChannel("rc")), true},
+		{PrereleaseChannel(""), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString(), func(t *testing.T) {
+			if got := test.Set.IsEmpty(); got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSetRelationships(t *testing.T) {
+	tests := []struct {
+		A, B       Set
+		Equal      bool
+		IsSubsetOf bool
+		Overlaps   bool
+	}{
+		{
+			All,
+			All,
+			true, true, true,
+		},
+		{
+			None,
+			None,
+			true, true, false,
+		},
+		{
+			None,
+			All,
+			false, true, false,
+		},
+		{
+			All,
+			Union(Released, Prerelease),
+			true, true, true,
+		},
+		{
+			Released,
+			All,
+			false, true, true,
+		},
+		{
+			Released,
+			Prerelease,
+			false, false, false,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <2.0.0 || >=1.5.0 <3.0.0")),
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <3.0.0")),
+			true, true, true,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.2")),
+			MustMakeSet(MeetingConstraintsString("^1.0")),
+			false, true, true,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0")),
+			MustMakeSet(MeetingConstraintsString("^1.2")),
+			false, false, true,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0")),
+			MustMakeSet(MeetingConstraintsString("^2.0")),
+			false, false, false,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("<=1.0.0")),
+			MustMakeSet(MeetingConstraintsString(">=1.0.0")),
+			false, false, true,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("<1.0.0")),
+			MustMakeSet(MeetingConstraintsString(">=1.0.0")),
+			false, false, false,
+		},
+		{
+			Only(MustParseVersion("1.0.0")),
+			Only(MustParseVersion("1.0.0+abc")),
+			false, false, false,
+		},
+		{
+			Only(MustParseVersion("1.0.0+abc")),
+			AtLeast(MustParseVersion("1.0.0")),
+			false, true, true,
+		},
+		{
+			AtLeast(MustParseVersion("1.0.0")).Subtract(Only(MustParseVersion("1.0.0"))),
+			NewerThan(MustParseVersion("1.0.0")),
+			false, false, true,
+		},
+		{
+			// The first set still contains 1.0.0 with any metadata other
+			// than the empty string, so it isn't equal to the second.
+			NewerThan(MustParseVersion("1.0.0")),
+			AtLeast(MustParseVersion("1.0.0")).Subtract(Only(MustParseVersion("1.0.0"))),
+			false, true, true,
+		},
+		{
+			PrereleaseChannel("rc"),
+			Prerelease,
+			false, true, true,
+		},
+		{
+			Union(PrereleaseChannel("rc"), Prerelease.Subtract(PrereleaseChannel("rc"))),
+			Prerelease,
+			true, true, true,
+		},
+		{
+			PrereleaseChannel("rc"),
+			PrereleaseChannel("beta"),
+			false, false, false,
+		},
+		{
+			Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0-0")), OlderThan(MustParseVersion("1.0.0"))),
+			Only(MustParseVersion("1.0.0-rc.1")),
+			false, false, true,
+		},
+		{
+			Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0-rc")), OlderThan(MustParseVersion("1.0.0-rc-"))),
+			Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0-0")), OlderThan(MustParseVersion("1.0.0"))),
+			true, true, true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A.GoString()+" vs. "+test.B.GoString(), func(t *testing.T) {
+			if got, want := test.A.Equal(test.B), test.Equal; got != want {
+				t.Errorf("wrong Equal result\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := test.B.Equal(test.A), test.Equal; got != want {
+				t.Errorf("wrong Equal result when reversed\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := test.A.IsSubsetOf(test.B), test.IsSubsetOf; got != want {
+				t.Errorf("wrong IsSubsetOf result\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := test.A.Overlaps(test.B), test.Overlaps; got != want {
+				t.Errorf("wrong Overlaps result\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := test.B.Overlaps(test.A), test.Overlaps; got != want {
+				t.Errorf("wrong Overlaps result when reversed\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestSetIsNormalizable(t *testing.T) {
+	rc1 := PrereleaseMatching(func(pre VersionExtra) bool {
+		return pre.HasPrefixParts("rc", "1")
+	})
+	tests := []struct {
+		Set  Set
+		Want bool
+	}{
+		{All, true},
+		{AtLeast(MustParseVersion("1.0.0")), true},
+		{Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0"))), true},
+		{rc1, false},
+		{Intersection(rc1, AtLeast(MustParseVersion("1.0.0"))), false},
+		{Released.Subtract(MetadataMatching(func(meta VersionExtra) bool { return meta != "" })), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString(), func(t *testing.T) {
+			if got := test.Set.IsNormalizable(); got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+
+	t.Run("compare", func(t *testing.T) {
+		defer func() {
+			err, _ := recover().(error)
+			if err == nil || !strings.Contains(err.Error(), "IsNormalizable") {
+				t.Errorf("wrong panic %#v; want an error mentioning IsNormalizable", err)
+			}
+		}()
+		Intersection(rc1, AtLeast(MustParseVersion("1.0.0"))).IsEmpty()
+	})
+}
@@ -0,0 +1,142 @@
+package versions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// constraintSpec returns a constraint spec that MeetingConstraints would
+// turn into a set with the same members as the receiver, or an error if
+// there is no such spec.
+//
+// MeetingConstraints never includes pre-release versions except by exact
+// selection, so a set containing any ranges of pre-release versions cannot
+// be represented.
+func (s Set) constraintSpec() (constraints.UnionSpec, error) {
+	if problem := normalizeProblem(s.setI); problem != "" {
+		return nil, fmt.Errorf("set %s, which can't be represented as a constraint string", problem)
+	}
+	n := normalize(s.setI)
+	if len(n.prerelease) != 0 || len(n.channels) != 0 || n.otherPrerelease {
+		return nil, fmt.Errorf("set includes pre-release versions that are not individually selected, which can't be represented as a constraint string")
+	}
+	// MeetingConstraints selects versions of other schemes only using the
+	// "*" operator, which selects all of their released versions along with
+	// all released semantic versions.
+	if len(n.schemes) != 0 || n.otherReleased != n.released.isFull(releasedBound(minVersion)) {
+		return nil, fmt.Errorf("set includes some but not all versions of schemes other than Semver, which can't be represented as a constraint string")
+	}
+	for _, l := range [...]List{n.include, n.exclude} {
+		for _, v := range l {
+			if _, ok := v.(Version); !ok {
+				return nil, fmt.Errorf("set selects %s, which is not a semantic version and so can't be represented in a constraint string", v)
+			}
+		}
+	}
+
+	if n.isEmpty() {
+		// No version is less than zero, so this is a succinct way to
+		// select nothing at all.
+		return constraints.UnionSpec{
+			constraints.IntersectionSpec{
+				{Operator: constraints.OpLessThan, Boundary: versionSpecFromVersion(Unspecified)},
+			},
+		}, nil
+	}
+
+	type selectionSet struct {
+		first SchemeVersion
+		spec  constraints.IntersectionSpec
+	}
+	var sets []selectionSet
+	for _, r := range n.released {
+		spec := constraintSpecForRange(r)
+		for _, v := range n.exclude {
+			if (rangeList{r}).contains(v.(Version)) {
+				spec = append(spec, constraints.SelectionSpec{
+					Operator: constraints.OpNotEqual,
+					Boundary: versionSpecFromVersion(v.(Version)),
+				})
+			}
+		}
+		sets = append(sets, selectionSet{r.lower, spec})
+	}
+	for _, v := range n.include {
+		sets = append(sets, selectionSet{v, constraints.IntersectionSpec{
+			{Operator: constraints.OpEqual, Boundary: versionSpecFromVersion(v.(Version))},
+		}})
+	}
+	sort.SliceStable(sets, func(i, j int) bool {
+		return exactLess(sets[i].first, sets[j].first)
+	})
+
+	ret := make(constraints.UnionSpec, len(sets))
+	for i, set := range sets {
+		ret[i] = set.spec
+	}
+	return ret, nil
+}
+
+// constraintSpecForRange returns the most concise selection set that
+// describes the released versions in the given range, preferring the "~"
+// and "^" operators where they have the same meaning.
+func constraintSpecForRange(r versionRange) constraints.IntersectionSpec {
+	lower := versionSpecFromVersion(r.lower)
+	switch {
+	case r.unbounded && r.lower == Unspecified:
+		return constraints.IntersectionSpec{
+			{Operator: constraints.OpMatch, Boundary: constraints.VersionSpec{}},
+		}
+	case r.unbounded:
+		return constraints.IntersectionSpec{
+			{Operator: constraints.OpGreaterThanOrEqual, Boundary: lower},
+		}
+	case r.lower == Unspecified:
+		return constraints.IntersectionSpec{
+			upperBoundSelection(r.upper),
+		}
+	case r.upper == Version{Major: r.lower.Major, Minor: r.lower.Minor + 1}:
+		return constraints.IntersectionSpec{
+			{Operator: constraints.OpGreaterThanOrEqualPatchOnly, Boundary: lower},
+		}
+	case r.lower.Major != 0 && r.upper == Version{Major: r.lower.Major + 1}:
+		return constraints.IntersectionSpec{
+			{Operator: constraints.OpGreaterThanOrEqualMinorOnly, Boundary: lower},
+		}
+	default:
+		return constraints.IntersectionSpec{
+			{Operator: constraints.OpGreaterThanOrEqual, Boundary: lower},
+			upperBoundSelection(r.upper),
+		}
+	}
+}
+
+// upperBoundSelection returns a selection that excludes released versions
+// greater than or equal to the given upper bound, using the "<=" operator
+// with the previous patch release when possible because that is how such
+// constraints are most often written.
+func upperBoundSelection(upper Version) constraints.SelectionSpec {
+	if upper.Patch == 0 {
+		return constraints.SelectionSpec{
+			Operator: constraints.OpLessThan,
+			Boundary: versionSpecFromVersion(upper),
+		}
+	}
+	upper.Patch--
+	return constraints.SelectionSpec{
+		Operator: constraints.OpLessThanOrEqual,
+		Boundary: versionSpecFromVersion(upper),
+	}
+}
+
+func versionSpecFromVersion(v Version) constraints.VersionSpec {
+	return constraints.VersionSpec{
+		Major:      constraints.NumConstraint{Num: v.Major},
+		Minor:      constraints.NumConstraint{Num: v.Minor},
+		Patch:      constraints.NumConstraint{Num: v.Patch},
+		Prerelease: string(v.Prerelease),
+		Metadata:   string(v.Metadata),
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"fmt"
 )
 
-type setExact map[Version]struct{}
+// setExact is a set of exact versions, sorted and without duplicates as
+// produced by sortExactList.
+type setExact List
 
-func (s setExact) Has(v Version) bool {
-	_, has := s[v]
-	return has
+func (s setExact) Has(v SchemeVersion) bool {
+	return exactListHas(List(s), v)
 }
 
 func (s setExact) AllRequested() Set {
@@ -25,21 +26,14 @@ func (s setExact) GoString() string {
 	}
 
 	if len(s) == 1 {
-		var first Version
-		for v := range s {
-			first = v
-			break
-		}
-		return fmt.Sprintf("versions.Only(%#v)", first)
+		return fmt.Sprintf("versions.Only(%#v)", s[0])
 	}
 
 	var buf bytes.Buffer
 	fmt.Fprint(&buf, "versions.Selection(")
-	versions := s.listVersions()
-	versions.Sort()
-	for i, version := range versions {
+	for i, version := range s {
 		if i == 0 {
-			fmt.Fprint(&buf, version.GoString())
+			fmt.Fprintf(&buf, "%#v", version)
 		} else {
 			fmt.Fprintf(&buf, ", %#v", version)
 		}
@@ -50,31 +44,33 @@ func (s setExact) GoString() string {
 
 // Only returns a version set containing only the given version.
 //
+// A version of the scheme Semver is matched exactly, including its build
+// metadata. For other schemes, the set also contains any other versions that
+// the scheme considers to have the same precedence.
+//
 // This function is guaranteed to produce a finite set.
-func Only(v Version) Set {
+func Only(v SchemeVersion) Set {
 	return Set{
-		setI: setExact{v: struct{}{}},
+		setI: setExact{v},
 	}
 }
 
 // Selection returns a version set containing only the versions given
-// as arguments.
+// as arguments, which are matched as described for Only.
 //
 // This function is guaranteed to produce a finite set.
-func Selection(vs ...Version) Set {
+func Selection(vs ...SchemeVersion) Set {
 	if len(vs) == 0 {
 		return None
 	}
-	ret := make(setExact)
-	for _, v := range vs {
-		ret[v] = struct{}{}
-	}
-	return Set{setI: ret}
+	l := make(List, len(vs))
+	copy(l, vs)
+	return Set{setI: setExact(sortExactList(l))}
 }
 
 // Exactly returns true if and only if the receiving set is finite and
 // contains only a single version that is the same as the version given.
-func (s Set) Exactly(v Version) bool {
+func (s Set) Exactly(v SchemeVersion) bool {
 	if !s.IsFinite() {
 		return false
 	}
@@ -82,7 +78,7 @@ func (s Set) Exactly(v Version) bool {
 	if len(l) != 1 {
 		return false
 	}
-	return v.Same(l[0])
+	return sameScheme(v, l[0]) && compareVersions(v, l[0]) == 0
 }
 
 var _ setFinite = setExact(nil)
@@ -95,9 +91,7 @@ func (s setExact) listVersions() List {
 	if len(s) == 0 {
 		return nil
 	}
-	ret := make(List, 0, len(s))
-	for v := range s {
-		ret = append(ret, v)
-	}
+	ret := make(List, len(s))
+	copy(ret, s)
 	return ret
 }
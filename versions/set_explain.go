@@ -0,0 +1,416 @@
+package versions
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// Explanation describes why a particular version is or is not a member of a
+// set, as returned by Set.Explain.
+//
+// An explanation is a tree that mirrors the structure of the set, with one
+// node for each of the sets it was built from.
+type Explanation struct {
+	// Member is true if the version is a member of the set this node
+	// describes.
+	Member bool
+
+	// Reason is a short English description of why the version is or is not
+	// a member, such as "fails <2.0.0" or "is a prerelease".
+	//
+	// Reasons that concern a constraint selection use the words "meets" and
+	// "fails" followed by the selection, while those that concern a property
+	// of the version itself use "is" and "is not". The reasons of the
+	// children of a node that has alternatives begin with the number of the
+	// alternative, such as "alternative 2: fails <2.0.0", counting from one.
+	Reason string
+
+	// Selection is the constraint selection that is equivalent to the set
+	// this node describes, or nil if there is no such selection. For
+	// example, the node for a set created by AtLeast has a selection with
+	// the operator constraints.OpGreaterThanOrEqual.
+	Selection *constraints.SelectionSpec
+
+	// Children are the explanations for the sets that this node's set was
+	// built from, in the order they were given.
+	Children []Explanation
+}
+
+// Explain returns an explanation of why the given version is or is not a
+// member of the receiving set, as a tree of reasons that follows the
+// structure of the set.
+//
+// This is intended for helping end-users understand the result of Has, such
+// as why a particular version doesn't meet their constraints. The exact
+// wording of the reasons may change in future versions, so callers should not
+// attempt to parse them.
+//
+// The version may belong to any scheme. Reasons that concern a version of a
+// scheme other than Semver have no equivalent constraint selection, and so
+// their Selection is nil.
+func (s Set) Explain(v SchemeVersion) Explanation {
+	if isUnspecified(v) {
+		if s == All {
+			return Explanation{Member: true, Reason: "all versions are members"}
+		}
+		return Explanation{Member: false, Reason: "the unspecified version is a member only of the set of all versions"}
+	}
+	return explain(s.setI, v)
+}
+
+func explain(s setI, v SchemeVersion) Explanation {
+	switch s := s.(type) {
+	case setExtreme:
+		if bool(s) {
+			return Explanation{Member: true, Reason: "all versions are members"}
+		}
+		return Explanation{Member: false, Reason: "no versions are members"}
+
+	case setReleased:
+		if s.Has(v) {
+			return Explanation{Member: true, Reason: "is not a prerelease"}
+		}
+		return Explanation{Member: false, Reason: "is a prerelease"}
+
+	case setPrereleaseChannel:
+		if s.Has(v) {
+			return Explanation{Member: true, Reason: fmt.Sprintf("is in the prerelease channel %q", string(s))}
+		}
+		return Explanation{Member: false, Reason: fmt.Sprintf("is not in the prerelease channel %q", string(s))}
+
+	case *setExtraMatching:
+		if _, ok := v.(Version); !ok {
+			return Explanation{Member: false, Reason: fmt.Sprintf("is not a semantic version, so has no %s", s.name())}
+		}
+		if s.Has(v) {
+			return Explanation{Member: true, Reason: fmt.Sprintf("%s %q is accepted", s.name(), s.extra(v.(Version)))}
+		}
+		return Explanation{Member: false, Reason: fmt.Sprintf("%s %q is not accepted", s.name(), s.extra(v.(Version)))}
+
+	case *setMatching:
+		if s.Has(v) {
+			return Explanation{Member: true, Reason: "is accepted by the function given to Matching"}
+		}
+		return Explanation{Member: false, Reason: "is not accepted by the function given to Matching"}
+
+	case setBound:
+		if bv, ok := s.v.(Version); ok {
+			sel := selectionForBound(bv, s.op)
+			return Explanation{Member: s.Has(v), Reason: fmt.Sprintf("%s %s", meetsOrFails(s.Has(v)), sel), Selection: &sel}
+		}
+		return Explanation{Member: s.Has(v), Reason: fmt.Sprintf("%s %s%s", meetsOrFails(s.Has(v)), selectionOpForBound(s.op), s.v)}
+
+	case setExact:
+		// The versions are already sorted, including those of different
+		// schemes, which List.Sort would not allow.
+		l := List(s)
+		switch {
+		case len(l) == 0:
+			return Explanation{Member: false, Reason: "no versions are members"}
+		case len(l) == 1:
+			return explainExact(l[0], s.Has(v), constraints.OpEqual)
+		default:
+			strs := make([]string, len(l))
+			for i, ev := range l {
+				strs[i] = ev.String()
+			}
+			if s.Has(v) {
+				return Explanation{Member: true, Reason: fmt.Sprintf("is one of %s", strings.Join(strs, ", "))}
+			}
+			return Explanation{Member: false, Reason: fmt.Sprintf("is none of %s", strings.Join(strs, ", "))}
+		}
+
+	case setUnion:
+		ret := Explanation{Children: make([]Explanation, len(s))}
+		var met []string
+		for i, ss := range s {
+			child := explain(ss, v)
+			child.Reason = fmt.Sprintf("alternative %d: %s", i+1, child.Reason)
+			if child.Member {
+				ret.Member = true
+				met = append(met, strconv.Itoa(i+1))
+			}
+			ret.Children[i] = child
+		}
+		switch len(met) {
+		case 0:
+			ret.Reason = fmt.Sprintf("meets none of %d alternatives", len(s))
+		case 1:
+			ret.Reason = fmt.Sprintf("meets alternative %s of %d", met[0], len(s))
+		default:
+			ret.Reason = fmt.Sprintf("meets alternatives %s of %d", joinWords(met), len(s))
+		}
+		return ret
+
+	case setIntersection:
+		ret := Explanation{Member: true, Children: make([]Explanation, len(s))}
+		failed := 0
+		for i, ss := range s {
+			ret.Children[i] = explain(ss, v)
+			if !ret.Children[i].Member {
+				ret.Member = false
+				failed++
+			}
+		}
+		// Intersecting with Released is how MeetingConstraints and
+		// Set.WithoutUnrequestedPrereleases exclude the prereleases that
+		// the other sets don't request, so we say so.
+		for i, ss := range s {
+			if ss != setI(setReleased{}) || ret.Children[i].Member {
+				continue
+			}
+			others := make(setUnion, 0, len(s)-1)
+			others = append(others, s[:i]...)
+			others = append(others, s[i+1:]...)
+			if !others.AllRequested().Has(v) {
+				ret.Children[i].Reason = "is a prerelease not explicitly requested by the constraints"
+			}
+		}
+		if ret.Member {
+			ret.Reason = fmt.Sprintf("meets all %d requirements", len(s))
+		} else {
+			ret.Reason = fmt.Sprintf("fails %d of %d requirements", failed, len(s))
+		}
+		return ret
+
+	case setSource:
+		// We describe the set in terms of the selection it came from,
+		// rather than the sets that selection was translated into.
+		ret := explain(s.setI, v)
+		sel := s.sel
+		ret.Selection = &sel
+		ret.Reason = fmt.Sprintf("%s %s", meetsOrFails(ret.Member), sel)
+		if src := s.src; src.Filename != "" || src.Text != sel.String() {
+			ret.Reason = fmt.Sprintf("%s, from %s", ret.Reason, src)
+		}
+		return ret
+
+	case setSubtract:
+		if exact, ok := s.sub.(setExact); ok && s.from == setExtreme(true) && len(exact) == 1 {
+			// This is how MeetingConstraints represents a "!=" selection.
+			return explainExact(exact[0], s.Has(v), constraints.OpNotEqual)
+		}
+
+		from := explain(s.from, v)
+		sub := explain(s.sub, v)
+		ret := Explanation{
+			Member:   from.Member && !sub.Member,
+			Children: []Explanation{from, sub},
+		}
+		switch {
+		case ret.Member:
+			ret.Reason = "is not excluded"
+		case !from.Member:
+			ret.Reason = "is not in the set that exclusions apply to"
+		default:
+			ret.Reason = "is excluded"
+		}
+		return ret
+
+	case setNormal:
+		// A normalized set doesn't retain the structure it was built from,
+		// so we describe the exact version or range that decides membership
+		// instead, using the same syntax as MarshalText.
+		if exactListHas(s.include, v) {
+			return explainExact(v, true, constraints.OpEqual)
+		}
+		if exactListHas(s.exclude, v) {
+			return explainExact(v, false, constraints.OpNotEqual)
+		}
+		if sv, ok := v.(Version); ok {
+			return s.explainRanges(sv)
+		}
+		return s.explainSchemeRanges(v)
+
+	default:
+		// Other set types don't retain any structure we could describe, so
+		// we just report the result.
+		if s.Has(v) {
+			return Explanation{Member: true, Reason: fmt.Sprintf("is a member of %#v", s)}
+		}
+		return Explanation{Member: false, Reason: fmt.Sprintf("is not a member of %#v", s)}
+	}
+}
+
+// explainRanges explains the membership of a version that the receiver
+// neither includes nor excludes exactly, in terms of the ranges for the
+// class of versions it belongs to.
+func (s setNormal) explainRanges(v Version) Explanation {
+	var l rangeList
+	var kind string
+	var rangeString func(r versionRange) string
+	if v.Prerelease == "" {
+		l = s.released
+		kind = "released versions"
+		rangeString = func(r versionRange) string {
+			return constraintSpecForRange(r).String()
+		}
+	} else {
+		channels := s.channelNames()
+		class := channelClass(v.Prerelease, channels)
+		l = s.classRanges(class)
+		kind = prereleaseClassName(class, channels)
+		rangeString = func(r versionRange) string {
+			spec := constraints.IntersectionSpec{
+				{Operator: constraints.OpGreaterThanOrEqual, Boundary: versionSpecFromVersion(r.lower)},
+			}
+			if !r.unbounded {
+				spec = append(spec, constraints.SelectionSpec{
+					Operator: constraints.OpLessThan,
+					Boundary: versionSpecFromVersion(r.upper),
+				})
+			}
+			return spec.String()
+		}
+	}
+
+	strs := make([]string, len(l))
+	in := -1
+	for i, r := range l {
+		strs[i] = rangeString(r)
+		if (rangeList{r}).contains(v) {
+			in = i
+		}
+	}
+	return explainInRanges(strs, in, kind)
+}
+
+// explainSchemeRanges is the equivalent of explainRanges for a version of a
+// scheme other than Semver.
+func (s setNormal) explainSchemeRanges(v SchemeVersion) Explanation {
+	n := s.schemeNormal(v.Scheme())
+	l, kind := n.released, fmt.Sprintf("released versions of %#v", n.scheme)
+	if isPrerelease(v) {
+		l, kind = n.prerelease, fmt.Sprintf("prereleases of %#v", n.scheme)
+	}
+	class := n.class(isPrerelease(v))
+
+	strs := make([]string, len(l))
+	in := -1
+	for i, r := range l {
+		strs[i] = r.String()
+		if class.contains(schemeRanges{r}, v) {
+			in = i
+		}
+	}
+	return explainInRanges(strs, in, kind)
+}
+
+// explainInRanges returns the explanation for a version whose membership is
+// decided by the ranges of the given kind of versions, given descriptions of
+// those ranges and the index of the one containing the version, or -1 if
+// none does.
+func explainInRanges(strs []string, in int, kind string) Explanation {
+	switch {
+	case in >= 0:
+		return Explanation{Member: true, Reason: fmt.Sprintf("is in the range %s of %s", strs[in], kind)}
+	case len(strs) == 0:
+		return Explanation{Member: false, Reason: fmt.Sprintf("is not in any range of %s", kind)}
+	case len(strs) == 1:
+		return Explanation{Member: false, Reason: fmt.Sprintf("is not in the range %s of %s", strs[0], kind)}
+	default:
+		return Explanation{Member: false, Reason: fmt.Sprintf("is in none of the ranges %s of %s", strings.Join(strs, " || "), kind)}
+	}
+}
+
+// explainExact returns the explanation for a set that is equivalent to a
+// selection of the given version with the given operator, which is either
+// OpEqual or OpNotEqual. There is no such selection for a version of a
+// scheme other than Semver, so we describe it using the same syntax instead.
+func explainExact(ev SchemeVersion, member bool, op constraints.SelectionOp) Explanation {
+	v, ok := ev.(Version)
+	if !ok {
+		return Explanation{Member: member, Reason: fmt.Sprintf("%s %s%s", meetsOrFails(member), op, ev)}
+	}
+	sel := constraints.SelectionSpec{Operator: op, Boundary: versionSpecFromVersion(v)}
+	return Explanation{Member: member, Reason: fmt.Sprintf("%s %s", meetsOrFails(member), sel), Selection: &sel}
+}
+
+// prereleaseClassName returns an English description of the pre-releases
+// in the given class of a normalized set that distinguishes the given
+// channels, as returned by channelClass.
+func prereleaseClassName(class string, channels []string) string {
+	switch {
+	case class != "":
+		return fmt.Sprintf("prereleases in channel %q", class)
+	case len(channels) == 0:
+		return "prereleases"
+	case len(channels) == 1:
+		return fmt.Sprintf("prereleases not in channel %q", channels[0])
+	default:
+		quoted := make([]string, len(channels))
+		for i, c := range channels {
+			quoted[i] = strconv.Quote(c)
+		}
+		return fmt.Sprintf("prereleases in none of the channels %s", joinWords(quoted))
+	}
+}
+
+// meetsOrFails returns the verb that begins the reason for a set that is
+// equivalent to a selection, given whether the version is a member.
+func meetsOrFails(member bool) string {
+	if member {
+		return "meets"
+	}
+	return "fails"
+}
+
+// joinWords joins the given words into an English list, as in "1, 2 and 3".
+func joinWords(words []string) string {
+	if len(words) == 1 {
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// selectionForBound returns the constraint selection equivalent to a bound
+// with the given version and operator.
+func selectionForBound(v Version, op setBoundOp) constraints.SelectionSpec {
+	return constraints.SelectionSpec{
+		Operator: selectionOpForBound(op),
+		Boundary: versionSpecFromVersion(v),
+	}
+}
+
+// selectionOpForBound returns the selection operator equivalent to the
+// given bound operator.
+func selectionOpForBound(op setBoundOp) constraints.SelectionOp {
+	switch op {
+	case setBoundGT:
+		return constraints.OpGreaterThan
+	case setBoundGTE:
+		return constraints.OpGreaterThanOrEqual
+	case setBoundLT:
+		return constraints.OpLessThan
+	case setBoundLTE:
+		return constraints.OpLessThanOrEqual
+	default:
+		// Should never happen because the above is exhaustive
+		panic("invalid setBound operator")
+	}
+}
+
+// String returns a multi-line description of the receiver, with one line
+// for each node of the explanation. Each line starts with "+" if the version
+// is a member of the corresponding set or "-" if it is not, and is indented
+// to show the structure of the set.
+func (e Explanation) String() string {
+	var buf bytes.Buffer
+	e.writeTo(&buf, "")
+	return buf.String()
+}
+
+func (e Explanation) writeTo(buf *bytes.Buffer, indent string) {
+	mark := '-'
+	if e.Member {
+		mark = '+'
+	}
+	fmt.Fprintf(buf, "%s%c %s\n", indent, mark, e.Reason)
+	for _, child := range e.Children {
+		child.writeTo(buf, indent+"  ")
+	}
+}
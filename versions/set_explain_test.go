@@ -0,0 +1,199 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+func TestSetExplain(t *testing.T) {
+	tests := []struct {
+		Set     Set
+		Version string
+		Want    string
+	}{
+		{
+			All,
+			"1.0.0",
+			"+ all versions are members\n",
+		},
+		{
+			Only(MustParseVersion("1.0.0")),
+			"0.0.0",
+			"- the unspecified version is a member only of the set of all versions\n",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <2.0.0")),
+			"2.0.0-beta1",
+			`- fails 1 of 3 requirements
+  - is a prerelease not explicitly requested by the constraints
+  + meets >=1.0.0
+  + meets <2.0.0
+`,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <2.0.0 || >=3.0.0")),
+			"2.5.0",
+			`- fails 1 of 2 requirements
+  + is not a prerelease
+  - meets none of 2 alternatives
+    - alternative 1: fails 1 of 2 requirements
+      + meets >=1.0.0
+      - fails <2.0.0
+    - alternative 2: fails >=3.0.0
+`,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.2 || 2.0.0-beta1")),
+			"2.0.0-beta1",
+			`+ meets alternative 1 of 2
+  + alternative 1: meets 2.0.0-beta1
+  - alternative 2: fails 1 of 2 requirements
+    - is a prerelease
+    + meets alternatives 1 and 2 of 2
+      + alternative 1: meets ^1.2.0, from "^1.2"
+        + meets >=1.2.0
+        + meets <2.0.0
+      + alternative 2: meets 2.0.0-beta1
+`,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 !1.5.0")),
+			"1.5.0",
+			`- fails 1 of 3 requirements
+  + is not a prerelease
+  + meets >=1.0.0
+  - fails !1.5.0
+`,
+		},
+		{
+			Selection(MustParseVersion("1.0.0"), MustParseVersion("1.1.0")),
+			"1.2.0",
+			"- is none of 1.0.0, 1.1.0\n",
+		},
+		{
+			Selection(MustParseVersion("1.0.0"), MustParseVersion("1.1.0")),
+			"1.1.0",
+			"+ is one of 1.0.0, 1.1.0\n",
+		},
+		{
+			Only(MustParseVersion("1.0.0")),
+			"1.2.3",
+			"- fails 1.0.0\n",
+		},
+		{
+			Union(AtLeast(MustParseVersion("2.0.0")), Only(MustParseVersion("1.0.0")), AtMost(MustParseVersion("1.0.0"))),
+			"1.0.0",
+			`+ meets alternatives 2 and 3 of 3
+  - alternative 1: fails >=2.0.0
+  + alternative 2: meets 1.0.0
+  + alternative 3: meets <=1.0.0
+`,
+		},
+		{
+			AtLeast(MustParseVersion("1.0.0")).Intersection(Released),
+			"1.1.0-beta",
+			`- fails 1 of 2 requirements
+  + meets >=1.0.0
+  - is a prerelease not explicitly requested by the constraints
+`,
+		},
+		{
+			// The version is requested, so it isn't Released that fails to
+			// request it.
+			Only(MustParseVersion("1.1.0-beta")).Intersection(Released),
+			"1.1.0-beta",
+			`- fails 1 of 2 requirements
+  + meets 1.1.0-beta
+  - is a prerelease
+`,
+		},
+		{
+			AtLeast(MustParseVersion("1.0.0")).Subtract(Released),
+			"1.2.0",
+			`- is excluded
+  + meets >=1.0.0
+  + is not a prerelease
+`,
+		},
+		{
+			AtLeast(MustParseVersion("1.0.0")).Subtract(Released),
+			"0.2.0-beta",
+			`- is not in the set that exclusions apply to
+  - fails >=1.0.0
+  - is a prerelease
+`,
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0.0")).Normalize(),
+			"2.0.0",
+			"- is not in the range ^1.0.0 of released versions\n",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <1.5.0")).Normalize(),
+			"1.2.0",
+			"+ is in the range >=1.0.0 <1.5.0 of released versions\n",
+		},
+		{
+			// The union of normalized sets is also normalized.
+			Union(
+				MustMakeSet(MeetingConstraintsString("^1.0.0")).Normalize(),
+				MustMakeSet(MeetingConstraintsString(">=3.0.0")).Normalize(),
+			),
+			"2.0.0",
+			"- is in none of the ranges ^1.0.0 || >=3.0.0 of released versions\n",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0.0 !1.2.0")).Normalize(),
+			"1.2.0",
+			"- fails !1.2.0\n",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0.0 || 2.0.0-beta.1")).Normalize(),
+			"2.0.0-beta.1",
+			"+ meets 2.0.0-beta.1\n",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0.0")).Normalize(),
+			"1.1.0-beta.1",
+			"- is not in any range of prereleases\n",
+		},
+		{
+			Intersection(AtLeast(MustParseVersion("1.0.0")), OlderThan(MustParseVersion("2.0.0"))).Normalize(),
+			"1.1.0-beta.1",
+			"+ is in the range >=1.0.1-0 <2.0.1-0 of prereleases\n",
+		},
+		{
+			Intersection(AtLeast(MustParseVersion("1.0.0")), PrereleaseChannel("rc")).Normalize(),
+			"1.1.0-beta.1",
+			"- is not in any range of prereleases not in channel \"rc\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString()+" "+test.Version, func(t *testing.T) {
+			v := MustParseVersion(test.Version)
+			got := test.Set.Explain(v)
+			if got, want := got.String(), test.Want; got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+			if got, want := got.Member, test.Set.Has(v); got != want {
+				t.Errorf("wrong membership\ngot:  %t\nwant: %t", got, want)
+			}
+		})
+	}
+}
+
+func TestSetExplainSelection(t *testing.T) {
+	set := MeetingConstraintsExact(constraints.SelectionSpec{
+		Operator: constraints.OpLessThan,
+		Boundary: versionSpecFromVersion(MustParseVersion("2.0.0")),
+	})
+	got := set.Explain(MustParseVersion("2.1.0"))
+	if got.Selection == nil {
+		t.Fatalf("explanation has no selection")
+	}
+	if got, want := got.Selection.String(), "<2.0.0"; got != want {
+		t.Errorf("wrong selection\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package versions
+
+import (
+	"fmt"
+)
+
+// setPrereleaseChannel is the set of prerelease versions whose prerelease
+// identifiers begin with those of the given string, such as the versions
+// 1.0.0-rc.1 and 2.0.0-rc in the channel "rc".
+type setPrereleaseChannel string
+
+func (s setPrereleaseChannel) Has(v SchemeVersion) bool {
+	sv, ok := v.(Version)
+	return ok && sv.Prerelease != "" && sv.Prerelease.HasPrefixParts(string(s))
+}
+
+func (s setPrereleaseChannel) AllRequested() Set {
+	// A channel requests nothing, because it selects no particular version.
+	return None
+}
+
+func (s setPrereleaseChannel) GoString() string {
+	return fmt.Sprintf("versions.PrereleaseChannel(%q)", string(s))
+}
+
+// PrereleaseChannel returns a set containing the prerelease versions whose
+// prerelease identifiers begin with the identifiers of the given channel
+// name. For example, the channel "rc" contains 1.0.0-rc.1 and 1.0.0-rc but
+// not 1.0.0-beta.1 or 1.0.0-rc1.
+//
+// A channel name may have more than one identifier, separated by dots, to
+// select a narrower series of prereleases: the channel "rc.1" contains
+// 1.0.0-rc.1 and 1.0.0-rc.1.2 but not 1.0.0-rc or 1.0.0-rc.10.
+//
+// This is intended for selecting one of several series of prereleases that
+// are published alongside one another, such as "nightly", "beta" and "rc",
+// usually in combination with other sets using Intersection. The set
+// contains no versions of schemes other than Semver.
+func PrereleaseChannel(channel string) Set {
+	return Set{setI: setPrereleaseChannel(channel)}
+}
+
+// setExtraMatching is the set of versions whose prerelease or metadata
+// string is accepted by a function. It is always used as a pointer, because
+// functions cannot be compared and sets must be comparable.
+type setExtraMatching struct {
+	metadata bool // if false, the prerelease string is tested
+	fn       func(VersionExtra) bool
+}
+
+func (s *setExtraMatching) extra(v Version) VersionExtra {
+	if s.metadata {
+		return v.Metadata
+	}
+	return v.Prerelease
+}
+
+func (s *setExtraMatching) name() string {
+	if s.metadata {
+		return "metadata"
+	}
+	return "prerelease"
+}
+
+func (s *setExtraMatching) Has(v SchemeVersion) bool {
+	sv, ok := v.(Version)
+	return ok && s.fn(s.extra(sv))
+}
+
+func (s *setExtraMatching) AllRequested() Set {
+	// A predicate requests nothing, because it selects no particular version.
+	return None
+}
+
+func (s *setExtraMatching) GoString() string {
+	if s.metadata {
+		return "versions.MetadataMatching(...)"
+	}
+	return "versions.PrereleaseMatching(...)"
+}
+
+// PrereleaseMatching returns a set containing the versions whose prerelease
+// string is accepted by the given function, which is called with an empty
+// string for versions that are not prereleases. The set contains no versions
+// of schemes other than Semver.
+//
+// For example, the following set contains the prereleases whose first two
+// identifiers are "rc" and "1", such as 2.0.0-rc.1.2:
+//
+//	versions.PrereleaseMatching(func(pre versions.VersionExtra) bool {
+//		return pre.HasPrefixParts("rc", "1")
+//	})
+//
+// The function must always return the same result for the same string.
+// Sets that select versions using a function cannot be normalized, and so
+// nor can any set built from them. Such sets report false from
+// Set.IsNormalizable, and the methods that compare sets, such as
+// Set.IsEmpty, panic if given one.
+func PrereleaseMatching(fn func(VersionExtra) bool) Set {
+	return Set{setI: &setExtraMatching{fn: fn}}
+}
+
+// MetadataMatching returns a set containing the versions whose build
+// metadata string is accepted by the given function, which is called with an
+// empty string for versions that have no metadata. The set contains no
+// versions of schemes other than Semver.
+//
+// For example, the following set contains the builds for a particular
+// platform, such as 1.2.3+linux.amd64 and 1.2.3+20200101.linux.amd64:
+//
+//	versions.MetadataMatching(func(meta versions.VersionExtra) bool {
+//		return meta.ContainsParts("linux", "amd64")
+//	})
+//
+// The function must always return the same result for the same string.
+// Sets that select versions using a function cannot be normalized, and so
+// nor can any set built from them. Such sets report false from
+// Set.IsNormalizable, and the methods that compare sets, such as
+// Set.IsEmpty, panic if given one.
+func MetadataMatching(fn func(VersionExtra) bool) Set {
+	return Set{setI: &setExtraMatching{metadata: true, fn: fn}}
+}
@@ -0,0 +1,106 @@
+package versions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrereleaseChannel(t *testing.T) {
+	rc := PrereleaseChannel("rc")
+	tests := map[string]bool{
+		"1.0.0":         false,
+		"1.0.0-rc":      true,
+		"1.0.0-rc.1":    true,
+		"1.0.0-rc.1+a":  true,
+		"1.0.0-rc1":     false,
+		"1.0.0-beta.rc": false,
+		"1.0.0+rc":      false,
+	}
+	for str, want := range tests {
+		v := MustParseVersion(str)
+		if got := rc.Has(v); got != want {
+			t.Errorf("wrong result for %s: got %t, want %t", v, got, want)
+		}
+		if got := rc.Explain(v).Member; got != want {
+			t.Errorf("wrong explanation for %s: got %t, want %t", v, got, want)
+		}
+	}
+
+	if got, want := rc.GoString(), `versions.PrereleaseChannel("rc")`; got != want {
+		t.Errorf("wrong GoString\ngot:  %s\nwant: %s", got, want)
+	}
+
+	rc1 := PrereleaseChannel("rc.1")
+	tests = map[string]bool{
+		"1.0.0-rc":        false,
+		"1.0.0-rc.1":      true,
+		"1.0.0-rc.1.2":    true,
+		"1.0.0-rc.1-a":    false,
+		"1.0.0-rc.10":     false,
+		"1.0.0-beta.rc.1": false,
+	}
+	for str, want := range tests {
+		v := MustParseVersion(str)
+		if got := rc1.Has(v); got != want {
+			t.Errorf("wrong result for %s in rc.1: got %t, want %t", v, got, want)
+		}
+		if got := rc1.Normalize().Has(v); got != want {
+			t.Errorf("wrong normalized result for %s in rc.1: got %t, want %t", v, got, want)
+		}
+	}
+}
+
+func TestExtraMatching(t *testing.T) {
+	rc1 := PrereleaseMatching(func(pre VersionExtra) bool {
+		return pre.HasPrefixParts("rc", "1")
+	})
+	linux := MetadataMatching(func(meta VersionExtra) bool {
+		return meta.ContainsParts("linux", "amd64")
+	})
+	nightly := PrereleaseChannel("nightly")
+
+	tests := []struct {
+		Set     Set
+		Version string
+		Want    bool
+	}{
+		{rc1, "1.0.0-rc.1", true},
+		{rc1, "1.0.0-rc.1.2", true},
+		{rc1, "1.0.0-rc.10", false},
+		{rc1, "1.0.0", false},
+		{linux, "1.0.0+linux.amd64", true},
+		{linux, "1.0.0+20200101.linux.amd64", true},
+		{linux, "1.0.0+linux.amd64v2", false},
+		{linux, "1.0.0+darwin.arm64", false},
+		{linux, "1.0.0-linux.amd64", false},
+		{Intersection(nightly, linux), "1.0.0-nightly.5+linux.amd64", true},
+		{Intersection(nightly, linux), "1.0.0-beta.5+linux.amd64", false},
+		{Intersection(nightly, linux), "1.0.0-nightly.5+darwin.arm64", false},
+		{Intersection(AtLeast(MustParseVersion("1.1.0-0")), nightly), "1.0.0-nightly.5", false},
+		{Intersection(AtLeast(MustParseVersion("1.1.0-0")), nightly), "1.1.0-nightly.5", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString()+" has "+test.Version, func(t *testing.T) {
+			v := MustParseVersion(test.Version)
+			if got := test.Set.Has(v); got != test.Want {
+				t.Errorf("wrong result: got %t, want %t", got, test.Want)
+			}
+			if got := test.Set.Explain(v).Member; got != test.Want {
+				t.Errorf("wrong explanation: got %t, want %t", got, test.Want)
+			}
+		})
+	}
+
+	for _, set := range []Set{rc1, linux, Union(Released, linux)} {
+		if set.IsNormalized() || !reflect.DeepEqual(set.Normalize(), set) {
+			t.Errorf("%#v was normalized; should be unchanged", set)
+		}
+		if _, err := set.MarshalBinary(); err == nil {
+			t.Errorf("%#v was encoded; want error", set)
+		}
+		if _, err := set.MarshalText(); err == nil {
+			t.Errorf("%#v was marshalled; want error", set)
+		}
+	}
+}
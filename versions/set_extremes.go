@@ -1,6 +1,6 @@
 package versions
 
-// All is an infinite set containing all possible versions.
+// All is an infinite set containing all possible versions, of all schemes.
 var All Set
 
 // None is a finite set containing no versions.
@@ -8,7 +8,7 @@ var None Set
 
 type setExtreme bool
 
-func (s setExtreme) Has(v Version) bool {
+func (s setExtreme) Has(v SchemeVersion) bool {
 	return bool(s)
 }
 
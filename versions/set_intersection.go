@@ -7,7 +7,7 @@ import (
 
 type setIntersection []setI
 
-func (s setIntersection) Has(v Version) bool {
+func (s setIntersection) Has(v SchemeVersion) bool {
 	if len(s) == 0 {
 		// Weird to have an intersection with no elements, but we'll
 		// allow it and return something sensible.
@@ -52,11 +52,19 @@ func (s setIntersection) GoString() string {
 // Intersection creates a new set that contains the versions that all of the
 // given sets have in common.
 //
-// The result is finite if any of the given sets are finite.
+// The result is finite if any of the given sets are finite. If all of the given
+// sets are normalized then the result is normalized too.
 func Intersection(sets ...Set) Set {
 	if len(sets) == 0 {
 		return None
 	}
+	if normals, ok := allNormal(sets); ok {
+		ret := normals[0]
+		for _, n := range normals[1:] {
+			ret = normalIntersection(ret, n)
+		}
+		return ret.set()
+	}
 
 	r := make(setIntersection, 0, len(sets))
 	for _, set := range sets {
@@ -72,6 +80,10 @@ func Intersection(sets ...Set) Set {
 			r = append(r, set.setI)
 		}
 	}
+	if len(r) == 0 {
+		// All of the given sets were All.
+		return All
+	}
 	if len(r) == 1 {
 		return Set{setI: r[0]}
 	}
@@ -82,8 +94,13 @@ func Intersection(sets ...Set) Set {
 // the receiver and the given sets have in common.
 //
 // The result is a finite set if the receiver or any of the given sets are
-// finite.
+// finite. If the receiver and all of the given sets are normalized then the
+// result is normalized too.
 func (s Set) Intersection(others ...Set) Set {
+	if _, ok := allNormal(append([]Set{s}, others...)); ok {
+		return Intersection(append([]Set{s}, others...)...)
+	}
+
 	r := make(setIntersection, 1, len(others)+1)
 	r[0] = s.setI
 	for _, ss := range others {
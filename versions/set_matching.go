@@ -0,0 +1,33 @@
+package versions
+
+// setMatching is the set of versions that are accepted by a function. It is
+// always used as a pointer, because functions cannot be compared and sets
+// must be comparable.
+type setMatching struct {
+	fn func(SchemeVersion) bool
+}
+
+func (s *setMatching) Has(v SchemeVersion) bool {
+	return s.fn(v)
+}
+
+func (s *setMatching) AllRequested() Set {
+	// A predicate requests nothing, because it selects no particular version.
+	return None
+}
+
+func (s *setMatching) GoString() string {
+	return "versions.Matching(...)"
+}
+
+// Matching returns a set containing the versions that are accepted by the
+// given function, for rules that can't be expressed in terms of the
+// precedence of versions, such as some of the operators of other ecosystems.
+//
+// The function is called with versions of any scheme, and must always return
+// the same result for the same version. As for PrereleaseMatching, a set
+// built using Matching cannot be normalized, and so nor can any set built
+// from it.
+func Matching(fn func(SchemeVersion) bool) Set {
+	return Set{setI: &setMatching{fn: fn}}
+}
@@ -0,0 +1,537 @@
+package versions
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// setNormal is the canonical representation of a set, produced by
+// Set.Normalize.
+//
+// Membership is decided first by the exact versions in include and exclude,
+// and then by the range list corresponding to whether the given version is a
+// pre-release. include contains only versions that are not covered by the
+// ranges, while exclude contains only versions that are, so that there is
+// exactly one normalized representation for each distinct set of members.
+//
+// A set built from PrereleaseChannel also has a separate range list for the
+// pre-releases in each channel it distinguishes, sorted by channel name, in
+// which case prerelease applies only to pre-releases in none of them. Each
+// list's bounds are canonicalized for its class using channelBound.
+//
+// The ranges of versions of schemes other than Semver are in schemes, sorted
+// by schemeLess, for each scheme that the set distinguishes. The versions of
+// any other scheme are members if otherReleased or otherPrerelease is set,
+// according to whether they are pre-releases. A version of another scheme
+// that is a member but is not adjacent to any other member is in include
+// instead of in a range of its own, while exclude contains only semantic
+// versions, so that finite sets of such versions remain finite.
+type setNormal struct {
+	released   rangeList
+	prerelease rangeList
+	channels   []channelRanges
+	include    List
+	exclude    List
+
+	schemes         []schemeNormal
+	otherReleased   bool
+	otherPrerelease bool
+
+	// requested is the sorted list of versions that are requested by the
+	// set, as would be returned by AllRequested on the original set.
+	requested List
+}
+
+func (s setNormal) Has(v SchemeVersion) bool {
+	if exactListHas(s.include, v) {
+		return true
+	}
+	if exactListHas(s.exclude, v) {
+		return false
+	}
+	return s.rangesHave(v)
+}
+
+func (s setNormal) rangesHave(sv SchemeVersion) bool {
+	v, ok := sv.(Version)
+	if !ok {
+		return s.schemeRangesHave(sv)
+	}
+	if v.Prerelease == "" {
+		return s.released.contains(v)
+	}
+	if len(s.channels) != 0 {
+		return s.classRanges(channelClass(v.Prerelease, s.channelNames())).contains(v)
+	}
+	return s.prerelease.contains(v)
+}
+
+func (s setNormal) AllRequested() Set {
+	return setNormal{
+		include:   s.requested,
+		requested: s.requested,
+	}.set()
+}
+
+func (s setNormal) GoString() string {
+	if s.otherReleased || s.otherPrerelease {
+		return s.goStringFromBase()
+	}
+
+	var buf bytes.Buffer
+	var terms []string
+	for _, r := range s.released {
+		terms = append(terms, r.goString("versions.Released"))
+	}
+	channels := s.channelNames()
+	for _, r := range s.prerelease {
+		terms = append(terms, r.goString(classGoString("", channels)))
+	}
+	for _, c := range s.channels {
+		for _, r := range c.ranges {
+			terms = append(terms, r.goString(classGoString(c.channel, channels)))
+		}
+	}
+	for _, n := range s.schemes {
+		for _, r := range n.released {
+			terms = append(terms, r.goString("versions.Released", n.scheme))
+		}
+		for _, r := range n.prerelease {
+			terms = append(terms, r.goString("versions.Prerelease", n.scheme))
+		}
+	}
+	if len(s.include) != 0 {
+		terms = append(terms, exactSet(s.include).GoString())
+	}
+
+	fmt.Fprint(&buf, "versions.Union(")
+	for i, term := range terms {
+		if i != 0 {
+			fmt.Fprint(&buf, ", ")
+		}
+		fmt.Fprint(&buf, term)
+	}
+	fmt.Fprint(&buf, ")")
+	if len(s.exclude) != 0 {
+		fmt.Fprintf(&buf, ".Subtract(%#v)", exactSet(s.exclude))
+	}
+	fmt.Fprint(&buf, ".Normalize()")
+	return buf.String()
+}
+
+// goStringFromBase is the implementation of GoString for a set that contains
+// the versions of the schemes that it doesn't distinguish, which can only be
+// described in terms of the predefined set that contains them: the versions
+// of that set that the receiver excludes are subtracted from it, and any
+// members of the receiver outside of it are then added.
+func (s setNormal) goStringFromBase() string {
+	var base setNormal
+	var ret string
+	switch {
+	case s.otherReleased && s.otherPrerelease:
+		base, ret = normalAll, "versions.All"
+	case s.otherReleased:
+		base, ret = normalize(setReleased{}), "versions.Released"
+	default:
+		base, ret = normalSubtract(normalAll, normalize(setReleased{})), "versions.Prerelease"
+	}
+	// Neither of these distinguishes the other schemes, so describing them
+	// doesn't recurse back into this method.
+	if excluded := normalSubtract(base, s); !excluded.isEmpty() {
+		ret = fmt.Sprintf("%s.Subtract(%#v)", ret, excluded.set())
+	}
+	if added := normalSubtract(s, base); !added.isEmpty() {
+		ret = fmt.Sprintf("versions.Union(%s, %#v)", ret, added.set())
+	}
+	return ret + ".Normalize()"
+}
+
+func (r versionRange) goString(kind string) string {
+	if r.unbounded {
+		return fmt.Sprintf("versions.Intersection(%s, versions.AtLeast(%#v))", kind, r.lower)
+	}
+	return fmt.Sprintf("versions.Intersection(%s, versions.AtLeast(%#v), versions.OlderThan(%#v))", kind, r.lower, r.upper)
+}
+
+// Normalize returns a set with the same members and requested versions as
+// the receiver, but represented in a canonical form: a sorted list of
+// disjoint ranges of released versions, a sorted list of disjoint ranges of
+// pre-release versions, and any exact versions that are included or excluded
+// in addition to those ranges.
+//
+// The special version Unspecified remains a member only of All, and so All
+// is the only set that normalizes to All. A set containing every other
+// version normalizes to a set of ranges covering all versions instead.
+//
+// A normalized set can answer Has more quickly than a set built from many
+// nested set operations, and two normalized sets with the same members and
+// the same requested versions are structurally equal, such that they can be
+// compared using reflect.DeepEqual.
+//
+// Union, Intersection and Subtract produce normalized results when all of
+// their operands are normalized, so a set built only from normalized sets
+// remains normalized.
+//
+// A set built using PrereleaseChannel is normalized into separate ranges for
+// the pre-releases in each channel, and the versions of each scheme other
+// than Semver that it distinguishes are normalized into ranges of their own.
+// A set that selects versions using a function, such as one built using
+// PrereleaseMatching or Matching, cannot be normalized and is returned
+// unchanged, since its members are not made of ranges of versions. Use
+// IsNormalizable to recognize such sets.
+func (s Set) Normalize() Set {
+	if s == All || !canNormalize(s.setI) {
+		return s
+	}
+	return normalize(s.setI).set()
+}
+
+// IsNormalizable returns true if the receiver can be represented in the
+// canonical form returned by Normalize. Only sets that are normalizable can
+// be compared using IsEmpty, Equal, IsSubsetOf and Overlaps.
+//
+// All sets are normalizable except those built, directly or indirectly, from
+// a set that selects versions using a function, such as PrereleaseMatching,
+// MetadataMatching or Matching.
+func (s Set) IsNormalizable() bool {
+	return canNormalize(s.setI)
+}
+
+// IsNormalized returns true if the receiver is already in the canonical form
+// returned by Normalize.
+func (s Set) IsNormalized() bool {
+	switch s.setI.(type) {
+	case setNormal:
+		return true
+	default:
+		return s == All || s == None
+	}
+}
+
+// set wraps the receiver in a Set, using the predefined set None for an
+// empty set so that it can still be recognized by equality tests.
+//
+// A set containing every version is not represented as All, because All is
+// the only set containing Unspecified, and so only the set All itself
+// normalizes to All.
+func (s setNormal) set() Set {
+	if s.isEmpty() {
+		return None
+	}
+	return Set{setI: s}
+}
+
+func (s setNormal) isEmpty() bool {
+	return s.isFinite() && len(s.include) == 0
+}
+
+var normalAll = setNormal{
+	released:        newRangeList(minVersion, Unspecified, true, releasedBound),
+	prerelease:      newRangeList(minVersion, Unspecified, true, prereleaseBound),
+	otherReleased:   true,
+	otherPrerelease: true,
+}
+
+var normalNone = setNormal{}
+
+// canNormalize returns true if the given set, and all of the sets it is built
+// from, can be represented in the canonical form produced by normalize.
+func canNormalize(s setI) bool {
+	return normalizeProblem(s) == ""
+}
+
+// normalizeProblem returns a description of why the given set can't be
+// represented in the canonical form produced by normalize, suitable for
+// completing the sentence "set ...", or an empty string if it can.
+func normalizeProblem(s setI) string {
+	switch s := s.(type) {
+	case *setExtraMatching:
+		return "selects versions using a function of their prerelease or metadata identifiers"
+	case *setMatching:
+		return "selects versions using a function given to Matching"
+	case setUnion:
+		for _, ss := range s {
+			if problem := normalizeProblem(ss); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	case setIntersection:
+		for _, ss := range s {
+			if problem := normalizeProblem(ss); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	case setSubtract:
+		if problem := normalizeProblem(s.from); problem != "" {
+			return problem
+		}
+		return normalizeProblem(s.sub)
+	case setSource:
+		return normalizeProblem(s.setI)
+	default:
+		return ""
+	}
+}
+
+// normalize produces the canonical representation of the given set.
+func normalize(s setI) setNormal {
+	switch s := s.(type) {
+	case setNormal:
+		return s
+	case setExtreme:
+		if bool(s) {
+			return normalAll
+		}
+		return normalNone
+	case setReleased:
+		return setNormal{
+			released:      normalAll.released,
+			otherReleased: true,
+		}
+	case setBound:
+		if _, ok := s.v.(Version); !ok {
+			return normalizeSchemeBound(s)
+		}
+		var lower, upper Version
+		unbounded := false
+		switch s.op {
+		case setBoundGT:
+			lower, unbounded = successor(s.v.(Version)), true
+		case setBoundGTE:
+			lower, unbounded = s.v.(Version), true
+		case setBoundLT:
+			lower, upper = minVersion, s.v.(Version)
+		case setBoundLTE:
+			lower, upper = minVersion, successor(s.v.(Version))
+		default:
+			// Should never happen because the above is exhaustive
+			panic("invalid setBound operator")
+		}
+		return setNormal{
+			released:   newRangeList(lower, upper, unbounded, releasedBound),
+			prerelease: newRangeList(lower, upper, unbounded, prereleaseBound),
+		}
+	case setExact:
+		l := sortExactList(s.listVersions())
+		return setNormal{
+			include:   l,
+			requested: l,
+		}
+	case setUnion:
+		ret := normalNone
+		for _, ss := range s {
+			ret = normalUnion(ret, normalize(ss))
+		}
+		return ret
+	case setIntersection:
+		if len(s) == 0 {
+			return normalNone
+		}
+		ret := normalize(s[0])
+		for _, ss := range s[1:] {
+			ret = normalIntersection(ret, normalize(ss))
+		}
+		return ret
+	case setSubtract:
+		return normalSubtract(normalize(s.from), normalize(s.sub))
+	case setSource:
+		return normalize(s.setI)
+	case setPrereleaseChannel:
+		return normalizeChannel(string(s))
+	case *setExtraMatching, *setMatching:
+		panic(fmt.Errorf("can't normalize %#v", s))
+	default:
+		// Should never happen because the above is exhaustive for all of
+		// the set implementations in this package.
+		panic(fmt.Errorf("can't normalize %T", s))
+	}
+}
+
+// asNormal returns the normalized form of the given set if it is already
+// normalized, or false if it is not.
+func asNormal(s Set) (setNormal, bool) {
+	switch ss := s.setI.(type) {
+	case setNormal:
+		return ss, true
+	case setExtreme:
+		return normalize(ss), true
+	default:
+		return setNormal{}, false
+	}
+}
+
+// allNormal returns the normalized forms of all of the given sets if they are
+// all either normalized or one of the extreme sets All and None, and at least
+// one of them is a normalized set. Otherwise it returns false.
+func allNormal(sets []Set) ([]setNormal, bool) {
+	ret := make([]setNormal, len(sets))
+	found := false
+	for i, s := range sets {
+		n, ok := asNormal(s)
+		if !ok {
+			return nil, false
+		}
+		if _, isNormal := s.setI.(setNormal); isNormal {
+			found = true
+		}
+		ret[i] = n
+	}
+	return ret, found
+}
+
+func normalUnion(a, b setNormal) setNormal {
+	ret := combineNormal(a, b, func(a, b bool) bool { return a || b })
+	ret.requested = ret.filterMembers(a.requested, b.requested)
+	return ret
+}
+
+func normalIntersection(a, b setNormal) setNormal {
+	ret := combineNormal(a, b, func(a, b bool) bool { return a && b })
+	ret.requested = ret.filterMembers(a.requested, b.requested)
+	return ret
+}
+
+func normalSubtract(a, b setNormal) setNormal {
+	ret := combineNormal(a, b, func(a, b bool) bool { return a && !b })
+	ret.requested = ret.filterMembers(a.requested)
+	return ret
+}
+
+// combineNormal produces a new normalized set containing each version that
+// passes the given boolean operator when applied to its membership of the
+// two given sets. The requested versions of the result are not populated;
+// the caller must set them in a way appropriate for the operation.
+func combineNormal(a, b setNormal, op func(a, b bool) bool) setNormal {
+	ret := setNormal{
+		released: combineRanges(a.released, b.released, releasedBound(minVersion), op),
+	}
+	ret.combinePrereleaseRanges(a, b, op)
+
+	// The only semantic versions whose membership might differ from what
+	// the ranges alone imply are those mentioned exactly in either of the
+	// operands. The exact versions of other schemes are combined along with
+	// their ranges by combineSchemes instead.
+	candidates := make(List, 0, len(a.include)+len(a.exclude)+len(b.include)+len(b.exclude))
+	candidates = append(candidates, a.include...)
+	candidates = append(candidates, a.exclude...)
+	candidates = append(candidates, b.include...)
+	candidates = append(candidates, b.exclude...)
+	candidates = sortExactList(candidates)
+	for _, v := range candidates {
+		if _, ok := v.(Version); !ok {
+			continue
+		}
+		member := op(a.Has(v), b.Has(v))
+		inRanges := ret.rangesHave(v)
+		switch {
+		case member && !inRanges:
+			ret.include = append(ret.include, v)
+		case inRanges && !member:
+			ret.exclude = append(ret.exclude, v)
+		}
+	}
+
+	ret.combineSchemes(a, b, op)
+	ret.include = sortExactList(ret.include)
+	return ret
+}
+
+// filterMembers returns a sorted list of the versions from all of the given
+// lists that are members of the receiver.
+func (s setNormal) filterMembers(lists ...List) List {
+	var ret List
+	for _, l := range lists {
+		for _, v := range l {
+			if s.Has(v) {
+				ret = append(ret, v)
+			}
+		}
+	}
+	return sortExactList(ret)
+}
+
+// sortExactList sorts the given list in-place by scheme and then by
+// precedence, using metadata as a tie-breaker for semantic versions so that
+// the result is deterministic, and then removes any duplicate versions. The
+// result is nil if the list is empty.
+func sortExactList(l List) List {
+	if len(l) == 0 {
+		return nil
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return exactLess(l[i], l[j])
+	})
+	ret := l[:1]
+	for _, v := range l[1:] {
+		if !exactSame(v, ret[len(ret)-1]) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// exactListHas returns true if the given list, sorted by sortExactList,
+// contains the given version exactly, including its metadata if it is a
+// semantic version.
+func exactListHas(l List, v SchemeVersion) bool {
+	i := sort.Search(len(l), func(i int) bool {
+		return !exactLess(l[i], v)
+	})
+	return i < len(l) && exactSame(l[i], v)
+}
+
+func exactLess(a, b SchemeVersion) bool {
+	if av, ok := a.(Version); ok {
+		if bv, ok := b.(Version); ok {
+			if av.Same(bv) {
+				return av.Metadata < bv.Metadata
+			}
+			return av.LessThan(bv)
+		}
+	}
+	if !sameScheme(a, b) {
+		return schemeLess(a.Scheme(), b.Scheme())
+	}
+	return compareVersions(a, b) < 0
+}
+
+// exactSame returns true if the two given versions are the same version.
+// Semantic versions must be equal including their metadata, while versions
+// of other schemes need only have the same precedence.
+func exactSame(a, b SchemeVersion) bool {
+	if av, ok := a.(Version); ok {
+		bv, ok := b.(Version)
+		return ok && av == bv
+	}
+	return sameScheme(a, b) && compareVersions(a, b) == 0
+}
+
+func exactSet(l List) setExact {
+	ret := make(setExact, len(l))
+	copy(ret, l)
+	return ret
+}
+
+var _ setFinite = setNormal{}
+
+func (s setNormal) isFinite() bool {
+	for _, c := range s.channels {
+		if len(c.ranges) != 0 {
+			return false
+		}
+	}
+	return len(s.released) == 0 && len(s.prerelease) == 0 &&
+		len(s.schemes) == 0 && !s.otherReleased && !s.otherPrerelease
+}
+
+func (s setNormal) listVersions() List {
+	if len(s.include) == 0 {
+		return nil
+	}
+	ret := make(List, len(s.include))
+	copy(ret, s.include)
+	return ret
+}
@@ -0,0 +1,228 @@
+package versions
+
+import (
+	"reflect"
+	"testing"
+)
+
+var _ setI = setNormal{}
+
+func TestSetNormalize(t *testing.T) {
+	// We test normalization by comparing membership of the normalized and
+	// unnormalized sets for a selection of interesting versions, since the
+	// normalized set must always have the same members as the original.
+	candidates := List{
+		MustParseVersion("0.0.1"),
+		MustParseVersion("0.9.0"),
+		MustParseVersion("1.0.0-beta.1"),
+		MustParseVersion("1.0.0"),
+		MustParseVersion("1.0.0+abc"),
+		MustParseVersion("1.0.1-0"),
+		MustParseVersion("1.0.1"),
+		MustParseVersion("1.5.0"),
+		MustParseVersion("1.9.9"),
+		MustParseVersion("2.0.0-beta.1"),
+		MustParseVersion("2.0.0-beta.1.0"),
+		MustParseVersion("2.0.0-beta.2"),
+		MustParseVersion("2.0.0"),
+		MustParseVersion("2.0.0+abc"),
+		MustParseVersion("2.5.0"),
+		MustParseVersion("3.0.0"),
+		MustParseVersion("3.0.0-rc.1"),
+		MustParseVersion("10.0.0"),
+		MustParseVersion("1.0.0-1.5"),
+		MustParseVersion("1.0.0-2"),
+		MustParseVersion("1.0.0-rc"),
+		MustParseVersion("1.0.0-rc.1"),
+		MustParseVersion("1.0.0-rc.1.2"),
+		MustParseVersion("1.0.0-rc.2"),
+		MustParseVersion("1.0.0-rc-1"),
+		MustParseVersion("1.0.0-rc1"),
+		MustParseVersion("2.0.0-rc+abc"),
+	}
+
+	tests := []Set{
+		All,
+		None,
+		Released,
+		Prerelease,
+		InitialDevelopment,
+		Only(MustParseVersion("2.0.0-beta.1")),
+		Only(MustParseVersion("1.0.0+abc")),
+		NewerThan(MustParseVersion("1.0.0")),
+		NewerThan(MustParseVersion("2.0.0-beta.1")),
+		AtMost(MustParseVersion("1.0.0")),
+		AtMost(MustParseVersion("2.0.0-beta.1")),
+		AtLeast(MustParseVersion("2.0.0-beta.1")),
+		OlderThan(MustParseVersion("2.0.0-beta.2")),
+		All.Subtract(Only(MustParseVersion("1.0.0"))),
+		All.Subtract(Only(MustParseVersion("1.0.0+abc"))),
+		Only(MustParseVersion("1.0.0")).Subtract(AtLeast(MustParseVersion("1.0.0"))),
+		Intersection(
+			AtLeast(MustParseVersion("2.0.0")),
+			OlderThan(MustParseVersion("1.0.0")),
+		),
+		Union(
+			Intersection(Prerelease, AtLeast(MustParseVersion("2.0.0-beta.2"))),
+			Only(MustParseVersion("1.0.0+abc")),
+		),
+		MustMakeSet(MeetingConstraintsString(">=1.0.0 <2.0.0 || >=1.5.0 <3.0.0")),
+		MustMakeSet(MeetingConstraintsString("^1.0 || 2.0.0-beta.1 || 2.0.0-beta.2")),
+		MustMakeSet(MeetingConstraintsString("!1.0.0 !2.0.0")),
+		MustMakeSet(MeetingConstraintsString("1.0.0 - 2.0.0")),
+		MustMakeSet(MeetingConstraintsStringRuby("~> 1.0, != 1.5.0")),
+		PrereleaseChannel("rc"),
+		PrereleaseChannel("1"),
+		Intersection(PrereleaseChannel("rc"), AtLeast(MustParseVersion("1.0.0-rc.1"))),
+		Union(PrereleaseChannel("rc"), Released),
+		Prerelease.Subtract(PrereleaseChannel("beta")),
+		Union(PrereleaseChannel("rc"), PrereleaseChannel("beta")).Subtract(PrereleaseChannel("rc.1")),
+		Union(PrereleaseChannel("rc.1"), Only(MustParseVersion("1.0.0-rc.2"))),
+	}
+
+	for _, set := range tests {
+		t.Run(set.GoString(), func(t *testing.T) {
+			got := set.Normalize()
+			if !got.IsNormalized() {
+				t.Fatalf("result is not normalized: %#v", got)
+			}
+			for _, v := range candidates {
+				if got, want := got.Has(v), set.Has(v); got != want {
+					t.Errorf("wrong Has result for %s\ngot:  %#v\nwant: %#v", v, got, want)
+				}
+				if got, want := got.Requests(v), set.Requests(v); got != want {
+					t.Errorf("wrong Requests result for %s\ngot:  %#v\nwant: %#v", v, got, want)
+				}
+			}
+			if again := got.Normalize(); !reflect.DeepEqual(again, got) {
+				t.Errorf("normalizing again changed the result\ngot:  %#v\nwant: %#v", again, got)
+			}
+		})
+	}
+}
+
+func TestSetNormalizeEquivalent(t *testing.T) {
+	tests := []struct {
+		A, B Set
+	}{
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <2.0.0 || >=1.5.0 <3.0.0")),
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <3.0.0")),
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("<=1.0.0 || >1.0.0")),
+			Intersection(Released, AtLeast(MustParseVersion("0.0.0"))),
+		},
+		{
+			Union(AtMost(MustParseVersion("1.0.0")), NewerThan(MustParseVersion("1.0.0"))),
+			AtLeast(MustParseVersion("0.0.0-0")),
+		},
+		{
+			Union(OlderThan(MustParseVersion("1.0.0-rc.1")), AtLeast(MustParseVersion("1.0.0-rc.1"))),
+			AtLeast(MustParseVersion("0.0.0-0")),
+		},
+		{
+			Intersection(Released, NewerThan(MustParseVersion("1.0.0"))),
+			Intersection(Released, AtLeast(MustParseVersion("1.0.1-beta.1"))),
+		},
+		{
+			Intersection(Prerelease, AtLeast(MustParseVersion("1.0.0"))),
+			Intersection(Prerelease, NewerThan(MustParseVersion("1.0.0"))),
+		},
+		{
+			Union(
+				Intersection(AtLeast(MustParseVersion("1.0.0")), OlderThan(MustParseVersion("2.0.0"))),
+				Intersection(AtLeast(MustParseVersion("2.0.0")), OlderThan(MustParseVersion("3.0.0"))),
+			),
+			Intersection(AtLeast(MustParseVersion("1.0.0")), OlderThan(MustParseVersion("3.0.0"))),
+		},
+		{
+			Intersection(AtLeast(MustParseVersion("2.0.0")), OlderThan(MustParseVersion("1.0.0"))),
+			None,
+		},
+		{
+			Union(PrereleaseChannel("rc"), Prerelease),
+			Prerelease,
+		},
+		{
+			Union(PrereleaseChannel("rc"), Prerelease.Subtract(PrereleaseChannel("rc"))),
+			Prerelease,
+		},
+		{
+			Intersection(PrereleaseChannel("rc"), Released),
+			None,
+		},
+		{
+			Intersection(PrereleaseChannel("rc"), Prerelease),
+			PrereleaseChannel("rc"),
+		},
+		{
+			Union(PrereleaseChannel("rc"), PrereleaseChannel("rc.1")),
+			PrereleaseChannel("rc"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.A.GoString(), func(t *testing.T) {
+			a := test.A.Normalize()
+			b := test.B.Normalize()
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("results are not equal\na: %#v\nb: %#v", a, b)
+			}
+		})
+	}
+}
+
+func TestSetNormalizeUnspecified(t *testing.T) {
+	// Unspecified is a member only of All itself, even though other sets may
+	// contain every other version, and normalizing must not change that.
+	older := OlderThan(MustParseVersion("2.1.2"))
+	newer := NewerThan(MustParseVersion("1.1.2-rc0"))
+	tests := []struct {
+		Set  Set
+		Want bool
+	}{
+		{All, true},
+		{None, false},
+		{Union(Released, Prerelease), false},
+		{Union(older, newer), false},
+		{Union(older.Normalize(), newer.Normalize()), false},
+		{Union(All, older.Normalize()), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString(), func(t *testing.T) {
+			if got := test.Set.Has(Unspecified); got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+			if got := test.Set.Normalize().Has(Unspecified); got != test.Want {
+				t.Errorf("wrong result after Normalize\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSetNormalizeOperations(t *testing.T) {
+	a := AtLeast(MustParseVersion("1.0.0")).Normalize()
+	b := OlderThan(MustParseVersion("2.0.0")).Normalize()
+	c := Only(MustParseVersion("2.0.0-beta.1")).Normalize()
+
+	got := Intersection(a, b).Union(c).Subtract(Only(MustParseVersion("1.5.0")).Normalize())
+	if !got.IsNormalized() {
+		t.Fatalf("result is not normalized: %#v", got)
+	}
+
+	want := Union(
+		Intersection(
+			AtLeast(MustParseVersion("1.0.0")),
+			OlderThan(MustParseVersion("2.0.0")),
+		),
+		Only(MustParseVersion("2.0.0-beta.1")),
+	).Subtract(Only(MustParseVersion("1.5.0"))).Normalize()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if !got.Requests(MustParseVersion("2.0.0-beta.1")) {
+		t.Errorf("2.0.0-beta.1 is not requested")
+	}
+}
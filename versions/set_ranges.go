@@ -0,0 +1,156 @@
+package versions
+
+import (
+	"sort"
+)
+
+// versionRange is a half-open interval of versions, containing all versions
+// that have a precedence greater than or equal to lower and less than upper.
+// If unbounded is set then the range has no upper limit and upper is ignored.
+//
+// Ranges never consider build metadata, so both bounds always have an empty
+// Metadata string.
+type versionRange struct {
+	lower     Version
+	upper     Version
+	unbounded bool
+}
+
+// rangeList is a sorted list of disjoint, non-adjacent version ranges.
+//
+// Each rangeList is used to represent only one kind of version -- either
+// released or pre-release -- and all of its bounds are canonicalized for
+// that kind using either releasedBound or prereleaseBound, so that any two
+// lists describing the same versions are also structurally equal.
+type rangeList []versionRange
+
+// minVersion is the version with the lowest possible precedence.
+var minVersion = Version{Prerelease: "0"}
+
+// contains returns true if the given version falls within one of the ranges
+// in the receiver. Only the precedence of the version is considered, so
+// any metadata is ignored.
+func (l rangeList) contains(v Version) bool {
+	// Find the first range whose lower bound is greater than v, so that the
+	// range before it is the only one that could possibly contain v.
+	i := sort.Search(len(l), func(i int) bool {
+		return v.LessThan(l[i].lower)
+	})
+	if i == 0 {
+		return false
+	}
+	r := l[i-1]
+	return r.unbounded || v.LessThan(r.upper)
+}
+
+// isFull returns true if the receiver covers all versions of the kind whose
+// minimum bound is given.
+func (l rangeList) isFull(min Version) bool {
+	return len(l) == 1 && l[0].unbounded && l[0].lower == min
+}
+
+// newRangeList creates a range list containing a single range, after
+// canonicalizing its bounds using the given function. If the canonical
+// range would be empty then the result is nil.
+func newRangeList(lower, upper Version, unbounded bool, canon func(Version) Version) rangeList {
+	lower = canon(lower)
+	if !unbounded {
+		upper = canon(upper)
+		if !lower.LessThan(upper) {
+			return nil
+		}
+	} else {
+		upper = Unspecified
+	}
+	return rangeList{
+		{lower: lower, upper: upper, unbounded: unbounded},
+	}
+}
+
+// sortBounds sorts the given range bounds in-place by precedence.
+func sortBounds(l []Version) {
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].LessThan(l[j])
+	})
+}
+
+// combineRanges produces a new range list that contains each version that
+// passes the given boolean operator when applied to its membership of the
+// two given lists. For example, passing a function that implements logical
+// OR produces the union of the two lists.
+//
+// Both lists must be canonicalized for the same kind of version, and min must
+// be the canonical minimum version for that kind.
+func combineRanges(a, b rangeList, min Version, op func(a, b bool) bool) rangeList {
+	// Membership can only change at the bounds of the ranges in either list,
+	// so we'll evaluate the operator at each of those points in turn and
+	// record where the result changes.
+	bounds := make([]Version, 0, 1+len(a)*2+len(b)*2)
+	bounds = append(bounds, min)
+	for _, l := range [...]rangeList{a, b} {
+		for _, r := range l {
+			bounds = append(bounds, r.lower)
+			if !r.unbounded {
+				bounds = append(bounds, r.upper)
+			}
+		}
+	}
+	sortBounds(bounds)
+
+	var ret rangeList
+	in := false
+	for i, bound := range bounds {
+		if i > 0 && bound.Same(bounds[i-1]) {
+			continue
+		}
+		now := op(a.contains(bound), b.contains(bound))
+		switch {
+		case now && !in:
+			ret = append(ret, versionRange{lower: bound, unbounded: true})
+		case in && !now:
+			ret[len(ret)-1].upper = bound
+			ret[len(ret)-1].unbounded = false
+		}
+		in = now
+	}
+	return ret
+}
+
+// releasedBound returns the lowest released version that is greater than
+// or equal to the given version, which is the canonical form of a range
+// bound in a list of released versions.
+func releasedBound(v Version) Version {
+	v.Prerelease = ""
+	v.Metadata = ""
+	return v
+}
+
+// prereleaseBound returns the lowest pre-release version that is greater than
+// or equal to the given version, which is the canonical form of a range
+// bound in a list of pre-release versions.
+func prereleaseBound(v Version) Version {
+	v.Metadata = ""
+	if v.Prerelease == "" {
+		// No pre-release versions lie between a release and the lowest
+		// pre-release of its next patch version.
+		v.Patch++
+		v.Prerelease = "0"
+	}
+	return v
+}
+
+// successor returns the version that has the next-highest precedence after
+// the given version, such that there are no versions with precedence between
+// the two.
+func successor(v Version) Version {
+	v.Metadata = ""
+	if v.Prerelease == "" {
+		v.Patch++
+		v.Prerelease = "0"
+		return v
+	}
+	// An additional identifier always sorts after its prefix, and zero is
+	// the lowest possible identifier.
+	v.Prerelease = v.Prerelease + ".0"
+	return v
+}
@@ -2,8 +2,8 @@ package versions
 
 type setReleased struct{}
 
-func (s setReleased) Has(v Version) bool {
-	return v.Prerelease == ""
+func (s setReleased) Has(v SchemeVersion) bool {
+	return !isPrerelease(v)
 }
 
 func (s setReleased) AllRequested() Set {
@@ -16,11 +16,13 @@ func (s setReleased) GoString() string {
 }
 
 // Released is a set containing all versions that have an empty prerelease
-// string.
+// string, along with the versions of other schemes that their scheme does
+// not consider to be pre-releases.
 var Released Set
 
-// Prerelease is a set containing all versions that have a prerelease marker.
-// This is the complement of Released, or in other words it is
+// Prerelease is a set containing all versions that have a prerelease marker,
+// along with the versions of other schemes that their scheme considers to be
+// pre-releases. This is the complement of Released, or in other words it is
 // All.Subtract(Released).
 var Prerelease Set
 
@@ -0,0 +1,420 @@
+package versions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemeNormal is the part of a normalized set that contains the versions of
+// one scheme other than Semver, as two lists of ranges: one containing
+// released versions and one containing pre-releases.
+//
+// Unlike for semantic versions, we don't know which versions of another
+// scheme lie between two others, so each scheme is assumed to have versions
+// between any two different versions of the same class. For example, the
+// intersection of NewerThan(a) and OlderThan(b) is not considered empty even
+// if the scheme has no versions between a and b.
+type schemeNormal struct {
+	scheme               Scheme
+	released, prerelease schemeRanges
+}
+
+func (n schemeNormal) class(prerelease bool) schemeClass {
+	return schemeClass{scheme: n.scheme, prerelease: prerelease}
+}
+
+// schemeNormal returns the ranges of the versions of the given scheme that
+// are members of the receiver, which are those of the schemes that it
+// doesn't distinguish if it has none of its own for that scheme.
+func (s setNormal) schemeNormal(scheme Scheme) schemeNormal {
+	for _, n := range s.schemes {
+		if n.scheme == scheme {
+			return n
+		}
+	}
+	ret := schemeNormal{scheme: scheme}
+	if s.otherReleased {
+		ret.released = schemeRangesAll
+	}
+	if s.otherPrerelease {
+		ret.prerelease = schemeRangesAll
+	}
+	return ret
+}
+
+// schemeNormalWithExact is like schemeNormal except that it also includes
+// the exact versions of the given scheme that are in include, as ranges
+// containing only those versions.
+func (s setNormal) schemeNormalWithExact(scheme Scheme) schemeNormal {
+	ret := s.schemeNormal(scheme)
+	var released, prerelease schemeRanges
+	for _, v := range s.include {
+		if _, ok := v.(Version); ok || v.Scheme() != scheme {
+			continue
+		}
+		r := schemeRange{lower: v, upper: v, lowerIncl: true, upperIncl: true}
+		if isPrerelease(v) {
+			prerelease = append(prerelease, r)
+		} else {
+			released = append(released, r)
+		}
+	}
+	if len(released) != 0 {
+		ret.released = ret.class(false).canonical(append(released, ret.released...))
+	}
+	if len(prerelease) != 0 {
+		ret.prerelease = ret.class(true).canonical(append(prerelease, ret.prerelease...))
+	}
+	return ret
+}
+
+// schemeRangesHave is the equivalent of rangesHave for versions of schemes
+// other than Semver.
+func (s setNormal) schemeRangesHave(v SchemeVersion) bool {
+	n := s.schemeNormal(v.Scheme())
+	if isPrerelease(v) {
+		return n.class(true).contains(n.prerelease, v)
+	}
+	return n.class(false).contains(n.released, v)
+}
+
+// combineSchemes sets the ranges of the versions of schemes other than
+// Semver of the receiver, which must have none, to those that contain each
+// such version that passes the given boolean operator when applied to its
+// membership of the two given sets. Any ranges of a single version are
+// appended to the include list of the receiver instead, so the caller must
+// sort it afterwards.
+func (s *setNormal) combineSchemes(a, b setNormal, op func(a, b bool) bool) {
+	s.otherReleased = op(a.otherReleased, b.otherReleased)
+	s.otherPrerelease = op(a.otherPrerelease, b.otherPrerelease)
+
+	for _, scheme := range otherSchemes(a, b) {
+		an, bn := a.schemeNormalWithExact(scheme), b.schemeNormalWithExact(scheme)
+		n := schemeNormal{scheme: scheme}
+		var exact List
+		n.released, exact = n.class(false).extractExact(n.class(false).combine(an.released, bn.released, op), exact)
+		n.prerelease, exact = n.class(true).extractExact(n.class(true).combine(an.prerelease, bn.prerelease, op), exact)
+		s.include = append(s.include, exact...)
+
+		// A scheme whose ranges are the same as those of the schemes that
+		// the set doesn't distinguish needn't be distinguished either.
+		if n.released.isAllOrNone(s.otherReleased) && n.prerelease.isAllOrNone(s.otherPrerelease) {
+			continue
+		}
+		s.schemes = append(s.schemes, n)
+	}
+}
+
+// otherSchemes returns the schemes other than Semver that either of the
+// given sets distinguishes or has exact versions of, sorted by schemeLess.
+func otherSchemes(sets ...setNormal) []Scheme {
+	var ret []Scheme
+	add := func(scheme Scheme) {
+		for _, existing := range ret {
+			if existing == scheme {
+				return
+			}
+		}
+		ret = append(ret, scheme)
+	}
+	for _, s := range sets {
+		for _, n := range s.schemes {
+			add(n.scheme)
+		}
+		for _, v := range s.include {
+			if _, ok := v.(Version); !ok {
+				add(v.Scheme())
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return schemeLess(ret[i], ret[j])
+	})
+	return ret
+}
+
+// normalizeSchemeBound returns the normalized form of a setBound whose
+// version belongs to a scheme other than Semver.
+func normalizeSchemeBound(s setBound) setNormal {
+	var r schemeRanges
+	switch s.op {
+	case setBoundGT, setBoundGTE:
+		r = schemeRanges{{lower: s.v, lowerIncl: s.op == setBoundGTE}}
+	case setBoundLT, setBoundLTE:
+		r = schemeRanges{{upper: s.v, upperIncl: s.op == setBoundLTE}}
+	default:
+		// Should never happen because the above is exhaustive
+		panic("invalid setBound operator")
+	}
+	n := schemeNormal{scheme: s.v.Scheme()}
+	n.released = n.class(false).canonical(r)
+	n.prerelease = n.class(true).canonical(r)
+	return setNormal{schemes: []schemeNormal{n}}
+}
+
+// schemeRange is a range of versions of a particular scheme. A nil lower or
+// upper version means that the range is unbounded in that direction.
+type schemeRange struct {
+	lower, upper         SchemeVersion
+	lowerIncl, upperIncl bool
+}
+
+// String returns a description of the receiver using the syntax of
+// constraint strings, such as ">=1.0, <2.0".
+func (r schemeRange) String() string {
+	var bounds []string
+	if r.lower != nil {
+		op := ">"
+		if r.lowerIncl {
+			op = ">="
+		}
+		bounds = append(bounds, op+r.lower.String())
+	}
+	if r.upper != nil {
+		op := "<"
+		if r.upperIncl {
+			op = "<="
+		}
+		bounds = append(bounds, op+r.upper.String())
+	}
+	if len(bounds) == 0 {
+		return "*"
+	}
+	return strings.Join(bounds, ", ")
+}
+
+func (r schemeRange) goString(kind string, scheme Scheme) string {
+	var bounds string
+	if r.lower != nil {
+		if r.lowerIncl {
+			bounds += fmt.Sprintf(", versions.AtLeast(%#v)", r.lower)
+		} else {
+			bounds += fmt.Sprintf(", versions.NewerThan(%#v)", r.lower)
+		}
+	}
+	if r.upper != nil {
+		if r.upperIncl {
+			bounds += fmt.Sprintf(", versions.AtMost(%#v)", r.upper)
+		} else {
+			bounds += fmt.Sprintf(", versions.OlderThan(%#v)", r.upper)
+		}
+	}
+	if bounds == "" {
+		// There's no version we could use as a bound, so we must select
+		// the versions of the scheme using a function instead.
+		bounds = fmt.Sprintf(", versions.Matching(func(v versions.SchemeVersion) bool { return v.Scheme() == %#v })", scheme)
+	}
+	return fmt.Sprintf("versions.Intersection(%s%s)", kind, bounds)
+}
+
+// schemeRanges is a sorted list of non-empty ranges of versions of one
+// precedence class, none of which overlap or touch each other.
+type schemeRanges []schemeRange
+
+// schemeRangesAll contains all versions.
+var schemeRangesAll = schemeRanges{{}}
+
+// isAllOrNone returns true if the receiver contains all versions if all is
+// set, or no versions if it is not.
+func (l schemeRanges) isAllOrNone(all bool) bool {
+	if !all {
+		return len(l) == 0
+	}
+	return len(l) == 1 && l[0].lower == nil && l[0].upper == nil
+}
+
+// schemeClass is either the released or the pre-release versions of a
+// scheme, and has methods for combining ranges of those versions.
+//
+// The versions at the ends of a range need not belong to the class, in which
+// case they are treated as excluded from the range. Otherwise, the class is
+// assumed to have versions between any two different versions.
+type schemeClass struct {
+	scheme     Scheme
+	prerelease bool
+}
+
+// has returns true if the given version, which must not be nil, belongs to
+// the receiving class.
+func (c schemeClass) has(v SchemeVersion) bool {
+	return c.scheme.IsPrerelease(v) == c.prerelease
+}
+
+// contains returns true if the given version, which must belong to the
+// receiving class, is in one of the given ranges.
+func (c schemeClass) contains(l schemeRanges, v SchemeVersion) bool {
+	for _, r := range l {
+		if r.lower != nil {
+			if cmp := c.scheme.Compare(v, r.lower); cmp < 0 || (cmp == 0 && !r.lowerIncl) {
+				// The ranges are sorted, so none of the rest can contain
+				// the version either.
+				return false
+			}
+		}
+		if r.upper == nil {
+			return true
+		}
+		if cmp := c.scheme.Compare(v, r.upper); cmp < 0 || (cmp == 0 && r.upperIncl) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty returns true if the given range contains no versions.
+func (c schemeClass) isEmpty(r schemeRange) bool {
+	if r.lower == nil || r.upper == nil {
+		return false
+	}
+	cmp := c.scheme.Compare(r.lower, r.upper)
+	return cmp > 0 || (cmp == 0 && !(r.lowerIncl && r.upperIncl))
+}
+
+// lowerBefore returns true if the lower end of range a comes before the
+// lower end of range b.
+func (c schemeClass) lowerBefore(a, b schemeRange) bool {
+	switch {
+	case b.lower == nil:
+		return false
+	case a.lower == nil:
+		return true
+	}
+	cmp := c.scheme.Compare(a.lower, b.lower)
+	return cmp < 0 || (cmp == 0 && a.lowerIncl && !b.lowerIncl)
+}
+
+// upperBefore returns true if the upper end of range a comes before the
+// upper end of range b.
+func (c schemeClass) upperBefore(a, b schemeRange) bool {
+	switch {
+	case a.upper == nil:
+		return false
+	case b.upper == nil:
+		return true
+	}
+	cmp := c.scheme.Compare(a.upper, b.upper)
+	return cmp < 0 || (cmp == 0 && !a.upperIncl && b.upperIncl)
+}
+
+// reaches returns true if range a overlaps or touches range b, which starts
+// no earlier than a.
+func (c schemeClass) reaches(a, b schemeRange) bool {
+	if a.upper == nil || b.lower == nil {
+		return true
+	}
+	cmp := c.scheme.Compare(a.upper, b.lower)
+	return cmp > 0 || (cmp == 0 && (a.upperIncl || b.lowerIncl || !c.has(b.lower)))
+}
+
+// canonical returns the given ranges sorted, with any ends that are not in
+// the receiving class excluded, any empty ranges removed, and any ranges that
+// overlap or touch merged together.
+func (c schemeClass) canonical(r schemeRanges) schemeRanges {
+	sorted := make(schemeRanges, 0, len(r))
+	for _, rng := range r {
+		if rng.lower != nil && !c.has(rng.lower) {
+			rng.lowerIncl = false
+		}
+		if rng.upper != nil && !c.has(rng.upper) {
+			rng.upperIncl = false
+		}
+		if !c.isEmpty(rng) {
+			sorted = append(sorted, rng)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return c.lowerBefore(sorted[i], sorted[j])
+	})
+
+	var ret schemeRanges
+	for _, rng := range sorted {
+		if len(ret) != 0 && c.reaches(ret[len(ret)-1], rng) {
+			last := &ret[len(ret)-1]
+			if c.upperBefore(*last, rng) {
+				last.upper, last.upperIncl = rng.upper, rng.upperIncl
+			}
+			continue
+		}
+		ret = append(ret, rng)
+	}
+	return ret
+}
+
+// combine returns the ranges of versions of the receiving class that pass
+// the given boolean operator when applied to their membership of the two
+// given canonical range lists, as combineRanges does for semantic versions.
+func (c schemeClass) combine(a, b schemeRanges, op func(a, b bool) bool) schemeRanges {
+	var ret schemeRanges
+	for _, inA := range [...]bool{false, true} {
+		for _, inB := range [...]bool{false, true} {
+			if !op(inA, inB) {
+				continue
+			}
+			ra, rb := a, b
+			if !inA {
+				ra = c.complement(a)
+			}
+			if !inB {
+				rb = c.complement(b)
+			}
+			ret = append(ret, c.intersection(ra, rb)...)
+		}
+	}
+	return c.canonical(ret)
+}
+
+// extractExact removes from the given canonical ranges any that contain only
+// a single version, appending those versions to the given list. It returns
+// the remaining ranges along with the extended list.
+func (c schemeClass) extractExact(l schemeRanges, exact List) (schemeRanges, List) {
+	var ret schemeRanges
+	for _, r := range l {
+		if r.lower != nil && r.upper != nil && c.scheme.Compare(r.lower, r.upper) == 0 {
+			// A canonical range with equal bounds must include both.
+			exact = append(exact, r.lower)
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret, exact
+}
+
+func (c schemeClass) intersection(a, b schemeRanges) schemeRanges {
+	var ret schemeRanges
+	for _, ar := range a {
+		for _, br := range b {
+			rng := ar
+			if c.lowerBefore(ar, br) {
+				rng.lower, rng.lowerIncl = br.lower, br.lowerIncl
+			}
+			if c.upperBefore(br, ar) {
+				rng.upper, rng.upperIncl = br.upper, br.upperIncl
+			}
+			ret = append(ret, rng)
+		}
+	}
+	return c.canonical(ret)
+}
+
+// complement returns the ranges of versions of the receiving class that are
+// not in the given canonical ranges.
+func (c schemeClass) complement(r schemeRanges) schemeRanges {
+	if len(r) == 0 {
+		return schemeRangesAll
+	}
+	var ret schemeRanges
+	if r[0].lower != nil {
+		ret = append(ret, schemeRange{upper: r[0].lower, upperIncl: !r[0].lowerIncl})
+	}
+	for i := 1; i < len(r); i++ {
+		ret = append(ret, schemeRange{
+			lower: r[i-1].upper, lowerIncl: !r[i-1].upperIncl,
+			upper: r[i].lower, upperIncl: !r[i].lowerIncl,
+		})
+	}
+	if last := r[len(r)-1]; last.upper != nil {
+		ret = append(ret, schemeRange{lower: last.upper, lowerIncl: !last.upperIncl})
+	}
+	return c.canonical(ret)
+}
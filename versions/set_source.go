@@ -0,0 +1,87 @@
+package versions
+
+import (
+	"github.com/apparentlymart/go-versions/versions/constraints"
+)
+
+// setSource wraps the set produced for a single constraint selection so that
+// the selection and its source stay attached to the set. It has exactly the
+// same members as the set it wraps.
+type setSource struct {
+	setI
+	sel constraints.SelectionSpec
+	src constraints.Source
+}
+
+var _ setFinite = setSource{}
+
+func (s setSource) isFinite() bool {
+	return isFinite(s.setI)
+}
+
+func (s setSource) listVersions() List {
+	return s.setI.(setFinite).listVersions()
+}
+
+// withSelectionSource returns a set with the same members as the given set,
+// which must have been produced from the given selection, that retains the
+// selection and the given source of it.
+//
+// The set is returned unchanged if the source is the zero value, or if the
+// set is one of the extreme sets All and None, so that those sets can still
+// be recognized by equality tests.
+func withSelectionSource(set Set, sel constraints.SelectionSpec, src constraints.Source) Set {
+	if src.IsZero() || set == All || set == None {
+		return set
+	}
+	return Set{setI: setSource{setI: set.setI, sel: sel, src: src}}
+}
+
+// alternativeSources returns the sources for the alternative at the given
+// index of a UnionSpec, arranged as the sources of an IntersectionSpec, or
+// nil if there are no sources for that alternative.
+func alternativeSources(sources constraints.Sources, i int) constraints.Sources {
+	if i >= len(sources) {
+		return nil
+	}
+	return constraints.Sources{sources[i]}
+}
+
+// Sources returns the sources of the constraint selections that the receiver
+// was built from, in the order they appear in the set and without
+// duplicates, so that messages about the set can refer back to the
+// constraint strings the user wrote.
+//
+// Only sets built by MeetingConstraintsWithSources and the related functions,
+// from specs and sources produced by the parsers in package constraints, have
+// sources. Sources are not retained by Normalize or by any operation that
+// normalizes its operands, such as Union of normalized sets.
+func (s Set) Sources() []constraints.Source {
+	var ret []constraints.Source
+	seen := make(map[constraints.Source]struct{})
+	var visit func(s setI)
+	visit = func(s setI) {
+		switch s := s.(type) {
+		case setSource:
+			src := s.src
+			if _, exists := seen[src]; !exists {
+				seen[src] = struct{}{}
+				ret = append(ret, src)
+			}
+			visit(s.setI)
+		case setUnion:
+			for _, ss := range s {
+				visit(ss)
+			}
+		case setIntersection:
+			for _, ss := range s {
+				visit(ss)
+			}
+		case setSubtract:
+			visit(s.from)
+			visit(s.sub)
+		}
+	}
+	visit(s.setI)
+	return ret
+}
@@ -0,0 +1,110 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions/constraints"
+	"github.com/go-test/deep"
+)
+
+func TestSetSources(t *testing.T) {
+	app, appSources, err := constraints.ParseWithSources("^1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib, libSources, err := constraints.ParseWithSources(">=1.0.0 !1.3.0 || 2.0.0-beta1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := constraints.IntersectionSpec(nil)
+	merged = append(merged, app[0]...)
+	merged = append(merged, lib[0]...)
+	var mergedSources []constraints.Source
+	mergedSources = append(mergedSources, appSources.WithFilename("app.json")[0]...)
+	mergedSources = append(mergedSources, libSources.WithFilename("lib.json")[0]...)
+
+	tests := []struct {
+		Set  Set
+		Want []constraints.Source
+	}{
+		{
+			All,
+			nil,
+		},
+		{
+			AtLeast(MustParseVersion("1.0.0")),
+			nil,
+		},
+		{
+			MeetingConstraints(app),
+			nil,
+		},
+		{
+			MeetingConstraintsWithSources(app, appSources),
+			[]constraints.Source{
+				{Text: "^1.2", Offset: 0},
+			},
+		},
+		{
+			MeetingConstraintsWithSources(lib, libSources),
+			[]constraints.Source{
+				{Text: ">=1.0.0", Offset: 0},
+				{Text: "!1.3.0", Offset: 8},
+				{Text: "2.0.0-beta1", Offset: 18},
+			},
+		},
+		{
+			MeetingConstraintsWithSources(merged, constraints.Sources{mergedSources}),
+			[]constraints.Source{
+				{Filename: "app.json", Text: "^1.2", Offset: 0},
+				{Filename: "lib.json", Text: ">=1.0.0", Offset: 0},
+				{Filename: "lib.json", Text: "!1.3.0", Offset: 8},
+			},
+		},
+		{
+			MustMakeSet(MeetingConstraintsStringNPM("1.0.0 - 2.0.0 || =3.0.0")),
+			[]constraints.Source{
+				{Text: "1.0.0 - 2.0.0", Offset: 0},
+				{Text: "=3.0.0", Offset: 17},
+			},
+		},
+		{
+			MustMakeSet(MeetingConstraintsStringCargo(">= 1.2, =1.3.0")),
+			[]constraints.Source{
+				{Text: ">= 1.2", Offset: 0},
+				{Text: "=1.3.0", Offset: 8},
+			},
+		},
+		{
+			MeetingConstraintsWithSources(app, appSources).Normalize(),
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString(), func(t *testing.T) {
+			got := test.Set.Sources()
+			for _, problem := range deep.Equal(got, test.Want) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func TestSetExplainSources(t *testing.T) {
+	spec, sources, err := constraints.ParseWithSources(">=1.0 <1.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := MeetingConstraintsWithSources(spec, sources.WithFilename("manifest.json"))
+
+	got := set.Explain(MustParseVersion("1.3.0")).String()
+	want := `- fails 1 of 3 requirements
+  + is not a prerelease
+  + meets >=1.0.0, from ">=1.0" in manifest.json
+  - fails <1.3.0, from "<1.3" in manifest.json
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
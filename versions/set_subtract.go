@@ -7,7 +7,7 @@ type setSubtract struct {
 	sub  setI
 }
 
-func (s setSubtract) Has(v Version) bool {
+func (s setSubtract) Has(v SchemeVersion) bool {
 	return s.from.Has(v) && !s.sub.Has(v)
 }
 
@@ -26,7 +26,8 @@ func (s setSubtract) GoString() string {
 // Subtract returns a new set that has all of the versions from the receiver
 // except for any versions in the other given set.
 //
-// If the receiver is finite then the returned set is also finite.
+// If the receiver is finite then the returned set is also finite. If both the
+// receiver and the other set are normalized then the result is normalized too.
 func (s Set) Subtract(other Set) Set {
 	if other == None || s == None {
 		return s
@@ -34,6 +35,9 @@ func (s Set) Subtract(other Set) Set {
 	if other == All {
 		return None
 	}
+	if normals, ok := allNormal([]Set{s, other}); ok {
+		return normalSubtract(normals[0], normals[1]).set()
+	}
 	return Set{
 		setI: setSubtract{
 			from: s.setI,
@@ -36,6 +36,21 @@ func TestSetHas(t *testing.T) {
 			Unspecified,
 			false, // any sort of constraint removes the special Unspecified version
 		},
+		{
+			Intersection(All, All),
+			MustParseVersion("0.0.0+x"),
+			true,
+		},
+		{
+			Intersection(All, All),
+			Unspecified,
+			true, // the intersection of All with itself is All
+		},
+		{
+			All.Intersection(All),
+			MustParseVersion("1.0.0-beta"),
+			true,
+		},
 		{
 			InitialDevelopment,
 			Unspecified,
@@ -375,7 +390,104 @@ func TestSetJSON(t *testing.T) {
 		),
 	)
 
+	got = withoutSources(got)
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("wrong result\ngot:  %#v\nwant :%#v", got, want)
 	}
 }
+
+func TestSetMarshalText(t *testing.T) {
+	tests := []struct {
+		Set     Set
+		Want    string
+		WantErr string
+	}{
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0.0")),
+			"^1.0.0",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0 <2.0.0 || >=1.5.0 <3.0.0")),
+			">=1.0.0 <3.0.0",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^1.0 || 2.0.0-beta.1 || 2.0.0-beta.2")),
+			"^1.0.0 || 2.0.0-beta.1 || 2.0.0-beta.2",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("1.2.*")),
+			"~1.2.0",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("^0.2.1")),
+			"~0.2.1",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("<=1.0.0 !0.5.0")),
+			"<=1.0.0 !0.5.0",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("1.0.0 - 2.0.0 || >3")),
+			">=1.0.0 <=2.0.0 || >=3.0.1",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=1.0.0+abc")),
+			">=1.0.0",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString("=1.0.0+abc")),
+			"1.0.0+abc",
+			"",
+		},
+		{
+			MustMakeSet(MeetingConstraintsString(">=2.0.0 <1.0.0")),
+			"<0.0.0",
+			"",
+		},
+		{
+			Released,
+			"*",
+			"",
+		},
+		{
+			AtLeast(MustParseVersion("1.0.0")),
+			"",
+			"set includes pre-release versions that are not individually selected, which can't be represented as a constraint string",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Set.GoString(), func(t *testing.T) {
+			got, err := test.Set.MarshalText()
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != test.WantErr {
+				t.Fatalf("wrong error\ngot:  %s\nwant: %s", gotErr, test.WantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(got) != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+
+			var again Set
+			if err := again.UnmarshalText(got); err != nil {
+				t.Fatalf("result is not valid: %s", err)
+			}
+			if !again.Equal(test.Set) {
+				t.Errorf("result has different members\ngot:  %#v\nwant: %#v", again, test.Set)
+			}
+		})
+	}
+}
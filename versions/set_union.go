@@ -7,7 +7,7 @@ import (
 
 type setUnion []setI
 
-func (s setUnion) Has(v Version) bool {
+func (s setUnion) Has(v SchemeVersion) bool {
 	for _, ss := range s {
 		if ss.Has(v) {
 			return true
@@ -53,11 +53,19 @@ func (s setUnion) GoString() string {
 // Union creates a new set that contains all of the given versions.
 //
 // The result is finite only if the receiver and all of the other given sets
-// are finite.
+// are finite. If all of the given sets are normalized then the result is
+// normalized too.
 func Union(sets ...Set) Set {
 	if len(sets) == 0 {
 		return None
 	}
+	if normals, ok := allNormal(sets); ok {
+		ret := normalNone
+		for _, n := range normals {
+			ret = normalUnion(ret, n)
+		}
+		return ret.set()
+	}
 
 	r := make(setUnion, 0, len(sets))
 	for _, set := range sets {
@@ -80,8 +88,13 @@ func Union(sets ...Set) Set {
 // receiver and all of the versions from each of the other given sets.
 //
 // The result is finite only if the receiver and all of the other given sets
-// are finite.
+// are finite. If the receiver and all of the given sets are normalized then the
+// result is normalized too.
 func (s Set) Union(others ...Set) Set {
+	if _, ok := allNormal(append([]Set{s}, others...)); ok {
+		return Union(append([]Set{s}, others...)...)
+	}
+
 	r := make(setUnion, 1, len(others)+1)
 	r[0] = s.setI
 	for _, ss := range others {
@@ -136,11 +136,42 @@ type VersionExtra string
 
 // Parts tokenizes the string into its separate parts by splitting on dots.
 //
-// The result is undefined if the receiver is not valid per the semver spec,
+// The string is split without any validation, so each empty identifier in a
+// string that is not valid per the semver spec, such as "a..b", produces an
+// empty string in the result. An empty receiver produces a single empty
+// string.
 func (e VersionExtra) Parts() []string {
 	return strings.Split(string(e), ".")
 }
 
+// HasPrefixParts returns true if the first identifiers of the receiver, as
+// returned by Parts, are the given identifiers in the same order. For
+// example, "rc.1.2" has the prefix parts "rc" and "1", but not "r".
+//
+// All strings have the empty prefix, so the result is always true if no
+// identifiers are given.
+func (e VersionExtra) HasPrefixParts(parts ...string) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	prefix := strings.Join(parts, ".")
+	s := string(e)
+	return s == prefix || strings.HasPrefix(s, prefix+".")
+}
+
+// ContainsParts returns true if the given identifiers appear consecutively
+// in the same order anywhere in the identifiers of the receiver, as returned
+// by Parts. For example, "20200101.linux.amd64" contains the parts "linux"
+// and "amd64", but not "linux" and "amd" or "amd64" and "linux".
+//
+// The result is always true if no identifiers are given.
+func (e VersionExtra) ContainsParts(parts ...string) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	return strings.Contains("."+string(e)+".", "."+strings.Join(parts, ".")+".")
+}
+
 func (e VersionExtra) Raw() string {
 	return string(e)
 }
@@ -165,10 +196,18 @@ func (e VersionExtra) LessThan(other VersionExtra) bool {
 
 		switch {
 		case d1 == -1 && d2 != -1:
-			// s1 has fewer parts, so it precedes s2
+			// s1 has fewer parts, so it precedes s2 unless its last part
+			// differs from the corresponding part in s2.
+			if s1 != s2[:d2] {
+				return lessThanStr(s1, s2[:d2])
+			}
 			return true
 		case d2 == -1 && d1 != -1:
-			// s1 has more parts, so it succeeds s2
+			// s1 has more parts, so it succeeds s2 unless the last part of
+			// s2 differs from the corresponding part in s1.
+			if s1[:d1] != s2 {
+				return lessThanStr(s1[:d1], s2)
+			}
 			return false
 		case d1 == -1: // d2 must be -1 too, because of the above
 			// this is our last portion to compare
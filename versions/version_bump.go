@@ -0,0 +1,218 @@
+package versions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NextMajor returns the version with the next major version number after
+// the receiver's, with the minor and patch numbers reset to zero and with no
+// prerelease or metadata. For example, the next major version after 1.2.3
+// is 2.0.0.
+func (v Version) NextMajor() Version {
+	return Version{Major: v.Major + 1}
+}
+
+// NextMinor returns the version with the next minor version number after
+// the receiver's, with the patch number reset to zero and with no prerelease
+// or metadata. For example, the next minor version after 1.2.3 is 1.3.0.
+func (v Version) NextMinor() Version {
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// NextPatch returns the version with the next patch version number after
+// the receiver's, with no prerelease or metadata. For example, the next patch
+// version after 1.2.3 is 1.2.4.
+func (v Version) NextPatch() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// Finalize returns the release version that the receiver is a prerelease of,
+// which is the receiver with its prerelease and metadata removed. For
+// example, the result for 1.2.0-rc.1 is 1.2.0.
+//
+// If the receiver is not a prerelease then the result is the receiver with
+// its metadata removed.
+func (v Version) Finalize() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// NextPrerelease returns the next prerelease version after the receiver,
+// using the same rules as "npm version prerelease".
+//
+// If the receiver is already a prerelease then the last numeric identifier in
+// its prerelease is incremented, so that rc.1 becomes rc.2, or ".0" is
+// appended if there is no numeric identifier. If the receiver is not a
+// prerelease then the result is a prerelease of the next patch version.
+//
+// If identifier is not empty and the prerelease does not already start with
+// it followed by a number, the prerelease is replaced with the identifier
+// followed by ".0". For example, the next "rc" prerelease after both
+// 1.2.0-beta.3 and 1.1.0 is 1.2.0-rc.0 and 1.1.1-rc.0 respectively. Since
+// this does not consider the precedence of the identifiers, the result may
+// have lower precedence than the receiver.
+//
+// The result has no metadata. The result is not a valid version if
+// identifier is not a valid dot-separated sequence of prerelease
+// identifiers; Bump checks this before calling NextPrerelease.
+func (v Version) NextPrerelease(identifier string) Version {
+	if v.Prerelease == "" {
+		return v.NextPatch().withNextPrerelease(identifier)
+	}
+	return v.withNextPrerelease(identifier)
+}
+
+// withNextPrerelease implements the part of NextPrerelease that increments
+// the prerelease portion only, without changing the version numbers. For a
+// receiver that is not a prerelease, the result is therefore a prerelease
+// with lower precedence than the receiver.
+func (v Version) withNextPrerelease(identifier string) Version {
+	var parts []string
+	if v.Prerelease != "" {
+		parts = v.Prerelease.Parts()
+	}
+
+	incremented := false
+	for i := len(parts) - 1; i >= 0; i-- {
+		if n, err := strconv.ParseUint(parts[i], 10, 64); err == nil {
+			parts[i] = strconv.FormatUint(n+1, 10)
+			incremented = true
+			break
+		}
+	}
+	if !incremented {
+		parts = append(parts, "0")
+	}
+
+	if identifier != "" {
+		if parts[0] != identifier || len(parts) < 2 || !isNumericIdent(parts[1]) {
+			parts = []string{identifier, "0"}
+		}
+	}
+
+	return Version{
+		Major:      v.Major,
+		Minor:      v.Minor,
+		Patch:      v.Patch,
+		Prerelease: VersionExtra(strings.Join(parts, ".")),
+	}
+}
+
+// validPrerelease returns true if the given string is a valid dot-separated
+// sequence of prerelease identifiers as defined by the semver specification.
+func validPrerelease(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || (len(part) > 1 && part[0] == '0' && isNumericIdent(part)) {
+			return false
+		}
+		for _, c := range part {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isNumericIdent(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// BumpKind is the kind of change that Version.Bump makes to a version.
+//
+// The kinds have the same names and meanings as the release types accepted
+// by "npm version" and "semver --increment".
+type BumpKind string
+
+const (
+	// BumpMajor produces the next major version, or for a prerelease of a
+	// version whose minor and patch numbers are zero, that version itself.
+	// For example, 1.2.3 and 2.0.0-rc.1 both become 2.0.0.
+	BumpMajor BumpKind = "major"
+
+	// BumpMinor produces the next minor version, or for a prerelease of a
+	// version whose patch number is zero, that version itself. For example,
+	// 1.2.3 and 1.3.0-rc.1 both become 1.3.0.
+	BumpMinor BumpKind = "minor"
+
+	// BumpPatch produces the next patch version, or for a prerelease, the
+	// version it is a prerelease of. For example, 1.2.3 and 1.2.4-rc.1 both
+	// become 1.2.4.
+	BumpPatch BumpKind = "patch"
+
+	// BumpPremajor produces the first prerelease of the next major version,
+	// such as 2.0.0-0 or 2.0.0-rc.0 for 1.2.3.
+	BumpPremajor BumpKind = "premajor"
+
+	// BumpPreminor produces the first prerelease of the next minor version,
+	// such as 1.3.0-0 or 1.3.0-rc.0 for 1.2.3.
+	BumpPreminor BumpKind = "preminor"
+
+	// BumpPrepatch produces the first prerelease of the next patch version,
+	// such as 1.2.4-0 or 1.2.4-rc.0 for 1.2.3.
+	BumpPrepatch BumpKind = "prepatch"
+
+	// BumpPrerelease produces the next prerelease, as described for
+	// Version.NextPrerelease.
+	BumpPrerelease BumpKind = "prerelease"
+)
+
+// Bump returns a new version produced by making the given kind of change to
+// the receiver, following the rules of "npm version" and
+// "semver --increment".
+//
+// The identifier is used only for the kinds that produce prereleases, in
+// which case it is the identifier that the new prerelease starts with. It
+// may be empty to produce prereleases like 2.0.0-0 instead.
+//
+// Unlike NextMajor, NextMinor and NextPatch, Bump treats a prerelease as
+// unreleased, so bumping 2.0.0-rc.1 to the next major version produces 2.0.0
+// rather than 3.0.0. The result never has metadata.
+//
+// An error is returned if the kind is not one of the BumpKind constants or
+// if identifier is not a valid dot-separated sequence of prerelease
+// identifiers.
+func (v Version) Bump(kind BumpKind, identifier string) (Version, error) {
+	switch kind {
+	case BumpPremajor, BumpPreminor, BumpPrepatch, BumpPrerelease:
+		if identifier != "" {
+			if !validPrerelease(identifier) {
+				return Unspecified, fmt.Errorf("invalid prerelease identifier %q", identifier)
+			}
+		}
+	}
+
+	switch kind {
+	case BumpMajor:
+		if v.Prerelease != "" && v.Minor == 0 && v.Patch == 0 {
+			return v.Finalize(), nil
+		}
+		return v.NextMajor(), nil
+	case BumpMinor:
+		if v.Prerelease != "" && v.Patch == 0 {
+			return v.Finalize(), nil
+		}
+		return v.NextMinor(), nil
+	case BumpPatch:
+		if v.Prerelease != "" {
+			return v.Finalize(), nil
+		}
+		return v.NextPatch(), nil
+	case BumpPremajor:
+		return v.NextMajor().withNextPrerelease(identifier), nil
+	case BumpPreminor:
+		return v.NextMinor().withNextPrerelease(identifier), nil
+	case BumpPrepatch:
+		return v.NextPatch().withNextPrerelease(identifier), nil
+	case BumpPrerelease:
+		return v.NextPrerelease(identifier), nil
+	default:
+		return Unspecified, fmt.Errorf("invalid version bump %q; must be major, minor, patch, premajor, preminor, prepatch or prerelease", kind)
+	}
+}
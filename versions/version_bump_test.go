@@ -0,0 +1,106 @@
+package versions
+
+import (
+	"testing"
+)
+
+func TestVersionNext(t *testing.T) {
+	tests := []struct {
+		Version string
+		Major   string
+		Minor   string
+		Patch   string
+		Final   string
+	}{
+		{"1.2.3", "2.0.0", "1.3.0", "1.2.4", "1.2.3"},
+		{"0.0.0", "1.0.0", "0.1.0", "0.0.1", "0.0.0"},
+		{"1.2.3-rc.1", "2.0.0", "1.3.0", "1.2.4", "1.2.3"},
+		{"1.2.3+build.5", "2.0.0", "1.3.0", "1.2.4", "1.2.3"},
+		{"2.0.0-beta+abc", "3.0.0", "2.1.0", "2.0.1", "2.0.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Version, func(t *testing.T) {
+			v := MustParseVersion(test.Version)
+			if got, want := v.NextMajor(), MustParseVersion(test.Major); got != want {
+				t.Errorf("wrong NextMajor result\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := v.NextMinor(), MustParseVersion(test.Minor); got != want {
+				t.Errorf("wrong NextMinor result\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := v.NextPatch(), MustParseVersion(test.Patch); got != want {
+				t.Errorf("wrong NextPatch result\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := v.Finalize(), MustParseVersion(test.Final); got != want {
+				t.Errorf("wrong Finalize result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		Version    string
+		Kind       BumpKind
+		Identifier string
+		Want       string
+		WantErr    string
+	}{
+		{"1.2.3", BumpMajor, "", "2.0.0", ""},
+		{"1.2.3-rc.1", BumpMajor, "", "2.0.0", ""},
+		{"2.0.0-rc.1", BumpMajor, "", "2.0.0", ""},
+		{"2.1.0-rc.1", BumpMajor, "", "3.0.0", ""},
+		{"1.2.3", BumpMinor, "", "1.3.0", ""},
+		{"1.3.0-rc.1", BumpMinor, "", "1.3.0", ""},
+		{"1.3.1-rc.1", BumpMinor, "", "1.4.0", ""},
+		{"1.2.3", BumpPatch, "", "1.2.4", ""},
+		{"1.2.4-rc.1", BumpPatch, "", "1.2.4", ""},
+		{"1.2.3+build", BumpPatch, "", "1.2.4", ""},
+		{"1.2.3", BumpPremajor, "", "2.0.0-0", ""},
+		{"1.2.3", BumpPremajor, "rc", "2.0.0-rc.0", ""},
+		{"2.0.0-rc.3", BumpPremajor, "rc", "3.0.0-rc.0", ""},
+		{"1.2.3", BumpPreminor, "", "1.3.0-0", ""},
+		{"1.2.3", BumpPreminor, "beta", "1.3.0-beta.0", ""},
+		{"1.2.3", BumpPrepatch, "", "1.2.4-0", ""},
+		{"1.2.3-rc.1", BumpPrepatch, "alpha", "1.2.4-alpha.0", ""},
+		{"1.2.3", BumpPrerelease, "", "1.2.4-0", ""},
+		{"1.2.3", BumpPrerelease, "rc", "1.2.4-rc.0", ""},
+		{"1.2.4-0", BumpPrerelease, "", "1.2.4-1", ""},
+		{"1.2.4-rc.1", BumpPrerelease, "", "1.2.4-rc.2", ""},
+		{"1.2.4-rc.1", BumpPrerelease, "rc", "1.2.4-rc.2", ""},
+		{"1.2.4-rc.9", BumpPrerelease, "rc", "1.2.4-rc.10", ""},
+		{"1.2.4-rc", BumpPrerelease, "", "1.2.4-rc.0", ""},
+		{"1.2.4-rc", BumpPrerelease, "rc", "1.2.4-rc.0", ""},
+		{"1.2.4-beta.3", BumpPrerelease, "rc", "1.2.4-rc.0", ""},
+		{"1.2.4-rc.1.foo", BumpPrerelease, "", "1.2.4-rc.2.foo", ""},
+		{"1.2.4-1.rc", BumpPrerelease, "rc", "1.2.4-rc.0", ""},
+		{"1.2.4-rc.1+build", BumpPrerelease, "", "1.2.4-rc.2", ""},
+		{"1.2.3", BumpPrerelease, "rc.x", "1.2.4-rc.x.0", ""},
+		{"1.2.3", BumpPrerelease, "rc!", "", `invalid prerelease identifier "rc!"`},
+		{"1.2.3", BumpPrerelease, "rc..1", "", `invalid prerelease identifier "rc..1"`},
+		{"1.2.3", BumpPrerelease, "rc.01", "", `invalid prerelease identifier "rc.01"`},
+		{"1.2.3", BumpMajor, "rc!", "2.0.0", ""},
+		{"1.2.3", BumpKind("huge"), "", "", `invalid version bump "huge"; must be major, minor, patch, premajor, preminor, prepatch or prerelease`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Version+" "+string(test.Kind)+" "+test.Identifier, func(t *testing.T) {
+			got, err := MustParseVersion(test.Version).Bump(test.Kind, test.Identifier)
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success\ngot:  %s\nwant error: %s", got, test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if want := MustParseVersion(test.Want); got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package versions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LooseOptions selects which fix-ups ParseVersionLoose may apply to a version
+// string that is not in the canonical semantic versioning form.
+//
+// The zero value allows no fix-ups at all, in which case ParseVersionLoose
+// accepts only strings that have all three version numbers, without leading
+// zeros, and is otherwise the same as ParseVersion.
+type LooseOptions struct {
+	// TrimSpace allows leading and trailing whitespace, which is removed.
+	TrimSpace bool
+
+	// TrimPrefix allows a "v", "V" or "release-" prefix before the major
+	// version number, as is common in version control tags, which is
+	// removed.
+	TrimPrefix bool
+
+	// PadSegments allows the minor and patch numbers to be omitted, in which
+	// case they are zero.
+	PadSegments bool
+
+	// TrimLeadingZeros allows leading zeros in version numbers, as in
+	// "1.02.3", which are removed.
+	TrimLeadingZeros bool
+
+	// FoldFourthSegment allows a fourth version number, as in "1.2.3.4",
+	// and chooses which portion of the version to move it to.
+	FoldFourthSegment FoldTarget
+}
+
+// FoldTarget is the type of LooseOptions.FoldFourthSegment, describing what
+// to do with a fourth version number.
+type FoldTarget int
+
+const (
+	// FoldNone rejects versions that have a fourth version number.
+	FoldNone FoldTarget = 0
+
+	// FoldPrerelease moves a fourth version number to the start of the
+	// prerelease portion, so that "1.2.3.4" becomes "1.2.3-4". Note that this
+	// gives the result a lower precedence than "1.2.3".
+	FoldPrerelease FoldTarget = 1
+
+	// FoldMetadata moves a fourth version number to the start of the
+	// metadata portion, so that "1.2.3.4" becomes "1.2.3+4". The result has
+	// the same precedence as "1.2.3".
+	FoldMetadata FoldTarget = 2
+)
+
+// Fixup identifies a change that ParseVersionLoose made to a version string
+// in order to parse it.
+type Fixup string
+
+const (
+	// FixupTrimmedSpace means that leading or trailing whitespace was
+	// removed, as allowed by LooseOptions.TrimSpace.
+	FixupTrimmedSpace Fixup = "trimmed-space"
+
+	// FixupTrimmedPrefix means that a prefix such as "v" was removed from
+	// before the major version number, as allowed by LooseOptions.TrimPrefix.
+	FixupTrimmedPrefix Fixup = "trimmed-prefix"
+
+	// FixupPaddedSegments means that a missing minor or patch number was
+	// set to zero, as allowed by LooseOptions.PadSegments.
+	FixupPaddedSegments Fixup = "padded-segments"
+
+	// FixupTrimmedZeros means that leading zeros were removed from one or
+	// more version numbers, as allowed by LooseOptions.TrimLeadingZeros. It
+	// is reported only once, however many numbers were changed.
+	FixupTrimmedZeros Fixup = "trimmed-leading-zeros"
+
+	// FixupFoldedPrerelease means that a fourth version number was moved to
+	// the prerelease portion, because LooseOptions.FoldFourthSegment is
+	// FoldPrerelease.
+	FixupFoldedPrerelease Fixup = "folded-to-prerelease"
+
+	// FixupFoldedMetadata means that a fourth version number was moved to
+	// the metadata portion, because LooseOptions.FoldFourthSegment is
+	// FoldMetadata.
+	FixupFoldedMetadata Fixup = "folded-to-metadata"
+)
+
+// loosePrefixes are the prefixes that LooseOptions.TrimPrefix allows, which
+// are removed only if they are followed by a digit.
+var loosePrefixes = []string{"release-", "v", "V"}
+
+// ParseVersionLoose is like ParseVersion but also accepts some common
+// variations on the semantic versioning syntax, such as those found in
+// version control tags and container image tags, as allowed by the given
+// options.
+//
+// As well as the version, it returns the fix-ups that were needed to parse
+// the string, in the order they were applied, so that the caller can decide
+// whether to accept the result or to report the problems. An empty result
+// means that the string was already in the canonical form.
+//
+// For example, with all of the options enabled and fourth version numbers
+// folded into the metadata, " v1.02.3.4" produces the version 1.2.3+4 with
+// the fix-ups FixupTrimmedSpace, FixupTrimmedPrefix, FixupTrimmedZeros and
+// FixupFoldedMetadata.
+func ParseVersionLoose(s string, opts LooseOptions) (Version, []Fixup, error) {
+	var fixups []Fixup
+
+	if opts.TrimSpace {
+		if trimmed := strings.TrimSpace(s); trimmed != s {
+			s = trimmed
+			fixups = append(fixups, FixupTrimmedSpace)
+		}
+	}
+
+	if opts.TrimPrefix {
+		for _, prefix := range loosePrefixes {
+			if strings.HasPrefix(s, prefix) && len(s) > len(prefix) && isDigit(s[len(prefix)]) {
+				s = s[len(prefix):]
+				fixups = append(fixups, FixupTrimmedPrefix)
+				break
+			}
+		}
+	}
+
+	// We split the string into its portions so that we can rearrange them.
+	// The separators are kept so that an empty portion is still reported as
+	// an error by ParseVersion.
+	core, prerelease, metadata := s, "", ""
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		core, metadata = core[:i], core[i:]
+	}
+	if i := strings.IndexByte(core, '-'); i >= 0 {
+		core, prerelease = core[:i], core[i:]
+	}
+	nums := strings.Split(core, ".")
+
+	for i, num := range nums {
+		if len(num) > 1 && num[0] == '0' && allDigits(num) {
+			if !opts.TrimLeadingZeros {
+				return Unspecified, nil, fmt.Errorf("version numbers must not have leading zeros")
+			}
+			nums[i] = strings.TrimLeft(num, "0")
+			if nums[i] == "" {
+				nums[i] = "0"
+			}
+			if len(fixups) == 0 || fixups[len(fixups)-1] != FixupTrimmedZeros {
+				fixups = append(fixups, FixupTrimmedZeros)
+			}
+		}
+	}
+
+	if len(nums) == 4 && allNumbers(nums) {
+		fourth := nums[3]
+		switch opts.FoldFourthSegment {
+		case FoldPrerelease:
+			nums = nums[:3]
+			prerelease = joinIdentifiers('-', fourth, prerelease)
+			fixups = append(fixups, FixupFoldedPrerelease)
+		case FoldMetadata:
+			nums = nums[:3]
+			metadata = joinIdentifiers('+', fourth, metadata)
+			fixups = append(fixups, FixupFoldedMetadata)
+		}
+	}
+
+	// If any of the version numbers are malformed then we leave ParseVersion
+	// to report that, rather than complaining about the missing ones.
+	if len(nums) < 3 && allNumbers(nums) {
+		if !opts.PadSegments {
+			return Unspecified, nil, fmt.Errorf("major, minor and patch version numbers are all required")
+		}
+		for len(nums) < 3 {
+			nums = append(nums, "0")
+		}
+		fixups = append(fixups, FixupPaddedSegments)
+	}
+
+	v, err := ParseVersion(strings.Join(nums, ".") + prerelease + metadata)
+	if err != nil {
+		return Unspecified, nil, err
+	}
+	return v, fixups, nil
+}
+
+// joinIdentifiers adds the given identifier to the start of the given
+// prerelease or metadata portion, which begins with the given separator if
+// it is present at all.
+func joinIdentifiers(sep byte, first, portion string) string {
+	if portion == "" {
+		return string(sep) + first
+	}
+	return string(sep) + first + "." + portion[1:]
+}
+
+// allNumbers returns true if all of the given strings are non-empty and
+// consist only of decimal digits.
+func allNumbers(strs []string) bool {
+	for _, s := range strs {
+		if s == "" || !allDigits(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
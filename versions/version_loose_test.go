@@ -0,0 +1,90 @@
+package versions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersionLoose(t *testing.T) {
+	all := LooseOptions{
+		TrimSpace:         true,
+		TrimPrefix:        true,
+		PadSegments:       true,
+		TrimLeadingZeros:  true,
+		FoldFourthSegment: FoldMetadata,
+	}
+	allPrerelease := all
+	allPrerelease.FoldFourthSegment = FoldPrerelease
+
+	tests := []struct {
+		Input   string
+		Options LooseOptions
+		Want    string
+		Fixups  []Fixup
+		WantErr string
+	}{
+		{"1.2.3", LooseOptions{}, "1.2.3", nil, ""},
+		{"1.2.3-beta.1+abc", LooseOptions{}, "1.2.3-beta.1+abc", nil, ""},
+		{"1.2.3", all, "1.2.3", nil, ""},
+		{" 1.2.3\n", all, "1.2.3", []Fixup{FixupTrimmedSpace}, ""},
+		{"v1.2.3", all, "1.2.3", []Fixup{FixupTrimmedPrefix}, ""},
+		{"V1.2.3", all, "1.2.3", []Fixup{FixupTrimmedPrefix}, ""},
+		{"release-1.2.3-rc1", all, "1.2.3-rc1", []Fixup{FixupTrimmedPrefix}, ""},
+		{"1.2", all, "1.2.0", []Fixup{FixupPaddedSegments}, ""},
+		{"v2", all, "2.0.0", []Fixup{FixupTrimmedPrefix, FixupPaddedSegments}, ""},
+		{"1.2-beta+abc", all, "1.2.0-beta+abc", []Fixup{FixupPaddedSegments}, ""},
+		{"01.002.3", all, "1.2.3", []Fixup{FixupTrimmedZeros}, ""},
+		{"1.00.0", all, "1.0.0", []Fixup{FixupTrimmedZeros}, ""},
+		{"1.2.3.4", all, "1.2.3+4", []Fixup{FixupFoldedMetadata}, ""},
+		{"1.2.3.4+abc", all, "1.2.3+4.abc", []Fixup{FixupFoldedMetadata}, ""},
+		{"1.2.3.4-rc1+abc", all, "1.2.3-rc1+4.abc", []Fixup{FixupFoldedMetadata}, ""},
+		{"1.2.3.4", allPrerelease, "1.2.3-4", []Fixup{FixupFoldedPrerelease}, ""},
+		{"1.2.3.4-rc1+abc", allPrerelease, "1.2.3-4.rc1+abc", []Fixup{FixupFoldedPrerelease}, ""},
+		{
+			" v1.02.3.4",
+			all,
+			"1.2.3+4",
+			[]Fixup{FixupTrimmedSpace, FixupTrimmedPrefix, FixupTrimmedZeros, FixupFoldedMetadata},
+			"",
+		},
+
+		// Each fix-up is only applied if the options allow it.
+		{" 1.2.3", LooseOptions{}, "", nil, `extraneous spaces at start of specification`},
+		{"v1.2.3", LooseOptions{}, "", nil, `a "v" prefix should not be used`},
+		{"1.2", LooseOptions{}, "", nil, `major, minor and patch version numbers are all required`},
+		{"01.2.3", LooseOptions{}, "", nil, `version numbers must not have leading zeros`},
+		{"1.2.3.4", LooseOptions{}, "", nil, `too many numbered portions; only three are allowed (major, minor, patch)`},
+
+		// Some problems can't be fixed.
+		{"", all, "", nil, `empty specification`},
+		{"version1.2.3", all, "", nil, `a "v" prefix should not be used`},
+		{"v", all, "", nil, `a "v" prefix should not be used`},
+		{"1.2.3.4.5", all, "", nil, `too many numbered portions; only three are allowed (major, minor, patch)`},
+		{"1.2.3.Final", all, "", nil, `invalid characters "Final"`},
+		{"1..3", all, "", nil, `invalid characters ".3"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got, fixups, err := ParseVersionLoose(test.Input, test.Options)
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded with %s; want error", got)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if want := MustParseVersion(test.Want); !got.Same(want) {
+				t.Errorf("wrong version\ngot:  %s\nwant: %s", got, want)
+			}
+			if !reflect.DeepEqual(fixups, test.Fixups) {
+				t.Errorf("wrong fixups\ngot:  %#v\nwant: %#v", fixups, test.Fixups)
+			}
+		})
+	}
+}
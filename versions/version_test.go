@@ -2,6 +2,7 @@ package versions
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -46,3 +47,61 @@ func TestVersionJSON(t *testing.T) {
 	}
 
 }
+
+func TestVersionExtraLessThan(t *testing.T) {
+	tests := []struct {
+		A, B VersionExtra
+		Want bool
+	}{
+		{"alpha", "alpha", false},
+		{"alpha", "alpha.1", true},
+		{"alpha.1", "alpha", false},
+		{"alpha.1", "alpha.beta", true},
+		{"alpha.beta", "beta", true},
+		{"beta", "beta.2", true},
+		{"beta.2", "beta.11", true},
+		{"beta.11", "rc.1", true},
+		{"beta.1.0", "beta.2", true},
+		{"beta.2", "beta.1.0", false},
+		{"1", "alpha", true},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.A)+" < "+string(test.B), func(t *testing.T) {
+			if got := test.A.LessThan(test.B); got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestVersionExtraParts(t *testing.T) {
+	tests := []struct {
+		Extra    VersionExtra
+		Parts    []string
+		Prefix   bool
+		Contains bool
+	}{
+		{"rc.1.2", []string{"rc", "1"}, true, true},
+		{"rc.1", []string{"rc", "1"}, true, true},
+		{"rc.10", []string{"rc", "1"}, false, false},
+		{"rc", []string{"r"}, false, false},
+		{"nightly.rc.1", []string{"rc", "1"}, false, true},
+		{"linux.amd64", []string{"linux", "amd"}, false, false},
+		{"linux.amd64", []string{"amd64", "linux"}, false, false},
+		{"", []string{"rc"}, false, false},
+		{"rc", nil, true, true},
+		{"", nil, true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %q", test.Extra, test.Parts), func(t *testing.T) {
+			if got := test.Extra.HasPrefixParts(test.Parts...); got != test.Prefix {
+				t.Errorf("wrong HasPrefixParts result\ngot:  %#v\nwant: %#v", got, test.Prefix)
+			}
+			if got := test.Extra.ContainsParts(test.Parts...); got != test.Contains {
+				t.Errorf("wrong ContainsParts result\ngot:  %#v\nwant: %#v", got, test.Contains)
+			}
+		})
+	}
+}